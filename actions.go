@@ -0,0 +1,128 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Actions interface {
+	// FlushNetworkTable purges all devices from the network table (ARP cache).
+	FlushNetworkTable(ctx context.Context) (*ActionResult, error)
+
+	// FlushLogs truncates the DNS log and purges the last 24 hours from the query database.
+	FlushLogs(ctx context.Context) (*ActionResult, error)
+
+	// RestartDNS restarts FTL's DNS resolver.
+	RestartDNS(ctx context.Context) (*ActionResult, error)
+
+	// RestartDNSAndWait restarts FTL and blocks until it answers again, returning how long
+	// the restart took.
+	RestartDNSAndWait(ctx context.Context, timeout time.Duration) (time.Duration, error)
+}
+
+// restartPollInterval is how often RestartDNSAndWait checks whether FTL is back.
+var restartPollInterval = 500 * time.Millisecond
+
+type actions struct {
+	client *Client
+}
+
+// ActionResult is the outcome of an action endpoint.
+type ActionResult struct {
+	Status string
+	Took   time.Duration
+}
+
+type actionResponse struct {
+	Status string  `json:"status"`
+	Took   float64 `json:"took"`
+}
+
+func (res actionResponse) toActionResult() *ActionResult {
+	return &ActionResult{
+		Status: res.Status,
+		Took:   time.Duration(res.Took * float64(time.Second)),
+	}
+}
+
+// FlushNetworkTable flushes the network table
+func (a actions) FlushNetworkTable(ctx context.Context) (*ActionResult, error) {
+	return a.post(ctx, "/api/action/flush/arp")
+}
+
+// FlushLogs flushes the DNS logs
+func (a actions) FlushLogs(ctx context.Context) (*ActionResult, error) {
+	return a.post(ctx, "/api/action/flush/logs")
+}
+
+// RestartDNS restarts the DNS resolver
+func (a actions) RestartDNS(ctx context.Context) (*ActionResult, error) {
+	return a.post(ctx, "/api/action/restartdns")
+}
+
+// RestartDNSAndWait restarts the DNS resolver and polls /api/info/ftl until FTL reports
+// a fresh uptime, so the old process answering before it shuts down is not mistaken
+// for the restarted one
+func (a actions) RestartDNSAndWait(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+	before, beforeErr := a.client.Info.FTL(ctx)
+
+	started := time.Now()
+	if _, err := a.RestartDNS(ctx); err != nil {
+		return 0, fmt.Errorf("failed to restart DNS: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(restartPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return time.Since(started), fmt.Errorf("FTL did not answer within %s after restart: %w", timeout, ctx.Err())
+		case <-ticker.C:
+		}
+
+		after, err := a.client.Info.FTL(ctx)
+		if err != nil {
+			continue
+		}
+
+		if beforeErr != nil || after.PID != before.PID || after.Uptime < before.Uptime {
+			return time.Since(started), nil
+		}
+	}
+}
+
+func (a actions) post(ctx context.Context, path string) (result *ActionResult, err error) {
+	defer func() {
+		a.client.afterMutation(ctx, Mutation{Operation: AuditAction, Target: path, After: auditValue(result)}, err)
+	}()
+
+	if err := a.client.beforeMutation(ctx, Mutation{Operation: AuditAction, Target: path}); err != nil {
+		return nil, err
+	}
+
+	res, err := a.client.Post(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newActionAPIError(res, b)
+	}
+
+	var resAction actionResponse
+	if err := json.NewDecoder(res.Body).Decode(&resAction); err != nil {
+		return nil, fmt.Errorf("failed to parse action body: %w", err)
+	}
+
+	return resAction.toActionResult(), nil
+}
@@ -0,0 +1,107 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActions_FlushNetworkTable(t *testing.T) {
+	isUnit(t)
+
+	var paths []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+		switch req.URL.Path {
+		case "/api/action/flush/arp":
+			return newHTTPResponse(http.StatusOK, `{"status":"success","took":0.25}`), nil
+		case "/api/action/flush/logs":
+			return newHTTPResponse(http.StatusForbidden, `{"error":{"key":"forbidden","message":"Action not allowed","hint":"webserver.api.allow_destructive is false"}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	result, err := client.Actions.FlushNetworkTable(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, 250*time.Millisecond, result.Took)
+
+	_, err = client.Actions.FlushLogs(context.Background())
+	var apiErr *ActionAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "forbidden", apiErr.Key)
+
+	assert.Equal(t, []string{"POST /api/action/flush/arp", "POST /api/action/flush/logs"}, paths)
+}
+
+func TestActions_RestartDNSAndWait(t *testing.T) {
+	isUnit(t)
+
+	defer func(interval time.Duration) { restartPollInterval = interval }(restartPollInterval)
+	restartPollInterval = time.Millisecond
+
+	restarted := false
+	polls := 0
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/action/restartdns":
+			restarted = true
+			return newHTTPResponse(http.StatusOK, `{"status":"success","took":0.001}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/info/ftl":
+			if !restarted {
+				return newHTTPResponse(http.StatusOK, `{"ftl":{"pid":10,"uptime":900000}}`), nil
+			}
+
+			polls++
+			switch polls {
+			case 1:
+				// The old process still answers right after the restart request.
+				return newHTTPResponse(http.StatusOK, `{"ftl":{"pid":10,"uptime":900010}}`), nil
+			case 2:
+				return nil, fmt.Errorf("connection refused")
+			default:
+				return newHTTPResponse(http.StatusOK, `{"ftl":{"pid":10,"uptime":20}}`), nil
+			}
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	took, err := client.Actions.RestartDNSAndWait(context.Background(), time.Second)
+	require.NoError(t, err)
+	assert.Positive(t, took)
+	assert.Equal(t, 3, polls)
+}
+
+func TestActions_RestartDNSAndWaitTimesOut(t *testing.T) {
+	isUnit(t)
+
+	defer func(interval time.Duration) { restartPollInterval = interval }(restartPollInterval)
+	restartPollInterval = time.Millisecond
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/api/action/restartdns" {
+			return newHTTPResponse(http.StatusOK, `{"status":"success","took":0.001}`), nil
+		}
+		return nil, fmt.Errorf("connection refused")
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	_, err = client.Actions.RestartDNSAndWait(context.Background(), 20*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
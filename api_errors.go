@@ -1,14 +1,237 @@
 package pihole
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 )
 
+// ErrNotFound is wrapped by every subsystem-specific not-found error, such as
+// ErrorLocalDNSNotFound, so callers can check errors.Is(err, ErrNotFound) regardless
+// of the service that returned it.
+var ErrNotFound = errors.New("not found")
+
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+func (e *notFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+func newNotFoundError(msg string) error {
+	return &notFoundError{msg: msg}
+}
+
+// ErrUnauthenticated matches errors for 401 Unauthorized responses, meaning the
+// client has no valid session or credentials and should log in again.
+// ErrForbidden matches errors for 403 Forbidden responses, meaning the credentials
+// are valid but not allowed to perform the request.
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+type unauthenticatedError struct {
+	msg string
+}
+
+func (e *unauthenticatedError) Error() string {
+	return e.msg
+}
+
+func (e *unauthenticatedError) Unwrap() error {
+	return ErrUnauthenticated
+}
+
+func newUnauthenticatedError(msg string) error {
+	return &unauthenticatedError{msg: msg}
+}
+
+// statusSentinel returns the sentinel matching an authentication status code.
+func statusSentinel(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case http.StatusForbidden:
+		return ErrForbidden
+	default:
+		return nil
+	}
+}
+
+// AuthError is returned for 401 and 403 responses that carry no structured API
+// error, such as a rejected login. TOTPRequired is set when the server has
+// two-factor authentication enabled, in which case a password alone cannot log in.
+type AuthError struct {
+	StatusCode   int
+	Message      string
+	TOTPRequired bool
+
+	Method    string
+	Path      string
+	RequestID string
+}
+
+func (e *AuthError) Error() string {
+	msg := fmt.Sprintf("pi-hole authentication failed (%d)", e.StatusCode)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	if e.TOTPRequired {
+		msg += " (2FA required)"
+	}
+
+	return msg + requestSuffix(e.Method, e.Path, e.RequestID)
+}
+
+// Is matches ErrUnauthenticated or ErrForbidden according to the status code.
+func (e *AuthError) Is(target error) bool {
+	return target != nil && target == statusSentinel(e.StatusCode)
+}
+
+type authErrorPayload struct {
+	Session *struct {
+		TOTP    bool   `json:"totp"`
+		Message string `json:"message"`
+	} `json:"session"`
+}
+
+func newAuthError(res *http.Response, body []byte) *AuthError {
+	authErr := &AuthError{StatusCode: res.StatusCode}
+	if res.Request != nil {
+		authErr.Method = res.Request.Method
+		authErr.Path = res.Request.URL.Path
+		authErr.RequestID = res.Request.Header.Get(requestIDHeader)
+	}
+
+	var payload authErrorPayload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Session != nil {
+		authErr.Message = payload.Session.Message
+		authErr.TOTPRequired = payload.Session.TOTP
+	}
+
+	return authErr
+}
+
+// Hint is the optional hint of a Pi-hole API error. Pi-hole emits hints as a string,
+// a list, or a key/value object depending on the endpoint.
+type Hint struct {
+	text   string
+	list   []string
+	fields map[string]string
+}
+
+// UnmarshalJSON decodes a hint from any of the shapes Pi-hole emits.
+func (h *Hint) UnmarshalJSON(data []byte) error {
+	*h = Hint{}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &h.text)
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		h.list = make([]string, 0, len(items))
+		for _, item := range items {
+			h.list = append(h.list, hintValueString(item))
+		}
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		h.fields = make(map[string]string, len(fields))
+		for key, value := range fields {
+			h.fields[key] = hintValueString(value)
+		}
+	default:
+		h.text = string(data)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the hint in the shape it was received in.
+func (h Hint) MarshalJSON() ([]byte, error) {
+	switch {
+	case h.fields != nil:
+		return json.Marshal(h.fields)
+	case h.list != nil:
+		return json.Marshal(h.list)
+	case h.text != "":
+		return json.Marshal(h.text)
+	default:
+		return []byte("null"), nil
+	}
+}
+
+// IsZero reports whether the error carried no hint.
+func (h Hint) IsZero() bool {
+	return h.text == "" && h.list == nil && h.fields == nil
+}
+
+// String renders the hint for display: lists are joined with "; " and objects are
+// rendered as key=value pairs sorted by key.
+func (h Hint) String() string {
+	switch {
+	case h.fields != nil:
+		keys := make([]string, 0, len(h.fields))
+		for key := range h.fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, h.fields[key]))
+		}
+		return strings.Join(pairs, ", ")
+	case h.list != nil:
+		return strings.Join(h.list, "; ")
+	default:
+		return h.text
+	}
+}
+
+// List returns the hint's items when Pi-hole sent a list, and nil otherwise.
+func (h Hint) List() []string {
+	return h.list
+}
+
+// Fields returns the hint's key/value pairs when Pi-hole sent an object, and nil otherwise.
+func (h Hint) Fields() map[string]string {
+	return h.fields
+}
+
+func hintValueString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(bytes.TrimSpace(raw))
+}
+
 type apiErrorDetails struct {
-	Key     string      `json:"key"`
-	Message string      `json:"message"`
-	Hint    interface{} `json:"hint"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	Hint    Hint   `json:"hint"`
 }
 
 type apiErrorPayload struct {
@@ -32,30 +255,146 @@ func parseAPIError(body []byte) (*apiErrorDetails, error) {
 	return payload.Error, nil
 }
 
-type DNSAPIError struct {
+// APIError is the structured error Pi-hole returns in an error response. The
+// service-specific error types embed it and unwrap to it, so callers can use
+// errors.As(err, &apiErr) with an *APIError regardless of the service.
+type APIError struct {
 	StatusCode int
 	Key        string
 	Message    string
-	Hint       interface{}
+	Hint       Hint
+
+	// Method, Path and RequestID identify the request that failed. RequestID is
+	// also sent to Pi-hole in the X-Request-ID header.
+	Method    string
+	Path      string
+	RequestID string
 }
 
-func (e *DNSAPIError) Error() string {
+func (e *APIError) Error() string {
 	if e == nil {
 		return ""
 	}
 
+	return e.format("")
+}
+
+// Is matches ErrUnauthenticated for 401 responses and ErrForbidden for 403
+// responses, so callers can tell a missing login from a permissions problem.
+func (e *APIError) Is(target error) bool {
+	return target != nil && target == statusSentinel(e.StatusCode)
+}
+
+// HintString returns the hint rendered for display, or "" if there is none.
+func (e *APIError) HintString() string {
+	return e.Hint.String()
+}
+
+// HintFields returns the hint's key/value pairs, or nil if the hint is not an object.
+func (e *APIError) HintFields() map[string]string {
+	return e.Hint.Fields()
+}
+
+func (e *APIError) format(subsystem string) string {
+	prefix := "pi-hole API error"
+	if subsystem != "" {
+		prefix = fmt.Sprintf("pi-hole %s API error", subsystem)
+	}
+
+	msg := fmt.Sprintf("%s (%d): %s", prefix, e.StatusCode, e.Message)
 	if e.Key != "" {
-		return fmt.Sprintf("pi-hole DNS API error (%d %s): %s", e.StatusCode, e.Key, e.Message)
+		msg = fmt.Sprintf("%s (%d %s): %s", prefix, e.StatusCode, e.Key, e.Message)
+	}
+
+	if hint := e.Hint.String(); hint != "" {
+		msg = fmt.Sprintf("%s (hint: %s)", msg, hint)
+	}
+
+	return msg + requestSuffix(e.Method, e.Path, e.RequestID)
+}
+
+func newAPIError(res *http.Response, body []byte) (APIError, error) {
+	var method, path, requestID string
+	if res.Request != nil {
+		method = res.Request.Method
+		path = res.Request.URL.Path
+		requestID = res.Request.Header.Get(requestIDHeader)
+	}
+
+	details, err := parseAPIError(body)
+	if err != nil {
+		if statusSentinel(res.StatusCode) != nil {
+			return APIError{}, newAuthError(res, body)
+		}
+
+		return APIError{}, fmt.Errorf("received unexpected status code %d %s%s", res.StatusCode, string(body), requestSuffix(method, path, requestID))
+	}
+
+	return APIError{
+		StatusCode: res.StatusCode,
+		Key:        details.Key,
+		Message:    details.Message,
+		Hint:       details.Hint,
+		Method:     method,
+		Path:       path,
+		RequestID:  requestID,
+	}, nil
+}
+
+// RequestError is returned when a request could not be sent or its response could
+// not be received.
+type RequestError struct {
+	Method    string
+	Path      string
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("failed to send request %s %s: %s%s", e.Method, e.Path, e.Err, requestSuffix("", "", e.RequestID))
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func requestSuffix(method string, path string, requestID string) string {
+	parts := make([]string, 0, 3)
+	if method != "" {
+		parts = append(parts, method)
+	}
+	if path != "" {
+		parts = append(parts, path)
+	}
+	if requestID != "" {
+		parts = append(parts, "request_id="+requestID)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " [" + strings.Join(parts, " ") + "]"
+}
+
+type DNSAPIError struct {
+	APIError
+}
+
+func (e *DNSAPIError) Error() string {
+	if e == nil {
+		return ""
 	}
 
-	return fmt.Sprintf("pi-hole DNS API error (%d): %s", e.StatusCode, e.Message)
+	return e.format("DNS")
+}
+
+func (e *DNSAPIError) Unwrap() error {
+	return &e.APIError
 }
 
 type CNAMEAPIError struct {
-	StatusCode int
-	Key        string
-	Message    string
-	Hint       interface{}
+	APIError
 }
 
 func (e *CNAMEAPIError) Error() string {
@@ -63,25 +402,327 @@ func (e *CNAMEAPIError) Error() string {
 		return ""
 	}
 
-	if e.Key != "" {
-		return fmt.Sprintf("pi-hole CNAME API error (%d %s): %s", e.StatusCode, e.Key, e.Message)
+	return e.format("CNAME")
+}
+
+func (e *CNAMEAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type DomainAPIError struct {
+	APIError
+}
+
+func (e *DomainAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("domain")
+}
+
+func (e *DomainAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type ListAPIError struct {
+	APIError
+}
+
+func (e *ListAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("list")
+}
+
+func (e *ListAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type StatsAPIError struct {
+	APIError
+}
+
+func (e *StatsAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("stats")
+}
+
+func (e *StatsAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type QueryAPIError struct {
+	APIError
+}
+
+func (e *QueryAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("query")
+}
+
+func (e *QueryAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type DHCPAPIError struct {
+	APIError
+}
+
+func (e *DHCPAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("DHCP")
+}
+
+func (e *DHCPAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type NetworkAPIError struct {
+	APIError
+}
+
+func (e *NetworkAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("network")
+}
+
+func (e *NetworkAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type ActionAPIError struct {
+	APIError
+}
+
+func (e *ActionAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("action")
+}
+
+func (e *ActionAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type TeleporterAPIError struct {
+	APIError
+}
+
+func (e *TeleporterAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("teleporter")
+}
+
+func (e *TeleporterAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type InfoAPIError struct {
+	APIError
+}
+
+func (e *InfoAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("info")
+}
+
+func (e *InfoAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type GroupAPIError struct {
+	APIError
+}
+
+func (e *GroupAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("group")
+}
+
+func (e *GroupAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type ClientAPIError struct {
+	APIError
+}
+
+func (e *ClientAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("client")
+}
+
+func (e *ClientAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+type WildcardAPIError struct {
+	APIError
+}
+
+func (e *WildcardAPIError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.format("wildcard")
+}
+
+func (e *WildcardAPIError) Unwrap() error {
+	return &e.APIError
+}
+
+func newDNSAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &DNSAPIError{APIError: apiErr}
+}
+
+func newCNAMEAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &CNAMEAPIError{APIError: apiErr}
+}
+
+func newDomainAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &DomainAPIError{APIError: apiErr}
+}
+
+func newListAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &ListAPIError{APIError: apiErr}
+}
+
+func newStatsAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &StatsAPIError{APIError: apiErr}
+}
+
+func newQueryAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &QueryAPIError{APIError: apiErr}
+}
+
+func newDHCPAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &DHCPAPIError{APIError: apiErr}
+}
+
+func newNetworkAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &NetworkAPIError{APIError: apiErr}
+}
+
+func newActionAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &ActionAPIError{APIError: apiErr}
+}
+
+func newTeleporterAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &TeleporterAPIError{APIError: apiErr}
+}
+
+func newInfoAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
+	}
+
+	return &InfoAPIError{APIError: apiErr}
+}
+
+func newWildcardAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Sprintf("pi-hole CNAME API error (%d): %s", e.StatusCode, e.Message)
+	return &WildcardAPIError{APIError: apiErr}
 }
 
-func newDNSAPIError(status int, body []byte) error {
-	if details, err := parseAPIError(body); err == nil {
-		return &DNSAPIError{StatusCode: status, Key: details.Key, Message: details.Message, Hint: details.Hint}
+func newClientAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("received unexpected status code %d %s", status, string(body))
+	return &ClientAPIError{APIError: apiErr}
 }
 
-func newCNAMEAPIError(status int, body []byte) error {
-	if details, err := parseAPIError(body); err == nil {
-		return &CNAMEAPIError{StatusCode: status, Key: details.Key, Message: details.Message, Hint: details.Hint}
+func newGroupAPIError(res *http.Response, body []byte) error {
+	apiErr, err := newAPIError(res, body)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("received unexpected status code %d %s", status, string(body))
+	return &GroupAPIError{APIError: apiErr}
 }
@@ -0,0 +1,153 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFoundSentinelsWrapErrNotFound(t *testing.T) {
+	sentinels := []error{
+		ErrorLocalDNSNotFound,
+		ErrorLocalCNAMENotFound,
+		ErrorDomainNotFound,
+		ErrorListNotFound,
+		ErrorDHCPLeaseNotFound,
+		ErrorSessionNotFound,
+		ErrorLocalRecordNotFound,
+		ErrorWildcardNotFound,
+		ErrorGroupNotFound,
+	}
+
+	for _, sentinel := range sentinels {
+		err := fmt.Errorf("%w: example.com", sentinel)
+		assert.ErrorIs(t, err, ErrNotFound, sentinel.Error())
+		assert.ErrorIs(t, err, sentinel)
+	}
+
+	assert.Equal(t, "local dns record not found", ErrorLocalDNSNotFound.Error())
+	assert.NotErrorIs(t, ErrorLocalDNSNotFound, ErrorLocalCNAMENotFound)
+}
+
+func TestAPIErrorHints(t *testing.T) {
+	tcs := []struct {
+		name       string
+		hint       string
+		wantString string
+		wantFields map[string]string
+		wantList   []string
+	}{
+		{name: "null", hint: `null`},
+		{name: "string", hint: `"Missing ')'"`, wantString: "Missing ')'"},
+		{name: "list", hint: `["first problem",2]`, wantString: "first problem; 2", wantList: []string{"first problem", "2"}},
+		{
+			name:       "object",
+			hint:       `{"item":"example.com","reason":"duplicate","line":3}`,
+			wantString: "item=example.com, line=3, reason=duplicate",
+			wantFields: map[string]string{"item": "example.com", "reason": "duplicate", "line": "3"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"error":{"key":"bad_request","message":"Invalid request","hint":%s}}`, tc.hint)
+
+			res := newHTTPResponse(http.StatusBadRequest, body)
+			err := newDNSAPIError(res, []byte(body))
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tc.wantString, apiErr.HintString())
+			assert.Equal(t, tc.wantFields, apiErr.HintFields())
+			assert.Equal(t, tc.wantList, apiErr.Hint.List())
+			assert.Equal(t, tc.hint == "null", apiErr.Hint.IsZero())
+
+			if tc.wantString != "" {
+				assert.Contains(t, err.Error(), "(hint: "+tc.wantString+")")
+			}
+		})
+	}
+}
+
+func TestAPIErrorsCarryRequestMetadata(t *testing.T) {
+	isUnit(t)
+
+	var sentID string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sentID = req.Header.Get("X-Request-ID")
+		if req.URL.Path == "/api/lists" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"Invalid request"}}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	_, err = client.Stats.QueryTypes(context.Background())
+	require.NotEmpty(t, sentID)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.MethodGet, apiErr.Method)
+	assert.Equal(t, "/api/stats/query_types", apiErr.Path)
+	assert.Equal(t, sentID, apiErr.RequestID)
+	assert.Contains(t, err.Error(), "request_id="+sentID)
+
+	_, err = client.Lists.List(context.Background())
+
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	assert.Equal(t, http.MethodGet, reqErr.Method)
+	assert.Equal(t, "/api/lists", reqErr.Path)
+	assert.Equal(t, sentID, reqErr.RequestID)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestAuthErrorsMatchStatusSentinels(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/action/flush/logs":
+			return newHTTPResponse(http.StatusForbidden, `{"error":{"key":"forbidden","message":"Action not allowed","hint":null}}`), nil
+		case "/api/action/restartdns":
+			return newHTTPResponse(http.StatusUnauthorized, `{"error":{"key":"unauthorized","message":"Unauthorized","hint":null}}`), nil
+		case "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusUnauthorized, ``), nil
+		case "/api/auth":
+			return newHTTPResponse(http.StatusUnauthorized, `{"session":{"valid":false,"totp":true,"sid":null,"validity":-1,"message":"password incorrect"}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", Password: "wrong", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	_, err = client.Actions.FlushLogs(context.Background())
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.NotErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = client.Actions.RestartDNS(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	assert.NotErrorIs(t, err, ErrForbidden)
+
+	_, err = client.LocalDNS.List(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = client.SessionAPI.Login(context.Background())
+	require.ErrorIs(t, err, ErrUnauthenticated)
+
+	var authErr *AuthError
+	require.ErrorAs(t, err, &authErr)
+	assert.True(t, authErr.TOTPRequired)
+	assert.Equal(t, "password incorrect", authErr.Message)
+	assert.Contains(t, authErr.Error(), "2FA required")
+
+	assert.ErrorIs(t, ErrorSessionUnauthorized, ErrUnauthenticated)
+}
@@ -0,0 +1,87 @@
+package pihole
+
+import (
+	"context"
+	"time"
+)
+
+// AuditOperation names a mutating client call.
+type AuditOperation string
+
+const (
+	AuditLocalDNSCreate   AuditOperation = "local_dns.create"
+	AuditLocalDNSUpdate   AuditOperation = "local_dns.update"
+	AuditLocalDNSDelete   AuditOperation = "local_dns.delete"
+	AuditLocalCNAMECreate AuditOperation = "local_cname.create"
+	AuditLocalCNAMEUpdate AuditOperation = "local_cname.update"
+	AuditLocalCNAMEDelete AuditOperation = "local_cname.delete"
+	AuditWildcardCreate   AuditOperation = "wildcard.create"
+	AuditWildcardDelete   AuditOperation = "wildcard.delete"
+	AuditDomainAdd        AuditOperation = "domain.add"
+	AuditDomainUpdate     AuditOperation = "domain.update"
+	AuditDomainDelete     AuditOperation = "domain.delete"
+	AuditGroupCreate      AuditOperation = "group.create"
+	AuditGroupUpdate      AuditOperation = "group.update"
+	AuditGroupDelete      AuditOperation = "group.delete"
+	AuditClientCreate     AuditOperation = "client.create"
+	AuditClientUpdate     AuditOperation = "client.update"
+	AuditClientDelete     AuditOperation = "client.delete"
+	AuditDHCPReserve      AuditOperation = "dhcp.reserve"
+	AuditDeviceDelete     AuditOperation = "device.delete"
+	AuditAction           AuditOperation = "action"
+	AuditTeleporterImport AuditOperation = "teleporter.import"
+	AuditTokenRotate      AuditOperation = "auth.rotate_token"
+	AuditConfigRestore    AuditOperation = "config.restore"
+)
+
+// AuditEvent describes one mutating call made through the client. Before and After
+// hold the affected value before and after the call where the client knows them.
+type AuditEvent struct {
+	Time time.Time
+	Mutation
+
+	// Err is the error the call returned, or nil if it succeeded.
+	Err error
+}
+
+// Succeeded reports whether the audited call succeeded.
+func (e AuditEvent) Succeeded() bool {
+	return e.Err == nil
+}
+
+// AuditSink receives an event for every mutating call made through the client. Record
+// is called synchronously once the call has finished, so slow sinks should buffer.
+type AuditSink interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(ctx context.Context, event AuditEvent)
+
+// Record calls f(ctx, event).
+func (f AuditSinkFunc) Record(ctx context.Context, event AuditEvent) {
+	f(ctx, event)
+}
+
+// auditValue dereferences v so that sinks receive a copy, mapping nil to a nil
+// interface rather than a typed nil pointer.
+func auditValue[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+
+	return *v
+}
+
+// audit passes the outcome of a mutating call to the configured sink, if any.
+func (c *Client) audit(ctx context.Context, m Mutation, err error) {
+	if c.auditSink == nil {
+		return
+	}
+
+	c.auditSink.Record(ctx, AuditEvent{
+		Time:     time.Now(),
+		Mutation: m,
+		Err:      err,
+	})
+}
@@ -0,0 +1,80 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditSink_RecordsMutations(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.1 nas.lan"}
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["`+strings.Join(hosts, `","`)+`"]}}}`), nil
+		case req.Method == http.MethodPut && req.URL.Path == "/api/config/dns/hosts/10.0.0.2 printer.lan":
+			hosts = append(hosts, "10.0.0.2 printer.lan")
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		case req.Method == http.MethodPut:
+			return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"invalid","hint":null}}`), nil
+		case req.Method == http.MethodDelete:
+			hosts = hosts[:1]
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	var events []AuditEvent
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+		AuditSink: AuditSinkFunc(func(_ context.Context, event AuditEvent) {
+			events = append(events, event)
+		}),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.LocalDNS.Create(ctx, "printer.lan", "10.0.0.2")
+	require.NoError(t, err)
+
+	_, err = client.LocalDNS.Create(ctx, "bad", "not-an-ip")
+	require.Error(t, err)
+
+	require.NoError(t, client.LocalDNS.Delete(ctx, "printer.lan"))
+
+	// Deleting a missing record changes nothing and is not audited.
+	require.NoError(t, client.LocalDNS.Delete(ctx, "missing.lan"))
+
+	require.Len(t, events, 3)
+
+	assert.Equal(t, AuditLocalDNSCreate, events[0].Operation)
+	assert.Equal(t, "printer.lan", events[0].Target)
+	assert.True(t, events[0].Succeeded())
+	assert.Nil(t, events[0].Before)
+	require.IsType(t, DNSRecord{}, events[0].After)
+	assert.Equal(t, "10.0.0.2", events[0].After.(DNSRecord).IP)
+	assert.False(t, events[0].Time.IsZero())
+
+	assert.Equal(t, AuditLocalDNSCreate, events[1].Operation)
+	assert.False(t, events[1].Succeeded())
+	assert.Nil(t, events[1].After)
+	var apiErr *DNSAPIError
+	assert.ErrorAs(t, events[1].Err, &apiErr)
+
+	assert.Equal(t, AuditLocalDNSDelete, events[2].Operation)
+	assert.True(t, events[2].Succeeded())
+	require.IsType(t, DNSRecord{}, events[2].Before)
+	assert.Equal(t, "printer.lan", events[2].Before.(DNSRecord).Domain)
+	assert.Nil(t, events[2].After)
+}
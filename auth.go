@@ -0,0 +1,143 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type Auth interface {
+	// RotateToken replaces the server's application password with a newly generated
+	// one and switches the client over to it, returning the new token so that it
+	// can be persisted. The previous token stops working once the new one has been
+	// verified; if verification fails the previous token is restored.
+	RotateToken(ctx context.Context) (string, error)
+}
+
+type authAPI struct {
+	client *Client
+}
+
+type appPasswordResponse struct {
+	App struct {
+		Password string `json:"password"`
+		Hash     string `json:"hash"`
+	} `json:"app"`
+}
+
+type appPasswordHashResponse struct {
+	Config appPasswordHashConfig `json:"config"`
+}
+
+type appPasswordHashConfig struct {
+	Webserver struct {
+		API struct {
+			AppPasswordHash string `json:"app_pwhash"`
+		} `json:"api"`
+	} `json:"webserver"`
+}
+
+// RotateToken generates a new application password, activates it, verifies it with an
+// authenticated request and swaps it into the client. Pi-hole keeps a single
+// application password, so activating the new one revokes the old one.
+// Clients authenticated with the application password itself need the server's
+// webserver.api.app_sudo setting enabled to change it.
+func (a authAPI) RotateToken(ctx context.Context) (token string, err error) {
+	m := Mutation{Operation: AuditTokenRotate, Target: "webserver.api.app_pwhash"}
+	defer func() {
+		a.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := a.client.beforeMutation(ctx, m); err != nil {
+		return "", err
+	}
+
+	var resCurrent appPasswordHashResponse
+	if err := a.client.getJSON(ctx, "/api/config/webserver/api/app_pwhash", &resCurrent); err != nil {
+		return "", fmt.Errorf("failed to fetch current application password: %w", err)
+	}
+	previousHash := resCurrent.Config.Webserver.API.AppPasswordHash
+
+	// Every GET generates a new password, so concurrent rotations must not share one.
+	var resApp appPasswordResponse
+	res, err := a.client.get(ctx, "/api/auth/app")
+	if err == nil {
+		err = readJSON(res, &resApp)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate application password: %w", err)
+	}
+	if resApp.App.Password == "" || resApp.App.Hash == "" {
+		return "", fmt.Errorf("failed to generate application password: empty response")
+	}
+
+	if err := a.setAppPasswordHash(ctx, a.client, resApp.App.Hash); err != nil {
+		return "", fmt.Errorf("failed to activate application password: %w", err)
+	}
+
+	if err := a.verify(ctx, resApp.App.Password); err != nil {
+		// Activating the new password revoked the old one, so a client authenticated
+		// with it can only restore the old one through the new password.
+		a.client.sessionLock.RLock()
+		usesToken := a.client.apiKey != ""
+		a.client.sessionLock.RUnlock()
+
+		restorer := a.client
+		if usesToken {
+			clone, cloneErr := a.client.With(WithAPIToken(resApp.App.Password))
+			if cloneErr != nil {
+				return "", fmt.Errorf("failed to verify application password: %w (restoring the previous one also failed: %s)", err, cloneErr)
+			}
+			restorer = clone
+		}
+
+		if restoreErr := a.setAppPasswordHash(ctx, restorer, previousHash); restoreErr != nil {
+			return "", fmt.Errorf("failed to verify application password: %w (restoring the previous one also failed: %s)", err, restoreErr)
+		}
+
+		return "", fmt.Errorf("failed to verify application password: %w", err)
+	}
+
+	a.client.sessionLock.Lock()
+	a.client.apiKey = resApp.App.Password
+	a.client.sessionLock.Unlock()
+
+	return resApp.App.Password, nil
+}
+
+// setAppPasswordHash activates hash through client, which is either the client
+// itself or a clone authenticated with another token.
+func (a authAPI) setAppPasswordHash(ctx context.Context, client *Client, hash string) error {
+	var req appPasswordHashResponse
+	req.Config.Webserver.API.AppPasswordHash = hash
+
+	res, err := client.request(ctx, http.MethodPatch, "/api/config", req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		apiErr, err := newAPIError(res, b)
+		if err != nil {
+			return err
+		}
+		return &apiErr
+	}
+
+	return nil
+}
+
+// verify checks that token authenticates, using a clone so that the client keeps
+// its current credentials until the new ones are known to work.
+func (a authAPI) verify(ctx context.Context, token string) error {
+	client, err := a.client.With(WithAPIToken(token))
+	if err != nil {
+		return err
+	}
+
+	var resSessions sessionListResponse
+	return client.getJSON(ctx, "/api/auth/sessions", &resSessions)
+}
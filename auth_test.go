@@ -0,0 +1,78 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRotationTestClient stubs an instance whose application password is "old". The
+// sessions endpoint accepts whichever token matches the active hash, unless
+// rejectNew is set, and only the active token may change the hash.
+func newRotationTestClient(t *testing.T, rejectNew bool) (*Client, *string) {
+	t.Helper()
+
+	hashes := map[string]string{"old": "hash-old", "new": "hash-new"}
+	activeHash := "hash-old"
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/webserver/api/app_pwhash":
+			return newHTTPResponse(http.StatusOK, `{"config":{"webserver":{"api":{"app_pwhash":"`+activeHash+`"}}}}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/auth/app":
+			return newHTTPResponse(http.StatusOK, `{"app":{"password":"new","hash":"hash-new"}}`), nil
+		case req.Method == http.MethodPatch && req.URL.Path == "/api/config":
+			// Only the active application password may change it.
+			if hashes[req.Header.Get(apiKeyHeader)] != activeHash {
+				return newHTTPResponse(http.StatusUnauthorized, `{"error":{"key":"unauthorized","message":"Unauthorized","hint":null}}`), nil
+			}
+			var body appPasswordHashResponse
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			activeHash = body.Config.Webserver.API.AppPasswordHash
+			return newHTTPResponse(http.StatusOK, `{}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/auth/sessions":
+			token := req.Header.Get(apiKeyHeader)
+			if hashes[token] != activeHash || (rejectNew && token == "new") {
+				return newHTTPResponse(http.StatusUnauthorized, `{"error":{"key":"unauthorized","message":"Unauthorized","hint":null}}`), nil
+			}
+			return newHTTPResponse(http.StatusOK, `{"sessions":[]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", APIToken: "old", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	return client, &activeHash
+}
+
+func TestAuth_RotateToken(t *testing.T) {
+	isUnit(t)
+
+	client, activeHash := newRotationTestClient(t, false)
+
+	token, err := client.Auth.RotateToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "new", token)
+	assert.Equal(t, "hash-new", *activeHash)
+
+	// The client now authenticates with the new token.
+	var resSessions sessionListResponse
+	assert.NoError(t, client.getJSON(context.Background(), "/api/auth/sessions", &resSessions))
+}
+
+func TestAuth_RotateTokenRestoresPreviousOnFailedVerification(t *testing.T) {
+	isUnit(t)
+
+	client, activeHash := newRotationTestClient(t, true)
+
+	_, err := client.Auth.RotateToken(context.Background())
+	require.ErrorIs(t, err, ErrUnauthenticated)
+	assert.Equal(t, "hash-old", *activeHash)
+	assert.Equal(t, "old", client.apiKey)
+}
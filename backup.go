@@ -0,0 +1,245 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupStore persists Teleporter archives for BackupScheduler.
+type BackupStore interface {
+	// Create returns a writer for a new backup. The backup is complete once the writer
+	// is closed without error. A writer that also implements BackupAborter is aborted
+	// rather than closed when writing the backup fails.
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+
+	// List returns the names of all stored backups.
+	List(ctx context.Context) ([]string, error)
+
+	// Delete removes a stored backup.
+	Delete(ctx context.Context, name string) error
+}
+
+// BackupAborter is implemented by backup writers that can discard a partly written
+// backup, so that a failed export is never stored as a complete one.
+type BackupAborter interface {
+	Abort() error
+}
+
+// BackupStoreFuncs adapts plain functions to a BackupStore, e.g. to write backups to
+// S3-compatible storage through a user-provided writer factory.
+type BackupStoreFuncs struct {
+	CreateFunc func(ctx context.Context, name string) (io.WriteCloser, error)
+	ListFunc   func(ctx context.Context) ([]string, error)
+	DeleteFunc func(ctx context.Context, name string) error
+}
+
+// Create calls CreateFunc.
+func (f BackupStoreFuncs) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return f.CreateFunc(ctx, name)
+}
+
+// List returns no backups when ListFunc is nil, which disables retention.
+func (f BackupStoreFuncs) List(ctx context.Context) ([]string, error) {
+	if f.ListFunc == nil {
+		return nil, nil
+	}
+	return f.ListFunc(ctx)
+}
+
+// Delete calls DeleteFunc.
+func (f BackupStoreFuncs) Delete(ctx context.Context, name string) error {
+	if f.DeleteFunc == nil {
+		return fmt.Errorf("backup store does not support deleting %s", name)
+	}
+	return f.DeleteFunc(ctx, name)
+}
+
+// DirBackupStore stores backups as files in a local directory.
+type DirBackupStore string
+
+// Create writes the backup to a temporary file that is renamed into place on Close.
+func (d DirBackupStore) Create(_ context.Context, name string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(string(d), 0o700); err != nil {
+		return nil, err
+	}
+
+	// Write to a temporary file so a failed backup never looks like a complete one.
+	f, err := os.CreateTemp(string(d), "."+name+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirBackupFile{File: f, path: filepath.Join(string(d), name)}, nil
+}
+
+// List returns the backups in the directory, ignoring hidden and temporary files.
+func (d DirBackupStore) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// Delete removes the backup file.
+func (d DirBackupStore) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(string(d), name))
+}
+
+type dirBackupFile struct {
+	*os.File
+	path string
+}
+
+func (f *dirBackupFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+
+	return os.Rename(f.File.Name(), f.path)
+}
+
+// Abort removes the temporary file without publishing it.
+func (f *dirBackupFile) Abort() error {
+	f.File.Close()
+	return os.Remove(f.File.Name())
+}
+
+// BackupScheduler periodically exports Teleporter archives to a BackupStore and
+// removes old ones beyond Retain.
+type BackupScheduler struct {
+	Client   *Client
+	Store    BackupStore
+	Interval time.Duration
+
+	// Retain is the number of backups to keep. Zero keeps every backup.
+	Retain int
+
+	// Prefix names backups as <Prefix>-<UTC timestamp>.zip. Only backups with this
+	// prefix are subject to retention. Defaults to "pihole-teleporter".
+	Prefix string
+
+	// OnError is called with errors from scheduled backups, which do not stop Run.
+	OnError func(error)
+}
+
+const backupTimeFormat = "20060102T150405Z"
+
+func (s *BackupScheduler) prefix() string {
+	if s.Prefix == "" {
+		return "pihole-teleporter"
+	}
+	return s.Prefix
+}
+
+// Run takes a backup immediately and then every Interval until ctx is cancelled.
+func (s *BackupScheduler) Run(ctx context.Context) error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("invalid backup interval %s", s.Interval)
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.BackupNow(ctx); err != nil && s.OnError != nil && ctx.Err() == nil {
+			s.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// BackupNow exports a Teleporter archive to the store, applies retention, and returns
+// the name of the new backup.
+func (s *BackupScheduler) BackupNow(ctx context.Context) (string, error) {
+	name := fmt.Sprintf("%s-%s.zip", s.prefix(), time.Now().UTC().Format(backupTimeFormat))
+
+	archive, err := s.Client.Teleporter.Export(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to export teleporter archive: %w", err)
+	}
+	defer archive.Close()
+
+	w, err := s.Store.Create(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(w, archive); err != nil {
+		if aborter, ok := w.(BackupAborter); ok {
+			aborter.Abort()
+		} else {
+			w.Close()
+		}
+		return "", fmt.Errorf("failed to write backup %s: %w", name, err)
+	}
+
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to write backup %s: %w", name, err)
+	}
+
+	if err := s.prune(ctx); err != nil {
+		return name, fmt.Errorf("failed to apply backup retention: %w", err)
+	}
+
+	return name, nil
+}
+
+func (s *BackupScheduler) prune(ctx context.Context) error {
+	if s.Retain <= 0 {
+		return nil
+	}
+
+	names, err := s.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	prefix := s.prefix() + "-"
+	backups := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".zip") {
+			backups = append(backups, name)
+		}
+	}
+
+	if len(backups) <= s.Retain {
+		return nil
+	}
+
+	// Timestamps sort lexically, so the oldest backups come first.
+	sort.Strings(backups)
+
+	var errs []error
+	for _, name := range backups[:len(backups)-s.Retain] {
+		if err := s.Store.Delete(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete backup %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupScheduler_BackupNowAppliesRetention(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/teleporter" {
+			return newHTTPResponse(http.StatusOK, "PK-archive"), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"pihole-teleporter-20240101T000000Z.zip",
+		"pihole-teleporter-20240102T000000Z.zip",
+		"pihole-teleporter-20240103T000000Z.zip",
+		"unrelated.zip",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old"), 0o600))
+	}
+
+	scheduler := &BackupScheduler{
+		Client: client,
+		Store:  DirBackupStore(dir),
+		Retain: 2,
+	}
+
+	name, err := scheduler.BackupNow(context.Background())
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	assert.Equal(t, "PK-archive", string(b))
+
+	names, err := DirBackupStore(dir).List(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pihole-teleporter-20240103T000000Z.zip", name, "unrelated.zip"}, names)
+}
+
+func TestBackupScheduler_RunRejectsInvalidInterval(t *testing.T) {
+	err := (&BackupScheduler{}).Run(context.Background())
+	assert.Error(t, err)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestBackupScheduler_BackupNowDiscardsFailedCopy(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		res := newHTTPResponse(http.StatusOK, "")
+		res.Body = io.NopCloser(io.MultiReader(strings.NewReader("PK-trunc"), failingReader{}))
+		return res, nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	existing := "pihole-teleporter-20240101T000000Z.zip"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, existing), []byte("old"), 0o600))
+
+	scheduler := &BackupScheduler{Client: client, Store: DirBackupStore(dir), Retain: 1}
+
+	_, err = scheduler.BackupNow(context.Background())
+	assert.ErrorContains(t, err, "connection reset")
+
+	// Neither the truncated archive nor its temporary file is left behind, and the
+	// valid backup survives retention.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, existing, entries[0].Name())
+}
@@ -0,0 +1,85 @@
+package pihole
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// baseURLProblem describes what is wrong with the normalized base URL, or returns ""
+// if it is an absolute http or https URL that API paths can be appended to. A
+// mistyped BaseURL thus fails in New rather than with a confusing 404 on the first
+// request.
+func baseURLProblem(raw string, baseURL string) string {
+	if strings.TrimSpace(raw) == "" {
+		return "is required"
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Sprintf("invalid URL %q: %s", raw, err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Sprintf("%q must include a scheme and host, e.g. http://pi.hole", raw)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Sprintf("%q must use http or https, not %s", raw, u.Scheme)
+	}
+
+	if u.RawQuery != "" || u.ForceQuery || u.Fragment != "" {
+		return fmt.Sprintf("%q must not include a query or fragment", raw)
+	}
+
+	return ""
+}
+
+// normalizeIPv6Host rewrites the host of an IPv6 base URL into the form url.Parse
+// accepts: the % introducing a zone ID is escaped as %25, as RFC 6874 requires, and a
+// bare literal such as http://fd00::53 is bracketed. A bare literal cannot carry a
+// port, since the port would read as part of the address.
+func normalizeIPv6Host(baseURL string) string {
+	scheme, rest, ok := strings.Cut(baseURL, "://")
+	if !ok {
+		return baseURL
+	}
+
+	host, path := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		host, path = rest[:i], rest[i:]
+	}
+
+	userinfo := ""
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		userinfo, host = host[:i+1], host[i+1:]
+	}
+
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end < 0 {
+			return baseURL
+		}
+		host = "[" + escapeZone(host[1:end]) + host[end:]
+	} else if strings.Count(host, ":") >= 2 {
+		addr, _, _ := strings.Cut(host, "%")
+		if net.ParseIP(addr) == nil {
+			return baseURL
+		}
+		host = "[" + escapeZone(host) + "]"
+	}
+
+	return scheme + "://" + userinfo + host + path
+}
+
+// escapeZone escapes the zone separator of an IPv6 literal such as fe80::1%eth0,
+// leaving literals that are already escaped alone.
+func escapeZone(literal string) string {
+	addr, zone, ok := strings.Cut(literal, "%")
+	if !ok || strings.HasPrefix(zone, "25") {
+		return literal
+	}
+
+	return addr + "%25" + zone
+}
@@ -0,0 +1,49 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPv6BaseURL(t *testing.T) {
+	isUnit(t)
+
+	tcs := []struct {
+		baseURL  string
+		wantURL  string
+		wantHost string
+	}{
+		{baseURL: "http://[fd00::53]:80", wantURL: "http://[fd00::53]:80/api/info/version", wantHost: "[fd00::53]:80"},
+		{baseURL: "http://[FD00::53]/api/", wantURL: "http://[FD00::53]/api/info/version", wantHost: "[FD00::53]"},
+		{baseURL: "http://fd00::53/", wantURL: "http://[fd00::53]/api/info/version", wantHost: "[fd00::53]"},
+		{baseURL: "http://[fe80::1%eth0]:8080/pihole", wantURL: "http://[fe80::1%25eth0]:8080/pihole/api/info/version", wantHost: "[fe80::1%eth0]:8080"},
+		{baseURL: "http://[fe80::1%25eth0]:8080", wantURL: "http://[fe80::1%25eth0]:8080/api/info/version", wantHost: "[fe80::1%eth0]:8080"},
+		{baseURL: "https://admin@[fd00::53]", wantURL: "https://admin@[fd00::53]/api/info/version", wantHost: "[fd00::53]"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.baseURL, func(t *testing.T) {
+			var got *http.Request
+			client, err := New(Config{
+				BaseURL:   tc.baseURL,
+				SessionID: "test",
+				HttpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					got = req
+					return newHTTPResponse(http.StatusOK, `{}`), nil
+				})},
+			})
+			require.NoError(t, err)
+
+			res, err := client.Get(context.Background(), "/api/info/version")
+			require.NoError(t, err)
+			res.Body.Close()
+
+			assert.Equal(t, tc.wantURL, got.URL.String())
+			assert.Equal(t, tc.wantHost, got.URL.Host)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BatchStatus is the outcome of one operation in a batch.
+type BatchStatus string
+
+const (
+	// BatchApplied operations took effect and were kept.
+	BatchApplied BatchStatus = "applied"
+	// BatchRolledBack operations took effect and were undone after a later failure.
+	BatchRolledBack BatchStatus = "rolled_back"
+	// BatchRollbackFailed operations took effect and could not be undone.
+	BatchRollbackFailed BatchStatus = "rollback_failed"
+	// BatchFailed is the operation that failed and stopped the batch.
+	BatchFailed BatchStatus = "failed"
+	// BatchSkipped operations were not attempted because an earlier one failed.
+	BatchSkipped BatchStatus = "skipped"
+)
+
+// BatchResult reports what happened to one operation of a batch.
+type BatchResult struct {
+	Operation AuditOperation
+	Target    string
+	Status    BatchStatus
+
+	// Err is the error of a failed operation or of a failed rollback.
+	Err error
+}
+
+// BatchReport lists the outcome of every operation in the order they were added.
+type BatchReport struct {
+	Results []BatchResult
+}
+
+var ErrBatchFailed = errors.New("batch failed")
+
+// OperationError identifies the record and operation a bulk call failed on. Bulk
+// calls such as Batch.Apply, Domains.AddBatch and OwnedDNS.Apply join one per failure
+// with errors.Join; OperationErrors lists them so callers can retry exactly those.
+type OperationError struct {
+	Operation AuditOperation
+	Target    string
+	Err       error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Operation, e.Target, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// OperationErrors returns every *OperationError in err's tree, in order. It looks
+// through errors.Join aggregates and fmt.Errorf wrapping, but not into an
+// OperationError's own cause.
+func OperationErrors(err error) []*OperationError {
+	var found []*OperationError
+
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *OperationError:
+			found = append(found, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+
+	return found
+}
+
+// Batch collects local DNS, CNAME and domain mutations and applies them in order.
+// When an operation fails, the operations already applied are undone in reverse
+// order on a best-effort basis. Pi-hole has no transactions, so other clients may
+// observe the intermediate states.
+type Batch struct {
+	client *Client
+	ctx    context.Context
+	steps  []batchStep
+}
+
+type batchStep struct {
+	operation AuditOperation
+	target    string
+
+	// apply performs the step and returns a function undoing it, or nil if the step
+	// changed nothing.
+	apply func(ctx context.Context) (undo func(ctx context.Context) error, err error)
+}
+
+// Batch starts an empty batch whose operations run with ctx.
+func (c *Client) Batch(ctx context.Context) *Batch {
+	return &Batch{client: c, ctx: ctx}
+}
+
+// CreateDNS adds the creation of a local DNS record.
+func (b *Batch) CreateDNS(record DNSRecord) *Batch {
+	return b.add(AuditLocalDNSCreate, record.Domain, func(ctx context.Context) (func(context.Context) error, error) {
+		dns := localDNS{client: b.client}
+		if _, err := dns.CreateRecord(ctx, &record); err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			return dns.delete(ctx, &record)
+		}, nil
+	})
+}
+
+// DeleteDNS adds the deletion of every local DNS record for domain, such as the
+// IPv4 and IPv6 lines of a dual-stack host. No record is not an error.
+func (b *Batch) DeleteDNS(domain string) *Batch {
+	return b.add(AuditLocalDNSDelete, domain, func(ctx context.Context) (func(context.Context) error, error) {
+		dns := localDNS{client: b.client}
+		records, err := dns.named(ctx, domain)
+		if err != nil {
+			return nil, err
+		}
+
+		// The records keep their original lines, so the restored entries are identical.
+		restore := func(ctx context.Context, records []DNSRecord) error {
+			errs := make([]error, 0)
+			for _, record := range records {
+				if _, err := dns.CreateRecord(ctx, &record); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		}
+
+		for i := range records {
+			if err := dns.delete(ctx, &records[i]); err != nil {
+				if undoErr := restore(ctx, records[:i]); undoErr != nil {
+					return nil, errors.Join(err, undoErr)
+				}
+				return nil, err
+			}
+		}
+
+		return func(ctx context.Context) error {
+			return restore(ctx, records)
+		}, nil
+	})
+}
+
+// CreateCNAME adds the creation of a CNAME record.
+func (b *Batch) CreateCNAME(record CNAMERecord) *Batch {
+	return b.add(AuditLocalCNAMECreate, record.Domain, func(ctx context.Context) (func(context.Context) error, error) {
+		cname := localCNAME{client: b.client}
+		if _, err := cname.CreateRecord(ctx, &record); err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			return cname.delete(ctx, &record)
+		}, nil
+	})
+}
+
+// DeleteCNAME adds the deletion of the CNAME record for domain. A missing record is
+// not an error.
+func (b *Batch) DeleteCNAME(domain string) *Batch {
+	return b.add(AuditLocalCNAMEDelete, domain, func(ctx context.Context) (func(context.Context) error, error) {
+		cname := localCNAME{client: b.client}
+		record, err := cname.Get(ctx, domain)
+		if errors.Is(err, ErrorLocalCNAMENotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cname.delete(ctx, record); err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			_, err := cname.CreateRecord(ctx, record)
+			return err
+		}, nil
+	})
+}
+
+// AddDomain adds the addition of an allow or deny domain entry. An entry that
+// already exists is left in place on rollback.
+func (b *Batch) AddDomain(kind DomainKind, entry DomainEntry) *Batch {
+	return b.add(AuditDomainAdd, entry.Domain, func(ctx context.Context) (func(context.Context) error, error) {
+		d := domains{client: b.client}
+		status, err := d.add(ctx, kind, entry)
+		if err != nil {
+			return nil, err
+		}
+		if status == DomainBatchDuplicate {
+			return nil, nil
+		}
+
+		return func(ctx context.Context) error {
+			return d.remove(ctx, entry.Type, kind, entry.Domain)
+		}, nil
+	})
+}
+
+func (b *Batch) add(operation AuditOperation, target string, apply func(context.Context) (func(context.Context) error, error)) *Batch {
+	b.steps = append(b.steps, batchStep{operation: operation, target: target, apply: apply})
+	return b
+}
+
+// Apply runs the operations in order. If one fails, the operations applied before it
+// are rolled back and the returned error, which wraps ErrBatchFailed and the
+// operation's error, is accompanied by a report of every operation. Rollbacks that
+// fail are joined to the error as well, since their operations remain applied.
+// Rollbacks run even if the batch's context has been cancelled.
+func (b *Batch) Apply() (*BatchReport, error) {
+	report := &BatchReport{Results: make([]BatchResult, len(b.steps))}
+	undos := make([]func(context.Context) error, len(b.steps))
+
+	for i, step := range b.steps {
+		report.Results[i] = BatchResult{Operation: step.operation, Target: step.target, Status: BatchSkipped}
+	}
+
+	for i, step := range b.steps {
+		undo, err := step.apply(b.ctx)
+		if err != nil {
+			report.Results[i].Status = BatchFailed
+			report.Results[i].Err = err
+
+			rollbackErrs := b.rollback(report, undos[:i])
+			errs := append([]error{&OperationError{Operation: step.operation, Target: step.target, Err: err}}, rollbackErrs...)
+
+			return report, fmt.Errorf("%w: %w", ErrBatchFailed, errors.Join(errs...))
+		}
+
+		report.Results[i].Status = BatchApplied
+		undos[i] = undo
+	}
+
+	return report, nil
+}
+
+func (b *Batch) rollback(report *BatchReport, undos []func(context.Context) error) []error {
+	ctx := context.WithoutCancel(b.ctx)
+
+	var errs []error
+	for i := len(undos) - 1; i >= 0; i-- {
+		if undos[i] == nil {
+			continue
+		}
+
+		if err := undos[i](ctx); err != nil {
+			report.Results[i].Status = BatchRollbackFailed
+			report.Results[i].Err = err
+			errs = append(errs, &OperationError{Operation: report.Results[i].Operation, Target: report.Results[i].Target, Err: fmt.Errorf("rollback failed: %w", err)})
+			continue
+		}
+
+		report.Results[i].Status = BatchRolledBack
+	}
+
+	return errs
+}
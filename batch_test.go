@@ -0,0 +1,79 @@
+package pihole
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_Apply(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.1 old.lan # rack 2"}
+	client := newHostsTestClient(t, &hosts)
+
+	report, err := client.Batch(context.Background()).
+		DeleteDNS("old.lan").
+		CreateDNS(DNSRecord{Domain: "new.lan", IP: "10.0.0.2"}).
+		DeleteDNS("missing.lan").
+		Apply()
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+	for _, result := range report.Results {
+		assert.Equal(t, BatchApplied, result.Status)
+	}
+	assert.Equal(t, []string{"10.0.0.2 new.lan"}, hosts)
+}
+
+func TestBatch_RollsBackAppliedOperationsOnFailure(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.1 old.lan # rack 2"}
+	client := newHostsTestClient(t, &hosts)
+
+	// The stub does not serve CNAME records, so the CNAME step fails.
+	report, err := client.Batch(context.Background()).
+		DeleteDNS("old.lan").
+		CreateDNS(DNSRecord{Domain: "new.lan", IP: "10.0.0.2"}).
+		CreateCNAME(CNAMERecord{Domain: "www.lan", Target: "new.lan"}).
+		CreateDNS(DNSRecord{Domain: "later.lan", IP: "10.0.0.3"}).
+		Apply()
+	require.ErrorIs(t, err, ErrBatchFailed)
+
+	statuses := make([]BatchStatus, 0, len(report.Results))
+	for _, result := range report.Results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []BatchStatus{BatchRolledBack, BatchRolledBack, BatchFailed, BatchSkipped}, statuses)
+	assert.Error(t, report.Results[2].Err)
+
+	failed := OperationErrors(err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, AuditLocalCNAMECreate, failed[0].Operation)
+	assert.Equal(t, "www.lan", failed[0].Target)
+
+	assert.Equal(t, []string{"10.0.0.1 old.lan # rack 2"}, hosts)
+}
+
+func TestBatch_DeleteDNSRemovesEveryRecordForDomain(t *testing.T) {
+	isUnit(t)
+
+	original := []string{"10.0.0.1 nas.lan # rack 2", "10.0.0.9 printer.lan", "fd00::1 nas.lan"}
+	hosts := append([]string(nil), original...)
+	client := newHostsTestClient(t, &hosts)
+
+	_, err := client.Batch(context.Background()).DeleteDNS("NAS.lan").Apply()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.9 printer.lan"}, hosts)
+
+	hosts = append([]string(nil), original...)
+	report, err := client.Batch(context.Background()).
+		DeleteDNS("nas.lan").
+		CreateCNAME(CNAMERecord{Domain: "www.lan", Target: "nas.lan"}).
+		Apply()
+	require.ErrorIs(t, err, ErrBatchFailed)
+	assert.Equal(t, BatchRolledBack, report.Results[0].Status)
+	assert.ElementsMatch(t, original, hosts)
+}
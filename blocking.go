@@ -0,0 +1,103 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Blocking interface {
+	// Status returns whether blocking is enabled and when a temporary change ends.
+	Status(ctx context.Context) (*BlockingStatus, error)
+
+	// Watch polls the blocking status and emits an event whenever its state changes.
+	// The channel is closed when ctx is done.
+	Watch(ctx context.Context, interval time.Duration) (<-chan BlockingEvent, error)
+}
+
+type blocking struct {
+	client *Client
+}
+
+// BlockingState is the blocking state FTL reports.
+type BlockingState string
+
+const (
+	BlockingEnabled  BlockingState = "enabled"
+	BlockingDisabled BlockingState = "disabled"
+	BlockingFailed   BlockingState = "failed"
+	BlockingUnknown  BlockingState = "unknown"
+)
+
+type BlockingStatus struct {
+	State BlockingState
+
+	// Timer is how long until the state reverts, or zero if the state is permanent.
+	Timer time.Duration
+}
+
+// BlockingEvent reports a change of the blocking state. Old is nil for error
+// events, which only carry Err.
+type BlockingEvent struct {
+	Old *BlockingStatus
+	New *BlockingStatus
+	Err error
+}
+
+type blockingResponse struct {
+	Blocking string   `json:"blocking"`
+	Timer    *float64 `json:"timer"`
+}
+
+func (res blockingResponse) toBlockingStatus() *BlockingStatus {
+	status := &BlockingStatus{State: BlockingState(res.Blocking)}
+	if res.Timer != nil {
+		status.Timer = time.Duration(*res.Timer * float64(time.Second))
+	}
+
+	return status
+}
+
+// Status returns the blocking status
+func (b blocking) Status(ctx context.Context) (*BlockingStatus, error) {
+	res, err := b.client.Get(ctx, "/api/dns/blocking")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		apiErr, err := newAPIError(res, body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &apiErr
+	}
+
+	var resBlocking blockingResponse
+	if err := json.NewDecoder(res.Body).Decode(&resBlocking); err != nil {
+		return nil, fmt.Errorf("failed to parse blocking body: %w", err)
+	}
+
+	return resBlocking.toBlockingStatus(), nil
+}
+
+// Watch polls the blocking status every interval and emits an event when its state
+// differs from the previous poll. Timer changes alone are not reported.
+func (b blocking) Watch(ctx context.Context, interval time.Duration) (<-chan BlockingEvent, error) {
+	return watch(ctx, interval, b.Status, diffBlockingStatus, func(err error) BlockingEvent {
+		return BlockingEvent{Err: err}
+	})
+}
+
+func diffBlockingStatus(before *BlockingStatus, after *BlockingStatus) []BlockingEvent {
+	if before.State == after.State {
+		return nil
+	}
+
+	return []BlockingEvent{{Old: before, New: after}}
+}
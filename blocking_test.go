@@ -0,0 +1,66 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocking_Status(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/dns/blocking" {
+			return newHTTPResponse(http.StatusOK, `{"blocking":"disabled","timer":299.5,"took":0.003}`), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	status, err := client.Blocking.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &BlockingStatus{State: BlockingDisabled, Timer: 299500 * time.Millisecond}, status)
+}
+
+func TestBlocking_Watch(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	body := `{"blocking":"enabled","timer":null}`
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return newHTTPResponse(http.StatusOK, body), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Blocking.Watch(ctx, 5*time.Millisecond)
+	require.NoError(t, err)
+
+	mu.Lock()
+	body = `{"blocking":"disabled","timer":60}`
+	mu.Unlock()
+
+	select {
+	case event := <-events:
+		require.NoError(t, event.Err)
+		assert.Equal(t, BlockingEnabled, event.Old.State)
+		assert.Equal(t, BlockingDisabled, event.New.State)
+		assert.Equal(t, time.Minute, event.New.Timer)
+	case <-time.After(time.Second):
+		t.Fatal("no blocking event")
+	}
+}
@@ -0,0 +1,251 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores API responses. Implementations must be safe for concurrent use; a
+// shared store such as Redis lets several processes reuse each other's responses.
+type Cache interface {
+	// Get returns the value stored under key, and false if there is none or it
+	// expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key for ttl. A ttl of zero or less never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+const defaultCacheTTL = 10 * time.Second
+
+// cachedSections are the top-level API paths whose GET responses are cached. They
+// hold configuration and list state that only changes through mutations, unlike
+// statistics or the query log.
+var cachedSections = map[string]bool{
+	"config":  true,
+	"groups":  true,
+	"domains": true,
+	"lists":   true,
+	"clients": true,
+}
+
+// cacheSection returns the section of an /api path, such as groups for
+// /api/groups/kids, and whether its responses are cached.
+func cacheSection(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, defaultAPIPath+"/")
+	if !ok {
+		return "", false
+	}
+
+	section, _, _ := strings.Cut(rest, "/")
+	section, _, _ = strings.Cut(section, "?")
+
+	return section, cachedSections[section]
+}
+
+// invalidatedSections returns the cached sections a mutation of section may change.
+func invalidatedSections(section string) []string {
+	switch section {
+	case "teleporter":
+		// Imports replace the configuration and the whole gravity database.
+		return []string{"config", "groups", "domains", "lists", "clients"}
+	case "groups":
+		// Deleting a group removes it from the domains, lists and clients using it.
+		return []string{"groups", "domains", "lists", "clients"}
+	}
+
+	if cachedSections[section] {
+		return []string{section}
+	}
+
+	return nil
+}
+
+// processCacheSecret keys cache identities unless Config.CacheKeySecret is set, so
+// that the keys in a shared store cannot be used to test guesses of the credentials.
+var processCacheSecret = func() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("pihole: failed to generate cache key secret: " + err.Error())
+	}
+
+	return secret
+}()
+
+// cacheIdentity identifies the credentials requests are sent with, so that clients
+// sharing a store never see responses read with other credentials. Clients logging
+// in with a password share responses across sessions.
+func (c *Client) cacheIdentity() string {
+	c.sessionLock.RLock()
+	credentials := []string{c.apiKey, c.headers.Get(apiKeyHeader), c.password}
+	if c.apiKey == "" && c.password == "" {
+		credentials = append(credentials, c.auth.sid)
+	}
+	c.sessionLock.RUnlock()
+
+	if c.basicAuth != nil {
+		credentials = append(credentials, c.basicAuth.String())
+	}
+
+	mac := hmac.New(sha256.New, c.cacheSecret)
+	mac.Write([]byte(strings.Join(credentials, "\x00")))
+
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// cacheGenerationKey names the entry holding the current generation of a section.
+// Cached responses are stored under the generation they were read in, so deleting
+// the generation invalidates every response of the section at once, in every
+// process sharing the cache.
+func (c *Client) cacheGenerationKey(section string) string {
+	return "pihole:" + c.baseURL + c.apiPath + ":" + section + ":generation"
+}
+
+// cachedGet serves a GET from the cache, or sends it and caches a 200 response.
+// Cache failures fall back to the API.
+func (c *Client) cachedGet(ctx context.Context, path string, section string) (*http.Response, error) {
+	generationKey := c.cacheGenerationKey(section)
+
+	generation, ok, err := c.cache.Get(ctx, generationKey)
+	if err != nil {
+		return c.do(ctx, http.MethodGet, path, nil, "", "", -1)
+	}
+	if !ok {
+		generation = []byte(newRequestID())
+		if err := c.cache.Set(ctx, generationKey, generation, 0); err != nil {
+			return c.do(ctx, http.MethodGet, path, nil, "", "", -1)
+		}
+	}
+
+	key := "pihole:" + c.baseURL + c.apiPath + ":" + c.cacheIdentity() + ":" + string(generation) + ":" + path
+	if body, ok, err := c.cache.Get(ctx, key); err == nil && ok {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+c.resolvePath(path), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+
+	res, err := c.do(ctx, http.MethodGet, path, nil, "", "", -1)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, &RequestError{Method: http.MethodGet, Path: path, Err: err}
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	_ = c.cache.Set(ctx, key, body, c.cacheTTL)
+
+	return res, nil
+}
+
+// invalidateCache drops the cached responses of the sections a mutation may have
+// changed. It runs whether or not the mutation succeeded, since a failed request may
+// still have been applied.
+func (c *Client) invalidateCache(ctx context.Context, path string) {
+	if c.cache == nil {
+		return
+	}
+
+	section, _ := cacheSection(path)
+	for _, section := range invalidatedSections(section) {
+		_ = c.cache.Delete(context.WithoutCancel(ctx), c.cacheGenerationKey(section))
+	}
+}
+
+// MemoryCache is an in-process Cache, the default for clients that do not share
+// state with other processes.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	sets    int
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// memoryCacheSweepInterval is how many Set calls pass between removals of expired
+// entries that were never read again.
+const memoryCacheSweepInterval = 1000
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := memoryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	m.entries[key] = entry
+
+	m.sets++
+	if m.sets%memoryCacheSweepInterval == 0 {
+		now := time.Now()
+		for key, entry := range m.entries {
+			if entry.expired(now) {
+				delete(m.entries, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+
+	return nil
+}
+
+func (e memoryCacheEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
@@ -0,0 +1,247 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheTestTransport(gets map[string]int, mu *sync.Mutex) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if req.Method != http.MethodGet {
+			return newHTTPResponse(http.StatusCreated, `{"groups":[],"processed":{"success":[],"errors":[]}}`), nil
+		}
+
+		gets[req.URL.Path]++
+		switch req.URL.Path {
+		case "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+		case "/api/stats/query_types":
+			return newHTTPResponse(http.StatusOK, `{"types":{"A":1}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	}
+}
+
+func TestCache_ServesReadsUntilMutation(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	gets := make(map[string]int)
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: &http.Client{Transport: newCacheTestTransport(gets, &mu)},
+		CacheTTL:   time.Minute,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		groups, err := client.Groups.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, groups, 1)
+	}
+	assert.Equal(t, 1, gets["/api/groups"])
+
+	res, err := client.Post(ctx, "/api/groups", groupRequest{Name: "kids"})
+	require.NoError(t, err)
+	res.Body.Close()
+
+	_, err = client.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+
+	// Statistics change without mutations and are never cached.
+	for i := 0; i < 2; i++ {
+		_, err := client.Stats.QueryTypes(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, gets["/api/stats/query_types"])
+}
+
+func TestCache_SharedBetweenClients(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	gets := make(map[string]int)
+	cache := NewMemoryCache()
+
+	newClient := func() *Client {
+		client, err := New(Config{
+			BaseURL:    "http://pi.test",
+			SessionID:  "test",
+			HttpClient: &http.Client{Transport: newCacheTestTransport(gets, &mu)},
+			Cache:      cache,
+		})
+		require.NoError(t, err)
+		return client
+	}
+	reader, writer := newClient(), newClient()
+	ctx := context.Background()
+
+	_, err := reader.Groups.List(ctx)
+	require.NoError(t, err)
+	_, err = writer.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, gets["/api/groups"])
+
+	res, err := writer.Post(ctx, "/api/groups", groupRequest{Name: "kids"})
+	require.NoError(t, err)
+	res.Body.Close()
+
+	_, err = reader.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+}
+
+func TestCache_CrossSectionWrites(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	gets := make(map[string]int)
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: &http.Client{Transport: newCacheTestTransport(gets, &mu)},
+		CacheTTL:   time.Minute,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Groups.List(ctx)
+	require.NoError(t, err)
+
+	// Teleporter imports replace the groups along with everything else.
+	res, err := client.Post(ctx, "/api/teleporter", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	_, err = client.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+
+	assert.ElementsMatch(t, []string{"groups", "domains", "lists", "clients"}, invalidatedSections("groups"))
+	assert.Equal(t, []string{"lists"}, invalidatedSections("lists"))
+	assert.Empty(t, invalidatedSections("auth"))
+}
+
+func TestCache_KeyedByCredentials(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	gets := make(map[string]int)
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		APIKey:     "first",
+		HttpClient: &http.Client{Transport: newCacheTestTransport(gets, &mu)},
+		CacheTTL:   time.Minute,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Groups.List(ctx)
+	require.NoError(t, err)
+	second, err := client.With(WithAPIToken("second"))
+	require.NoError(t, err)
+	_, err = second.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+
+	first, err := client.With(WithAPIToken("first"))
+	require.NoError(t, err)
+	_, err = first.Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+}
+
+func TestCache_KeyedBySecret(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	gets := make(map[string]int)
+	cache := NewMemoryCache()
+
+	newClient := func(secret string) *Client {
+		client, err := New(Config{
+			BaseURL:        "http://pi.test",
+			APIKey:         "token",
+			HttpClient:     &http.Client{Transport: newCacheTestTransport(gets, &mu)},
+			Cache:          cache,
+			CacheKeySecret: []byte(secret),
+		})
+		require.NoError(t, err)
+		return client
+	}
+	ctx := context.Background()
+
+	// Clients in one process share responses through the process secret.
+	_, err := newClient("").Groups.List(ctx)
+	require.NoError(t, err)
+	_, err = newClient("").Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, gets["/api/groups"])
+
+	// Other processes only share them if they are given the same secret.
+	_, err = newClient("fleet").Groups.List(ctx)
+	require.NoError(t, err)
+	_, err = newClient("fleet").Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets["/api/groups"])
+
+	_, err = newClient("other").Groups.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, gets["/api/groups"])
+
+	assert.NotEqual(t, newClient("fleet").cacheIdentity(), newClient("other").cacheIdentity())
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "short", []byte("a"), time.Millisecond))
+	require.NoError(t, cache.Set(ctx, "forever", []byte("b"), 0))
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok, err := cache.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	value, ok, err := cache.Get(ctx, "forever")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("b"), value)
+
+	require.NoError(t, cache.Delete(ctx, "forever"))
+	_, ok, _ = cache.Get(ctx, "forever")
+	assert.False(t, ok)
+}
+
+func TestCacheSection(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/config/dns/hosts":   "config",
+		"/api/groups":             "groups",
+		"/api/domains/deny/exact": "domains",
+		"/api/lists?type=block":   "lists",
+	} {
+		section, ok := cacheSection(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, section, path)
+	}
+
+	_, ok := cacheSection("/api/stats/summary")
+	assert.False(t, ok)
+}
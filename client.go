@@ -3,19 +3,28 @@ package pihole
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 )
 
 type Config struct {
+	// BaseURL is the URL Pi-hole is served at, which may include a path prefix when
+	// it sits behind a reverse proxy, e.g. https://router.local/pihole. A trailing
+	// /api is accepted and treated as the API root. New rejects URLs without an http
+	// or https scheme and a host, and URLs with a query or fragment.
 	BaseURL    string
 	Password   string
 	SessionID  string
@@ -23,41 +32,225 @@ type Config struct {
 	Headers    http.Header
 	APIToken   string
 	APIKey     string
+
+	// BasicAuthUser and BasicAuthPassword are sent as HTTP Basic credentials on every
+	// request, in addition to Pi-hole's own authentication, for instances behind a
+	// reverse proxy that requires them.
+	BasicAuthUser     string
+	BasicAuthPassword string
+
+	// LenientParsing skips local DNS and CNAME entries that cannot be parsed instead
+	// of failing the whole list, so that one malformed line does not block Get and
+	// Delete for every other record.
+	LenientParsing bool
+
+	// RecordCodec parses and encodes local DNS and CNAME entries, DefaultRecordCodec
+	// if nil. Replace it for Pi-hole releases or forks with another entry syntax.
+	RecordCodec RecordCodec
+
+	// APIPath is the path of the API below BaseURL, "/api" by default. Set it when a
+	// reverse proxy exposes the API under another path.
+	APIPath string
+
+	// Gzip requests compressed responses and compresses large JSON request bodies.
+	// Bodies are sent uncompressed again if the server rejects them with 415
+	// Unsupported Media Type.
+	Gzip bool
+
+	// Transport tunes the connection pool of the default HTTP client. It cannot be
+	// combined with HttpClient, whose transport is left untouched.
+	Transport TransportConfig
+
+	// PasswordFile and APITokenFile name files holding the password or API token,
+	// such as Docker or Kubernetes secret mounts. They are read once by New, with
+	// surrounding whitespace trimmed, and cannot be combined with Password or APIToken.
+	PasswordFile string
+	APITokenFile string
+
+	// SessionRefreshMargin is how long before a password session expires the client
+	// logs in again, so that it never sends a request with a lapsed session. It
+	// defaults to 30 seconds and is capped at a quarter of the session's validity;
+	// a negative value disables proactive refreshes.
+	SessionRefreshMargin time.Duration
+
+	// SessionTransport selects how the session ID is sent: in the X-FTL-SID header
+	// (the default) or in the sid cookie as the web interface does, for reverse
+	// proxies that strip custom headers.
+	SessionTransport SessionTransport
+
+	// AuditSink, when set, receives an event for every mutating call made through the
+	// client, for environments where DNS changes must be traceable.
+	AuditSink AuditSink
+
+	// CacheTTL enables caching of configuration and list reads (config, groups,
+	// domains, adlists and clients) for the given time. Cache selects where responses
+	// are stored, an in-memory MemoryCache by default; setting it alone enables
+	// caching for 10 seconds. Mutations sent through any client sharing the cache
+	// invalidate the cached responses of the section they change, but changes made
+	// elsewhere, e.g. in the web interface, show up only once entries expire.
+	CacheTTL time.Duration
+	Cache    Cache
+
+	// CacheKeySecret keys the HMAC that turns credentials into cache keys. Processes
+	// sharing a Cache must use the same secret to reuse each other's responses; by
+	// default each process uses a random one, so responses are only reused within
+	// the process while invalidations still reach every process.
+	CacheKeySecret []byte
+
+	// SlowRequestThreshold is how long a request may take before the hooks
+	// registered with OnSlowRequest are called with it, to find the instance or
+	// endpoint slowing down automation runs. Zero disables the hooks.
+	SlowRequestThreshold time.Duration
+}
+
+// SessionTransport selects how the session ID is carried on requests.
+type SessionTransport string
+
+const (
+	SessionTransportHeader SessionTransport = "header"
+	SessionTransportCookie SessionTransport = "cookie"
+)
+
+// TransportConfig tunes connection reuse for callers that send many concurrent
+// requests to one instance, or a few requests each to many instances. Zero values
+// keep the net/http defaults.
+type TransportConfig struct {
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+
+	// Protocol pins the HTTP version, for embedded webservers and proxies that
+	// misbehave with the default negotiation.
+	Protocol HTTPProtocol
+
+	// DialContext replaces the dialer of the default client, e.g. to reach the
+	// instance through a tunnel.
+	DialContext func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+	// Resolver looks up the instance's hostname instead of the system resolver,
+	// which is often the very Pi-hole being configured. It cannot be combined with
+	// DialContext.
+	Resolver *net.Resolver
+
+	// ResolveTo connects to this IP address, optionally with a port, whenever the
+	// client dials the BaseURL host, without looking the host up. The URL keeps its
+	// host for the Host header and TLS certificate verification.
+	ResolveTo string
 }
 
+// HTTPProtocol selects the HTTP version the default client speaks.
+type HTTPProtocol string
+
+const (
+	// HTTPProtocolAuto negotiates HTTP/2 with TLS servers that offer it and uses
+	// HTTP/1.1 otherwise, as net/http does.
+	HTTPProtocolAuto HTTPProtocol = ""
+	// HTTPProtocol1 always uses HTTP/1.1.
+	HTTPProtocol1 HTTPProtocol = "http/1.1"
+	// HTTPProtocol2 uses HTTP/2 over TLS, and unencrypted HTTP/2 (h2c) with prior
+	// knowledge for http:// base URLs. h2c requires Go 1.24 or later.
+	HTTPProtocol2 HTTPProtocol = "h2"
+)
+
 type Client struct {
 	baseURL         string
+	apiPath         string
 	password        string
 	headers         http.Header
 	http            *http.Client
 	auth            auth
 	publicEndpoints map[string]bool
 	apiKey          string
+	limits          limitTracker
+	gzip            bool
+	basicAuth       *url.Userinfo
+	lenientParsing  bool
+	codec           RecordCodec
+	refreshMargin   time.Duration
+	sessionCookie   bool
+	retry           *retrySettings
+	auditSink       AuditSink
+	hooks           *mutationHooks
+	cache           Cache
+	cacheTTL        time.Duration
+	cacheSecret     []byte
+	slowThreshold   time.Duration
+
+	// optionProblems collects invalid options while With applies them.
+	optionProblems []ConfigProblem
+
+	gzipRequestsRejected atomic.Bool
+
+	flights flightGroup
 
 	sessionLock sync.RWMutex
-
-	LocalDNS   LocalDNS
-	LocalCNAME LocalCNAME
-	SessionAPI SessionAPI
+	refreshLock sync.Mutex
+
+	LocalDNS      LocalDNS
+	LocalCNAME    LocalCNAME
+	LocalRecords  LocalRecords
+	Wildcards     Wildcards
+	SessionAPI    SessionAPI
+	Auth          Auth
+	Domains       Domains
+	Groups        Groups
+	Clients       Clients
+	Lists         Lists
+	Blocking      Blocking
+	Stats         Stats
+	StatsDatabase StatsDatabase
+	Queries       Queries
+	DHCP          DHCP
+	Network       Network
+	Actions       Actions
+	Teleporter    Teleporter
+	Info          Info
+	Messages      Messages
+	Config        ConfigAPI
 }
 
 type auth struct {
 	sid string
+
+	// csrf is the CSRF token Pi-hole issued with the session, sent on mutating requests.
+	csrf string
+
+	// validity is how long the session lasts without activity, and expires when it
+	// lapses. Both are zero for sessions the client did not log in itself.
+	validity time.Duration
+	expires  time.Time
 }
 
 const (
-	authHeader = "X-FTL-SID"
+	authHeader        = "X-FTL-SID"
+	sessionCookieName = "sid"
+	csrfHeader        = "X-FTL-CSRF"
+	apiKeyHeader      = "X-FTL-APIKEY"
+	requestIDHeader   = "X-Request-ID"
 )
 
 // New returns a new Pi-hole client
 func New(config Config) (*Client, error) {
-	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	baseURL, apiPath := splitBaseURL(config.BaseURL)
+	if err := config.validate(baseURL); err != nil {
+		return nil, err
+	}
+	if config.APIPath != "" {
+		apiPath = strings.TrimSuffix("/"+strings.Trim(config.APIPath, "/"), "/")
+	}
 
 	var httpClient *http.Client
+	var retry *retrySettings
 	if config.HttpClient != nil {
 		httpClient = config.HttpClient
 	} else {
-		httpClient = retryablehttp.NewClient().StandardClient()
+		retryClient := retryablehttp.NewClient()
+		retryClient.CheckRetry = retryPolicy
+		retry = &retrySettings{max: retryClient.RetryMax, waitMin: retryClient.RetryWaitMin, waitMax: retryClient.RetryWaitMax}
+		if transport, ok := retryClient.HTTPClient.Transport.(*http.Transport); ok {
+			config.Transport.apply(transport, baseURL)
+		}
+		httpClient = retryClient.StandardClient()
 	}
 
 	headers := make(http.Header)
@@ -70,12 +263,23 @@ func New(config Config) (*Client, error) {
 	}
 
 	client := &Client{
-		baseURL:  baseURL,
-		http:     httpClient,
-		headers:  headers,
-		password: config.Password,
+		baseURL:        baseURL,
+		apiPath:        apiPath,
+		http:           httpClient,
+		headers:        headers,
+		password:       config.Password,
+		gzip:           config.Gzip,
+		lenientParsing: config.LenientParsing,
+		codec:          config.RecordCodec,
+		refreshMargin:  config.SessionRefreshMargin,
+		retry:          retry,
+		auditSink:      config.AuditSink,
+		slowThreshold:  config.SlowRequestThreshold,
+		hooks:          &mutationHooks{},
 		publicEndpoints: map[string]bool{
-			"POST /api/auth": true,
+			"POST /api/auth":      true,
+			"GET /api/auth":       true,
+			"GET /api/info/login": true,
 		},
 	}
 
@@ -85,46 +289,184 @@ func New(config Config) (*Client, error) {
 	}
 	client.apiKey = apiKey
 
-	if apiKey != "" {
-		headers.Set("X-FTL-APIKEY", apiKey)
+	if config.SessionTransport == SessionTransportCookie {
+		client.sessionCookie = true
+	}
+
+	if client.refreshMargin == 0 {
+		client.refreshMargin = defaultSessionRefreshMargin
 	}
 
 	if config.SessionID != "" {
 		client.auth.sid = config.SessionID
 	}
 
-	client.LocalDNS = &localDNS{client: client}
-	client.LocalCNAME = &localCNAME{client: client}
-	client.SessionAPI = &sessionAPI{client: client}
+	if config.BasicAuthUser != "" || config.BasicAuthPassword != "" {
+		client.basicAuth = url.UserPassword(config.BasicAuthUser, config.BasicAuthPassword)
+	}
+
+	if config.Cache != nil || config.CacheTTL > 0 {
+		client.cache = config.Cache
+		if client.cache == nil {
+			client.cache = NewMemoryCache()
+		}
+
+		client.cacheTTL = config.CacheTTL
+		if client.cacheTTL == 0 {
+			client.cacheTTL = defaultCacheTTL
+		}
+
+		client.cacheSecret = config.CacheKeySecret
+		if len(client.cacheSecret) == 0 {
+			client.cacheSecret = processCacheSecret
+		}
+	}
+
+	client.initServices()
 
 	return client, nil
 }
 
+// initServices points every service at the client.
+func (c *Client) initServices() {
+	c.LocalDNS = &localDNS{client: c}
+	c.LocalCNAME = &localCNAME{client: c}
+	c.LocalRecords = &localRecords{client: c}
+	c.Wildcards = &wildcards{client: c}
+	c.SessionAPI = &sessionAPI{client: c}
+	c.Auth = &authAPI{client: c}
+	c.Domains = &domains{client: c}
+	c.Groups = &groups{client: c}
+	c.Clients = &clients{client: c}
+	c.Lists = &lists{client: c}
+	c.Blocking = &blocking{client: c}
+	c.Stats = &stats{client: c}
+	c.StatsDatabase = &statsDatabase{client: c}
+	c.Queries = &queries{client: c}
+	c.DHCP = &dhcp{client: c}
+	c.Network = &network{client: c}
+	c.Actions = &actions{client: c}
+	c.Teleporter = &teleporter{client: c}
+	c.Info = &info{client: c}
+	c.Messages = &messages{client: c}
+	c.Config = &configAPI{client: c}
+}
+
 var ErrClientValidation = errors.New("invalid client configuration")
 
+const defaultAPIPath = "/api"
+
+// splitBaseURL trims trailing slashes from a base URL and splits off a trailing /api,
+// so that URLs pointing at the API root work as well as URLs pointing at Pi-hole.
+// IPv6 hosts are normalized so that request URLs built from it parse.
+func splitBaseURL(baseURL string) (string, string) {
+	baseURL = normalizeIPv6Host(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+	baseURL = strings.TrimSuffix(baseURL, defaultAPIPath)
+
+	return baseURL, defaultAPIPath
+}
+
+// resolvePath maps the /api paths used throughout the client onto the configured
+// API path.
+func (c *Client) resolvePath(path string) string {
+	if c.apiPath == defaultAPIPath {
+		return path
+	}
+
+	if path == defaultAPIPath || strings.HasPrefix(path, defaultAPIPath+"/") || strings.HasPrefix(path, defaultAPIPath+"?") {
+		return c.apiPath + strings.TrimPrefix(path, defaultAPIPath)
+	}
+
+	return path
+}
+
+func (t TransportConfig) apply(transport *http.Transport, baseURL string) {
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = t.IdleConnTimeout
+	}
+	if t.DisableKeepAlives {
+		transport.DisableKeepAlives = true
+	}
+
+	if dial := t.dialContext(baseURL); dial != nil {
+		transport.DialContext = dial
+	}
+
+	if t.Protocol != HTTPProtocolAuto {
+		t.Protocol.apply(transport)
+	}
+}
+
 func (c *Client) request(ctx context.Context, method string, path string, body interface{}) (*http.Response, error) {
-	url := c.baseURL + path
+	if body == nil {
+		if method == http.MethodGet {
+			return c.coalescedGet(ctx, path)
+		}
+
+		return c.do(ctx, method, path, nil, "", "", -1)
+	}
 
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.shouldCompress(jsonData) {
+		compressed, err := gzipBytes(jsonData)
 		if err != nil {
 			return nil, err
 		}
-		reqBody = bytes.NewBuffer(jsonData)
+
+		res, err := c.do(ctx, method, path, bytes.NewReader(compressed), "application/json", "gzip", -1)
+		if err != nil || res.StatusCode != http.StatusUnsupportedMediaType {
+			return res, err
+		}
+
+		// The server does not accept compressed bodies; send this and later requests
+		// uncompressed.
+		res.Body.Close()
+		c.gzipRequestsRejected.Store(true)
 	}
 
+	return c.do(ctx, method, path, bytes.NewReader(jsonData), "application/json", "", -1)
+}
+
+// do sends a request with a pre-encoded body, authenticating it unless the endpoint is public.
+// A non-negative contentLength is sent as the request's Content-Length for bodies whose
+// size net/http cannot determine itself.
+func (c *Client) do(ctx context.Context, method string, path string, reqBody io.Reader, contentType string, contentEncoding string, contentLength int64) (*http.Response, error) {
+	url := c.baseURL + c.resolvePath(path)
+
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create req with context %s %s: %w", method, path, err)
 	}
 
-	if _, ok := c.publicEndpoints[fmt.Sprintf("%s %s", method, path)]; !ok {
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
+
+	var apiKey, csrf string
+
+	_, public := c.publicEndpoints[fmt.Sprintf("%s %s", method, path)]
+	if !public {
 		c.sessionLock.RLock()
 		sid := c.auth.sid
+		apiKey = c.apiKey
 		c.sessionLock.RUnlock()
 
-		if sid == "" && c.apiKey == "" {
+		if sid != "" && apiKey == "" {
+			refreshed, err := c.refreshSession(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to refresh session: %w", err)
+			}
+			sid = refreshed
+		}
+
+		if sid == "" && apiKey == "" {
 			session, err := c.SessionAPI.Login(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to login: %w", err)
@@ -140,7 +482,17 @@ func (c *Client) request(ctx context.Context, method string, path string, body i
 		}
 
 		if sid != "" {
-			req.Header.Set(authHeader, sid)
+			if c.sessionCookie {
+				req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: sid})
+			} else {
+				req.Header.Set(authHeader, sid)
+			}
+
+			c.sessionLock.RLock()
+			if c.auth.sid == sid {
+				csrf = c.auth.csrf
+			}
+			c.sessionLock.RUnlock()
 		}
 	}
 
@@ -148,18 +500,109 @@ func (c *Client) request(ctx context.Context, method string, path string, body i
 		req.Header[key] = header
 	}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	// Public endpoints are called as an anonymous client so that they report what an
+	// unauthenticated caller would see.
+	if public {
+		req.Header.Del(apiKeyHeader)
+	} else if apiKey != "" {
+		req.Header.Set(apiKeyHeader, apiKey)
+	}
+
+	// Deployments enforcing CSRF protection reject state-changing session requests
+	// without the token issued at login.
+	if csrf != "" && isMutatingMethod(method) {
+		req.Header.Set(csrfHeader, csrf)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	if c.gzip {
+		req.Header.Set("Accept-Encoding", "gzip")
 	}
+	if c.basicAuth != nil {
+		password, _ := c.basicAuth.Password()
+		req.SetBasicAuth(c.basicAuth.Username(), password)
+	}
+
+	requestID := newRequestID()
+	req.Header.Set(requestIDHeader, requestID)
 
+	start := time.Now()
 	res, err := c.http.Do(req)
+	if isMutatingMethod(method) {
+		c.invalidateCache(ctx, path)
+		c.flights.forget()
+	}
+
+	info := RequestInfo{BaseURL: c.baseURL, Method: method, Path: path, RequestID: requestID, Duration: time.Since(start), Err: err}
+	if res != nil {
+		info.StatusCode = res.StatusCode
+	}
+	c.observeDuration(info)
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request %s %s: %w", method, path, err)
+		return nil, &RequestError{Method: method, Path: path, RequestID: requestID, Err: err}
 	}
 
+	// Custom transports do not always link the response to its request, which the
+	// API errors rely on for request metadata.
+	if res.Request == nil {
+		res.Request = req
+	}
+
+	if sid := requestSessionID(req); sid != "" && res.StatusCode != http.StatusUnauthorized {
+		c.touchSession(sid)
+	}
+
+	if err := decompressResponse(res); err != nil {
+		res.Body.Close()
+		return nil, &RequestError{Method: method, Path: path, RequestID: requestID, Err: err}
+	}
+
+	// Throttling errors are read from the decompressed body.
+	c.limits.observe(res)
+
 	return res, nil
 }
 
+// requestSessionID returns the session ID req was sent with, from either transport.
+func requestSessionID(req *http.Request) string {
+	if sid := req.Header.Get(authHeader); sid != "" {
+		return sid
+	}
+
+	if cookie, err := req.Cookie(sessionCookieName); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// isMutatingMethod reports whether requests with method change server state.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// newRequestID returns a random identifier used to correlate a request with errors and
+// Pi-hole's webserver logs.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
 func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
 	return c.request(ctx, "GET", path, nil)
 }
@@ -0,0 +1,112 @@
+package pihole
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option overrides a setting of a client cloned with Client.With.
+type Option func(*Client)
+
+// WithBaseURL points the clone at another Pi-hole instance. The session of the
+// original client is not carried over. The URL is normalized and validated like
+// Config.BaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		normalized, _ := splitBaseURL(baseURL)
+		if problem := baseURLProblem(baseURL, normalized); problem != "" {
+			c.optionProblems = append(c.optionProblems, ConfigProblem{Field: "BaseURL", Message: problem})
+			return
+		}
+
+		c.baseURL = normalized
+		c.auth = auth{}
+	}
+}
+
+// WithPassword makes the clone log in with another password.
+func WithPassword(password string) Option {
+	return func(c *Client) {
+		c.password = password
+		c.auth = auth{}
+	}
+}
+
+// WithAPIToken makes the clone authenticate with another application password.
+// An empty token removes API token authentication from the clone.
+func WithAPIToken(token string) Option {
+	return func(c *Client) {
+		c.apiKey = token
+		c.auth = auth{}
+		c.headers.Del(apiKeyHeader)
+	}
+}
+
+// WithSessionID makes the clone use an existing session.
+func WithSessionID(sid string) Option {
+	return func(c *Client) {
+		c.auth = auth{sid: sid}
+	}
+}
+
+// WithTimeout sets the overall timeout of the clone's requests, keeping the
+// original transport and its connection pool.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		httpClient := *c.http
+		httpClient.Timeout = timeout
+		c.http = &httpClient
+	}
+}
+
+// With returns a shallow clone of the client with the given options applied. The
+// clone shares the HTTP transport, so fleet managers can talk to many instances
+// with mostly identical settings without opening a connection pool per instance.
+// If an option is invalid, such as a malformed WithBaseURL, it returns a
+// *ConfigValidationError listing every invalid option.
+func (c *Client) With(opts ...Option) (*Client, error) {
+	c.sessionLock.RLock()
+	session := c.auth
+	apiKey := c.apiKey
+	c.sessionLock.RUnlock()
+
+	clone := &Client{
+		baseURL:         c.baseURL,
+		apiPath:         c.apiPath,
+		password:        c.password,
+		headers:         c.headers.Clone(),
+		http:            c.http,
+		auth:            session,
+		publicEndpoints: c.publicEndpoints,
+		apiKey:          apiKey,
+		gzip:            c.gzip,
+		basicAuth:       c.basicAuth,
+		lenientParsing:  c.lenientParsing,
+		codec:           c.codec,
+		refreshMargin:   c.refreshMargin,
+		sessionCookie:   c.sessionCookie,
+		retry:           c.retry,
+		auditSink:       c.auditSink,
+		hooks:           c.hooks.clone(),
+		cache:           c.cache,
+		cacheTTL:        c.cacheTTL,
+		cacheSecret:     c.cacheSecret,
+		slowThreshold:   c.slowThreshold,
+	}
+	clone.gzipRequestsRejected.Store(c.gzipRequestsRejected.Load())
+	if clone.headers == nil {
+		clone.headers = make(http.Header)
+	}
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	if problems := clone.optionProblems; len(problems) > 0 {
+		return nil, &ConfigValidationError{Problems: problems}
+	}
+
+	clone.initServices()
+
+	return clone, nil
+}
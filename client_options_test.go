@@ -0,0 +1,70 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithOverridesSettings(t *testing.T) {
+	isUnit(t)
+
+	var hosts, apiKeys, sids []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		apiKeys = append(apiKeys, req.Header.Get(apiKeyHeader))
+		sids = append(sids, req.Header.Get(authHeader))
+		return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+	})
+
+	original, err := New(Config{
+		BaseURL:    "http://pi-one.test/",
+		SessionID:  "one",
+		HttpClient: &http.Client{Transport: transport},
+	})
+	require.NoError(t, err)
+
+	clone, err := original.With(WithBaseURL("http://pi-two.test/"), WithAPIToken("token"), WithTimeout(5*time.Second))
+	require.NoError(t, err)
+
+	_, err = clone.Messages.Count(context.Background())
+	require.NoError(t, err)
+	_, err = original.Messages.Count(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"pi-two.test", "pi-one.test"}, hosts)
+	assert.Equal(t, []string{"token", ""}, apiKeys)
+	assert.Equal(t, []string{"", "one"}, sids)
+
+	assert.Equal(t, 5*time.Second, clone.http.Timeout)
+	assert.Zero(t, original.http.Timeout)
+
+	same, err := original.With()
+	require.NoError(t, err)
+	assert.Equal(t, "one", same.auth.sid)
+	assert.NotSame(t, original.Messages, same.Messages)
+}
+
+func TestClient_WithRejectsInvalidBaseURL(t *testing.T) {
+	original, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		return newHTTPResponse(http.StatusOK, `{}`), nil
+	})
+	require.NoError(t, err)
+
+	_, err = original.With(WithBaseURL("pi-two.test"))
+	var validationErr *ConfigValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ErrorIs(t, err, ErrClientValidation)
+	assert.Equal(t, "BaseURL", validationErr.Problems[0].Field)
+
+	_, err = original.With(WithBaseURL("ftp://pi-two.test"))
+	assert.ErrorAs(t, err, &validationErr)
+
+	clone, err := original.With(WithBaseURL("http://pi-two.test/api"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://pi-two.test", clone.baseURL)
+}
@@ -0,0 +1,141 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PauseGroupName is the group paused clients are moved into. It must not have any
+// adlists or domains assigned, so that its members are not filtered.
+const PauseGroupName = "blocking-paused"
+
+var ErrClientPaused = errors.New("client blocking already paused")
+
+// ClientPause is a running pause of blocking for one client. The client's groups
+// are restored when the pause expires or Resume is called. The restore runs in this
+// process; if it exits first, the client stays in PauseGroupName.
+type ClientPause struct {
+	Client string
+	Until  time.Time
+
+	resume func(ctx context.Context) error
+	timer  *time.Timer
+	once   sync.Once
+	done   chan struct{}
+	err    error
+}
+
+// Resume ends the pause now and restores the client's groups. Later calls, and a
+// pause that has already expired, return the result of the first restore.
+func (p *ClientPause) Resume(ctx context.Context) error {
+	p.timer.Stop()
+	p.restore(ctx)
+
+	return p.err
+}
+
+func (p *ClientPause) restore(ctx context.Context) {
+	p.once.Do(func() {
+		p.err = p.resume(ctx)
+		close(p.done)
+	})
+}
+
+// Done is closed once the client's groups have been restored.
+func (p *ClientPause) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error restoring the client's groups, once Done is closed.
+func (p *ClientPause) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// PauseClientBlocking stops filtering for client, an IP address or any other client
+// identifier Pi-hole accepts, for duration. The client is moved into the
+// PauseGroupName group, which is created if needed, and its previous groups are
+// restored afterwards. A client without an entry gets a temporary one that is
+// deleted again.
+func (c *Client) PauseClientBlocking(ctx context.Context, client string, duration time.Duration) (*ClientPause, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("pause duration must be positive, got %s", duration)
+	}
+
+	group, err := c.pauseGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paused := []int{int(group.ID)}
+
+	var resume func(ctx context.Context) error
+
+	existing, err := c.Clients.Get(ctx, client)
+	switch {
+	case errors.Is(err, ErrorClientNotFound):
+		if _, err := c.Clients.Create(ctx, ManagedClientEntry{Client: client, Groups: paused}); err != nil {
+			return nil, fmt.Errorf("failed to pause blocking for %s: %w", client, err)
+		}
+
+		resume = func(ctx context.Context) error {
+			return c.Clients.Delete(ctx, client)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch client %s: %w", client, err)
+	default:
+		if slices.Equal(existing.Groups, paused) {
+			return nil, fmt.Errorf("%w: %s", ErrClientPaused, client)
+		}
+
+		if _, err := c.Clients.SetGroups(ctx, client, paused); err != nil {
+			return nil, fmt.Errorf("failed to pause blocking for %s: %w", client, err)
+		}
+
+		groups := existing.Groups
+		resume = func(ctx context.Context) error {
+			_, err := c.Clients.SetGroups(ctx, client, groups)
+			return err
+		}
+	}
+
+	pause := &ClientPause{
+		Client: client,
+		Until:  time.Now().Add(duration),
+		resume: resume,
+		done:   make(chan struct{}),
+	}
+
+	// The restore must not be cancelled along with the context of the pausing call.
+	restoreCtx := context.WithoutCancel(ctx)
+	pause.timer = time.AfterFunc(duration, func() {
+		pause.restore(restoreCtx)
+	})
+
+	return pause, nil
+}
+
+// pauseGroup returns the group paused clients are moved into, creating it if needed.
+func (c *Client) pauseGroup(ctx context.Context) (*Group, error) {
+	group, err := c.Groups.Get(ctx, PauseGroupName)
+	if err == nil {
+		return group, nil
+	}
+	if !errors.Is(err, ErrorGroupNotFound) {
+		return nil, err
+	}
+
+	group, err = c.Groups.Create(ctx, GroupEntry{Name: PauseGroupName, Comment: "Clients with blocking paused"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group %s: %w", PauseGroupName, err)
+	}
+
+	return group, nil
+}
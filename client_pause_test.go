@@ -0,0 +1,118 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPauseTestClient stubs the groups and clients endpoints backed by clientGroups,
+// which maps client identifiers to their group IDs.
+func newPauseTestClient(t *testing.T, clientGroups map[string][]int, pauseGroup bool) (*Client, *sync.Mutex) {
+	t.Helper()
+
+	var mu sync.Mutex
+	groups := `{"id":0,"name":"Default","enabled":true}`
+	if pauseGroup {
+		groups += `,{"id":7,"name":"` + PauseGroupName + `","enabled":true}`
+	}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		name := strings.TrimPrefix(req.URL.Path, "/api/clients/")
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[`+groups+`]}`), nil
+		case req.Method == http.MethodPost && req.URL.Path == "/api/groups":
+			groups += `,{"id":7,"name":"` + PauseGroupName + `","enabled":true}`
+			return newHTTPResponse(http.StatusCreated, `{"groups":[],"processed":{"success":[],"errors":[]}}`), nil
+		case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/api/clients/"):
+			ids, ok := clientGroups[name]
+			if !ok {
+				return newHTTPResponse(http.StatusOK, `{"clients":[]}`), nil
+			}
+			b, err := json.Marshal(managedClientListResponse{Clients: []managedClientResponse{{Client: name, Groups: ids}}})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodPost && req.URL.Path == "/api/clients":
+			var body managedClientRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			clientGroups[body.Client] = body.Groups
+			return newHTTPResponse(http.StatusCreated, `{"clients":[],"processed":{"success":[],"errors":[]}}`), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/api/clients/"):
+			var body managedClientRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			clientGroups[name] = body.Groups
+			return newHTTPResponse(http.StatusOK, `{}`), nil
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/clients/"):
+			delete(clientGroups, name)
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, fmt.Sprintf(`unexpected %s %s`, req.Method, req.URL.Path)), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client, &mu
+}
+
+func TestPauseClientBlocking_RestoresGroupsWhenExpired(t *testing.T) {
+	isUnit(t)
+
+	clientGroups := map[string][]int{"10.0.0.5": {0, 3}}
+	client, mu := newPauseTestClient(t, clientGroups, true)
+
+	pause, err := client.PauseClientBlocking(context.Background(), "10.0.0.5", 20*time.Millisecond)
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, []int{7}, clientGroups["10.0.0.5"])
+	mu.Unlock()
+
+	select {
+	case <-pause.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pause did not expire")
+	}
+	require.NoError(t, pause.Err())
+
+	mu.Lock()
+	assert.Equal(t, []int{0, 3}, clientGroups["10.0.0.5"])
+	mu.Unlock()
+}
+
+func TestPauseClientBlocking_TemporaryClientEntry(t *testing.T) {
+	isUnit(t)
+
+	clientGroups := map[string][]int{}
+	client, mu := newPauseTestClient(t, clientGroups, false)
+
+	pause, err := client.PauseClientBlocking(context.Background(), "10.0.0.9", time.Hour)
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, []int{7}, clientGroups["10.0.0.9"])
+	mu.Unlock()
+
+	_, err = client.PauseClientBlocking(context.Background(), "10.0.0.9", time.Hour)
+	assert.ErrorIs(t, err, ErrClientPaused)
+
+	require.NoError(t, pause.Resume(context.Background()))
+	require.NoError(t, pause.Resume(context.Background()))
+
+	mu.Lock()
+	assert.NotContains(t, clientGroups, "10.0.0.9")
+	mu.Unlock()
+}
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +27,60 @@ func TestClientValidation(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("error on invalid base URL", func(t *testing.T) {
+		isUnit(t)
+		t.Parallel()
+
+		for baseURL, want := range map[string]string{
+			"":                       "BaseURL: is required",
+			"pi.hole":                "must include a scheme and host",
+			"localhost:8080":         "must include a scheme and host",
+			"ftp://pi.hole":          "must use http or https",
+			"http://pi.hole/?x=1":    "must not include a query",
+			"http://pi.hole#admin":   "must not include a query or fragment",
+			"http://pi hole.lan/api": "BaseURL: invalid URL",
+		} {
+			_, err := New(Config{BaseURL: baseURL, Password: "test"})
+			assert.ErrorIs(t, err, ErrClientValidation, baseURL)
+			assert.ErrorContains(t, err, want, baseURL)
+		}
+	})
+
+	t.Run("reports every problem at once", func(t *testing.T) {
+		isUnit(t)
+		t.Parallel()
+
+		_, err := New(Config{
+			BaseURL:              "pi.hole",
+			APIToken:             "token",
+			APIKey:               "other",
+			SessionTransport:     "carrier-pigeon",
+			CacheTTL:             -time.Second,
+			SlowRequestThreshold: -time.Second,
+			Transport:            TransportConfig{Protocol: "spdy", ResolveTo: "pi.hole"},
+		})
+		require.ErrorIs(t, err, ErrClientValidation)
+
+		var validationErr *ConfigValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		var fields []string
+		for _, problem := range validationErr.Problems {
+			fields = append(fields, problem.Field)
+		}
+		assert.Equal(t, []string{"BaseURL", "APIKey", "Transport.Protocol", "Transport.ResolveTo", "SessionTransport", "CacheTTL", "SlowRequestThreshold"}, fields)
+	})
+
+	t.Run("normalizes base URL", func(t *testing.T) {
+		isUnit(t)
+		t.Parallel()
+
+		client, err := New(Config{BaseURL: " https://pi.hole/api/ ", Password: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://pi.hole", client.baseURL)
+		assert.Equal(t, "/api", client.apiPath)
+	})
 }
 
 func TestClientUsesAPIKey(t *testing.T) {
@@ -110,3 +166,96 @@ func cleanupTestClient(c *Client) {
 		fmt.Printf("failed to clean up client after acceptance test: %s\n", err)
 	}
 }
+
+func TestClientTransportConfig(t *testing.T) {
+	isUnit(t)
+
+	client, err := New(Config{
+		BaseURL: "http://pi.test",
+		Transport: TransportConfig{
+			MaxIdleConnsPerHost: 64,
+			IdleConnTimeout:     30 * time.Second,
+			DisableKeepAlives:   true,
+		},
+	})
+	require.NoError(t, err)
+
+	rt, ok := client.http.Transport.(*retryablehttp.RoundTripper)
+	require.True(t, ok)
+	transport, ok := rt.Client.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	assert.True(t, transport.DisableKeepAlives)
+
+	_, err = New(Config{
+		BaseURL:    "http://pi.test",
+		HttpClient: &http.Client{},
+		Transport:  TransportConfig{MaxIdleConnsPerHost: 64},
+	})
+	assert.ErrorIs(t, err, ErrClientValidation)
+}
+
+func TestClientAPIPath(t *testing.T) {
+	isUnit(t)
+
+	tcs := []struct {
+		name     string
+		baseURL  string
+		apiPath  string
+		wantPath string
+	}{
+		{name: "default", baseURL: "http://pi.test/", wantPath: "/api/info/messages/count"},
+		{name: "prefix", baseURL: "http://router.test/pihole", wantPath: "/pihole/api/info/messages/count"},
+		{name: "prefix with api", baseURL: "http://router.test/pihole/api/", wantPath: "/pihole/api/info/messages/count"},
+		{name: "custom api path", baseURL: "http://router.test/pihole", apiPath: "backend/", wantPath: "/pihole/backend/info/messages/count"},
+		{name: "api at root", baseURL: "http://router.test/pihole-api", apiPath: "/", wantPath: "/pihole-api/info/messages/count"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPath string
+			httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				gotPath = req.URL.Path
+				return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+			})}
+
+			client, err := New(Config{
+				BaseURL:    tc.baseURL,
+				APIPath:    tc.apiPath,
+				SessionID:  "test",
+				HttpClient: httpClient,
+			})
+			require.NoError(t, err)
+
+			_, err = client.Messages.Count(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPath, gotPath)
+		})
+	}
+}
+
+func TestClientBasicAuth(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		user, password, ok := req.BasicAuth()
+		if !ok || user != "proxy" || password != "secret" {
+			return newHTTPResponse(http.StatusUnauthorized, ``), nil
+		}
+		assert.Equal(t, "test", req.Header.Get(authHeader))
+		return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:           "http://pi.test",
+		SessionID:         "test",
+		HttpClient:        httpClient,
+		BasicAuthUser:     "proxy",
+		BasicAuthPassword: "secret",
+	})
+	require.NoError(t, err)
+
+	_, err = client.Messages.Count(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,293 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+type Clients interface {
+	// List all clients with a group assignment.
+	List(ctx context.Context) (ManagedClientList, error)
+
+	// Get a client by its identifier.
+	Get(ctx context.Context, client string) (*ManagedClient, error)
+
+	// Create a client entry, assigning it to groups.
+	Create(ctx context.Context, entry ManagedClientEntry) (*ManagedClient, error)
+
+	// SetGroups replaces the groups a client is assigned to.
+	SetGroups(ctx context.Context, client string, groups []int) (*ManagedClient, error)
+
+	// Delete a client entry. The client falls back to the Default group.
+	Delete(ctx context.Context, client string) error
+}
+
+var (
+	ErrorClientNotFound = newNotFoundError("client not found")
+)
+
+type clients struct {
+	client *Client
+}
+
+// ManagedClient is a client Pi-hole assigns to groups. Client identifies it by IP
+// address, subnet, MAC address, hostname or interface.
+type ManagedClient struct {
+	ID           int64
+	Client       string
+	Name         string
+	Comment      string
+	Groups       []int
+	DateAdded    time.Time
+	DateModified time.Time
+}
+
+type ManagedClientList []ManagedClient
+
+// ManagedClientEntry describes a client to be created.
+type ManagedClientEntry struct {
+	Client  string
+	Comment string
+	Groups  []int
+}
+
+type managedClientResponse struct {
+	ID           int64  `json:"id"`
+	Client       string `json:"client"`
+	Name         string `json:"name"`
+	Comment      string `json:"comment"`
+	Groups       []int  `json:"groups"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type managedClientListResponse struct {
+	Clients   []managedClientResponse  `json:"clients"`
+	Processed *domainProcessedResponse `json:"processed"`
+}
+
+type managedClientRequest struct {
+	Client  string `json:"client,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Groups  []int  `json:"groups"`
+}
+
+func (res managedClientResponse) toManagedClient() ManagedClient {
+	return ManagedClient{
+		ID:           res.ID,
+		Client:       res.Client,
+		Name:         res.Name,
+		Comment:      res.Comment,
+		Groups:       res.Groups,
+		DateAdded:    time.Unix(res.DateAdded, 0),
+		DateModified: time.Unix(res.DateModified, 0),
+	}
+}
+
+func (res managedClientListResponse) toManagedClientList() ManagedClientList {
+	list := make(ManagedClientList, 0, len(res.Clients))
+	for _, entry := range res.Clients {
+		list = append(list, entry.toManagedClient())
+	}
+
+	return list
+}
+
+// List returns all clients
+func (c clients) List(ctx context.Context) (ManagedClientList, error) {
+	return c.list(ctx, "/api/clients")
+}
+
+// Get returns a client by its identifier
+func (c clients) Get(ctx context.Context, client string) (*ManagedClient, error) {
+	list, err := c.list(ctx, clientPath(client))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range list {
+		if entry.Client == client {
+			return &entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrorClientNotFound, client)
+}
+
+func (c clients) list(ctx context.Context, path string) (ManagedClientList, error) {
+	res, err := c.client.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ManagedClientList{}, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newClientAPIError(res, b)
+	}
+
+	var resList managedClientListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse client list body: %w", err)
+	}
+
+	return resList.toManagedClientList(), nil
+}
+
+// Create creates a client entry
+func (c clients) Create(ctx context.Context, entry ManagedClientEntry) (created *ManagedClient, err error) {
+	defer func() {
+		c.client.afterMutation(ctx, Mutation{Operation: AuditClientCreate, Target: entry.Client, After: auditValue(created)}, err)
+	}()
+
+	if err := c.client.beforeMutation(ctx, Mutation{Operation: AuditClientCreate, Target: entry.Client, After: entry}); err != nil {
+		return nil, err
+	}
+
+	res, done, err := c.client.mutate(ctx, http.MethodPost, "/api/clients", managedClientRequest{
+		Client:  entry.Client,
+		Comment: entry.Comment,
+		Groups:  clientGroups(entry.Groups),
+	}, func(ctx context.Context) (bool, error) {
+		current, err := c.lookup(ctx, entry.Client)
+		return current != nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return c.Get(ctx, entry.Client)
+	}
+
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusCreated {
+		return nil, newClientAPIError(res, b)
+	}
+
+	var resList managedClientListResponse
+	if err := json.Unmarshal(b, &resList); err != nil {
+		return nil, fmt.Errorf("failed to parse client create body: %w", err)
+	}
+
+	if resList.Processed != nil {
+		for _, item := range resList.Processed.Errors {
+			return nil, fmt.Errorf("failed to create client %s: %s", item.Item, item.Error)
+		}
+	}
+
+	return c.Get(ctx, entry.Client)
+}
+
+// SetGroups replaces the groups of a client, keeping its comment
+func (c clients) SetGroups(ctx context.Context, client string, groups []int) (updated *ManagedClient, err error) {
+	existing, err := c.Get(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		c.client.afterMutation(ctx, Mutation{Operation: AuditClientUpdate, Target: client, Before: *existing, After: auditValue(updated)}, err)
+	}()
+
+	proposed := *existing
+	proposed.Groups = groups
+	if err := c.client.beforeMutation(ctx, Mutation{Operation: AuditClientUpdate, Target: client, Before: *existing, After: proposed}); err != nil {
+		return nil, err
+	}
+
+	res, done, err := c.client.mutate(ctx, http.MethodPut, clientPath(client), managedClientRequest{
+		Comment: existing.Comment,
+		Groups:  clientGroups(groups),
+	}, func(ctx context.Context) (bool, error) {
+		current, err := c.lookup(ctx, client)
+		return current != nil && slices.Equal(current.Groups, groups), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return c.Get(ctx, client)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newClientAPIError(res, b)
+	}
+
+	return c.Get(ctx, client)
+}
+
+// Delete deletes a client entry
+func (c clients) Delete(ctx context.Context, client string) (err error) {
+	m := Mutation{Operation: AuditClientDelete, Target: client}
+	defer func() {
+		c.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := c.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	res, done, err := c.client.mutate(ctx, http.MethodDelete, clientPath(client), nil, func(ctx context.Context) (bool, error) {
+		current, err := c.lookup(ctx, client)
+		return current == nil, err
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrorClientNotFound, client)
+	}
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newClientAPIError(res, b)
+	}
+
+	return nil
+}
+
+// lookup returns the entry for client, or nil if there is none.
+func (c clients) lookup(ctx context.Context, client string) (*ManagedClient, error) {
+	entry, err := c.Get(ctx, client)
+	if errors.Is(err, ErrorClientNotFound) {
+		return nil, nil
+	}
+
+	return entry, err
+}
+
+func clientPath(client string) string {
+	return "/api/clients/" + url.PathEscape(client)
+}
+
+// clientGroups makes sure an empty assignment is sent as [] rather than null.
+func clientGroups(groups []int) []int {
+	if groups == nil {
+		return []int{}
+	}
+
+	return groups
+}
@@ -0,0 +1,365 @@
+// Command pihole-sync keeps the local DNS and CNAME records, groups and allow/deny
+// domains of one or more replica Pi-hole instances in line with a manifest file or
+// a primary instance. It applies the desired state every interval, or once with
+// -once: missing entries are created and changed ones updated. Entries the desired
+// state does not list are deleted only with -prune.
+//
+// Every flag can also be set through the environment variable shown in its usage,
+// with flags taking precedence:
+//
+//	pihole-sync -manifest pihole.yaml -replica http://pi2.lan -password-file /run/secrets/pihole
+//	pihole-sync -primary http://pi1.lan -replica http://pi2.lan -replica http://pi3.lan -dry-run -once
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/awaybreaktoday/lib-pihole-go/manifest"
+)
+
+const defaultInterval = 5 * time.Minute
+
+// errDryRun vetoes every mutation of a dry run.
+var errDryRun = errors.New("dry run")
+
+type options struct {
+	manifestPath        string
+	primary             string
+	primaryPassword     string
+	primaryPasswordFile string
+	replicas            []string
+	password            string
+	passwordFile        string
+	interval            time.Duration
+	once                bool
+	dryRun              bool
+	prune               bool
+	logFormat           string
+}
+
+// stringList is a flag that can be repeated or given as a comma-separated list.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+
+	return nil
+}
+
+// parseOptions reads the flags in args, falling back to the environment for the
+// ones not given. Usage and flag errors are written to output.
+func parseOptions(args []string, getenv func(string) string, output io.Writer) (*options, error) {
+	opts := &options{interval: defaultInterval}
+	var replicas stringList
+
+	fs := flag.NewFlagSet("pihole-sync", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintln(output, "usage: pihole-sync (-manifest file | -primary url) -replica url [flags]")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.manifestPath, "manifest", "", "manifest file with the desired state ($PIHOLE_SYNC_MANIFEST)")
+	fs.StringVar(&opts.primary, "primary", "", "URL of the instance to copy the desired state from ($PIHOLE_SYNC_PRIMARY)")
+	fs.StringVar(&opts.primaryPassword, "primary-password", "", "password of the primary ($PIHOLE_SYNC_PRIMARY_PASSWORD)")
+	fs.StringVar(&opts.primaryPasswordFile, "primary-password-file", "", "file holding the password of the primary ($PIHOLE_SYNC_PRIMARY_PASSWORD_FILE)")
+	fs.Var(&replicas, "replica", "URL of a replica, repeatable or comma-separated ($PIHOLE_SYNC_REPLICAS)")
+	fs.StringVar(&opts.password, "password", "", "password of the replicas ($PIHOLE_SYNC_PASSWORD)")
+	fs.StringVar(&opts.passwordFile, "password-file", "", "file holding the password of the replicas ($PIHOLE_SYNC_PASSWORD_FILE)")
+	fs.DurationVar(&opts.interval, "interval", defaultInterval, "time between syncs ($PIHOLE_SYNC_INTERVAL)")
+	fs.BoolVar(&opts.once, "once", false, "sync once and exit, with status 1 if anything failed ($PIHOLE_SYNC_ONCE)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "log the changes without making them ($PIHOLE_SYNC_DRY_RUN)")
+	fs.BoolVar(&opts.prune, "prune", false, "delete the records, domains and groups the desired state does not list ($PIHOLE_SYNC_PRUNE)")
+	fs.StringVar(&opts.logFormat, "log-format", "text", "log format, text or json ($PIHOLE_SYNC_LOG_FORMAT)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for name, env := range map[string]string{
+		"manifest":              "PIHOLE_SYNC_MANIFEST",
+		"primary":               "PIHOLE_SYNC_PRIMARY",
+		"primary-password":      "PIHOLE_SYNC_PRIMARY_PASSWORD",
+		"primary-password-file": "PIHOLE_SYNC_PRIMARY_PASSWORD_FILE",
+		"replica":               "PIHOLE_SYNC_REPLICAS",
+		"password":              "PIHOLE_SYNC_PASSWORD",
+		"password-file":         "PIHOLE_SYNC_PASSWORD_FILE",
+		"interval":              "PIHOLE_SYNC_INTERVAL",
+		"once":                  "PIHOLE_SYNC_ONCE",
+		"dry-run":               "PIHOLE_SYNC_DRY_RUN",
+		"prune":                 "PIHOLE_SYNC_PRUNE",
+		"log-format":            "PIHOLE_SYNC_LOG_FORMAT",
+	} {
+		if value := getenv(env); value != "" && !set[name] {
+			if err := fs.Set(name, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", env, err)
+			}
+		}
+	}
+
+	opts.replicas = replicas
+
+	if (opts.manifestPath == "") == (opts.primary == "") {
+		return nil, errors.New("exactly one of -manifest and -primary is required")
+	}
+	if len(opts.replicas) == 0 {
+		return nil, errors.New("at least one -replica is required")
+	}
+	if opts.interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", opts.interval)
+	}
+	if opts.logFormat != "text" && opts.logFormat != "json" {
+		return nil, fmt.Errorf("invalid log format %q", opts.logFormat)
+	}
+
+	return opts, nil
+}
+
+// replica is an instance the desired state is applied to.
+type replica struct {
+	name   string
+	client *pihole.Client
+}
+
+type syncer struct {
+	source   func(ctx context.Context) (*manifest.Manifest, error)
+	replicas []replica
+	dryRun   bool
+	prune    bool
+	log      *slog.Logger
+}
+
+func newSyncer(opts *options, log *slog.Logger) (*syncer, error) {
+	s := &syncer{dryRun: opts.dryRun, prune: opts.prune, log: log}
+
+	if opts.manifestPath != "" {
+		path := opts.manifestPath
+		s.source = func(context.Context) (*manifest.Manifest, error) {
+			return manifest.LoadFile(path)
+		}
+	} else {
+		primary, err := pihole.New(pihole.Config{BaseURL: opts.primary, Password: opts.primaryPassword, PasswordFile: opts.primaryPasswordFile})
+		if err != nil {
+			return nil, fmt.Errorf("primary: %w", err)
+		}
+		s.source = func(ctx context.Context) (*manifest.Manifest, error) {
+			return exportManifest(ctx, primary)
+		}
+	}
+
+	for _, baseURL := range opts.replicas {
+		client, err := pihole.New(pihole.Config{BaseURL: baseURL, Password: opts.password, PasswordFile: opts.passwordFile})
+		if err != nil {
+			return nil, fmt.Errorf("replica %s: %w", baseURL, err)
+		}
+		if opts.dryRun {
+			client.OnBeforeMutation(func(context.Context, pihole.Mutation) error {
+				return errDryRun
+			})
+		}
+
+		s.replicas = append(s.replicas, replica{name: baseURL, client: client})
+	}
+
+	return s, nil
+}
+
+// exportManifest reads the records, groups and domains of the primary as a manifest.
+// Its settings are left out, as they often differ between instances on purpose.
+func exportManifest(ctx context.Context, primary *pihole.Client) (*manifest.Manifest, error) {
+	var buf bytes.Buffer
+	opts := pihole.ExportOptions{Records: true, Domains: true, Groups: true}
+	if err := primary.Config.Export(ctx, &buf, pihole.ExportJSON, opts); err != nil {
+		return nil, fmt.Errorf("failed to export primary: %w", err)
+	}
+
+	m, err := manifest.Load(&buf)
+	if err != nil {
+		return nil, err
+	}
+	m.Config = nil
+
+	return m, nil
+}
+
+// run syncs every interval until ctx is done.
+func (s *syncer) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = s.syncOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncOnce applies the desired state to every replica, logging each change. It
+// returns an error if the state could not be read or any replica failed.
+func (s *syncer) syncOnce(ctx context.Context) error {
+	m, err := s.source(ctx)
+	if err != nil {
+		s.log.Error("failed to read desired state", "err", err)
+		return err
+	}
+
+	var errs []error
+	for _, r := range s.replicas {
+		if err := s.apply(ctx, r, m); err != nil {
+			s.log.Error("sync failed", "replica", r.name, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *syncer) apply(ctx context.Context, r replica, m *manifest.Manifest) error {
+	log := s.log.With("replica", r.name)
+	start := time.Now()
+
+	if s.dryRun && len(m.Config) > 0 {
+		changes, err := plannedConfig(ctx, r.client, m.Config)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			log.Info("would change", "kind", "config", "name", change.Path)
+		}
+
+		// Config.Restore vetoed by the dry run would stop Apply.
+		withoutConfig := *m
+		withoutConfig.Config = nil
+		m = &withoutConfig
+	}
+
+	result, err := manifest.Apply(ctx, r.client, m, &manifest.ApplyOptions{Prune: s.prune})
+	if result == nil {
+		return err
+	}
+
+	var changed, unchanged, failed int
+	for _, change := range result.Changes {
+		switch {
+		case change.Action == manifest.ActionUnchanged:
+			unchanged++
+		case s.dryRun && errors.Is(change.Err, errDryRun):
+			changed++
+			log.Info("would change", "kind", change.Kind, "name", change.Name)
+		case change.Action == manifest.ActionFailed:
+			failed++
+			log.Warn("change failed", "kind", change.Kind, "name", change.Name, "err", change.Err)
+		default:
+			changed++
+			log.Info("changed", "kind", change.Kind, "name", change.Name, "action", change.Action)
+		}
+	}
+
+	log.Info("synced", "changed", changed, "unchanged", unchanged, "failed", failed, "dry_run", s.dryRun, "prune", s.prune, "duration", time.Since(start))
+
+	if failed == 0 && ctx.Err() == nil {
+		return nil
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	return err
+}
+
+// plannedConfig returns the settings Apply would patch on the instance, compared the
+// way manifest.Apply compares them.
+func plannedConfig(ctx context.Context, client *pihole.Client, config map[string]any) ([]pihole.ConfigChange, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var desired map[string]any
+	if err := json.Unmarshal(b, &desired); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	current, err := client.Config.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]pihole.ConfigChange, 0)
+	for _, change := range current.Diff(&pihole.ConfigSnapshot{Config: desired}) {
+		if change.After != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func newLogger(format string, w io.Writer) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, nil))
+	}
+
+	return slog.New(slog.NewTextHandler(w, nil))
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pihole-sync: %s\n", err)
+		os.Exit(2)
+	}
+
+	log := newLogger(opts.logFormat, os.Stderr)
+
+	s, err := newSyncer(opts, log)
+	if err != nil {
+		log.Error("invalid configuration", "err", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.once {
+		if err := s.syncOnce(ctx); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	log.Info("starting", "replicas", len(s.replicas), "interval", opts.interval, "dry_run", opts.dryRun, "prune", opts.prune)
+	s.run(ctx, opts.interval)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/awaybreaktoday/lib-pihole-go/manifest"
+	"github.com/awaybreaktoday/lib-pihole-go/piholetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	env := map[string]string{
+		"PIHOLE_SYNC_REPLICAS": "http://pi2.lan, http://pi3.lan",
+		"PIHOLE_SYNC_DRY_RUN":  "true",
+		"PIHOLE_SYNC_INTERVAL": "1m",
+		"PIHOLE_SYNC_PRUNE":    "true",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	opts, err := parseOptions([]string{"-manifest", "pihole.yaml", "-interval", "30s"}, getenv, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, "pihole.yaml", opts.manifestPath)
+	assert.Equal(t, []string{"http://pi2.lan", "http://pi3.lan"}, opts.replicas)
+	assert.True(t, opts.dryRun)
+	assert.True(t, opts.prune)
+	assert.Equal(t, 30*time.Second, opts.interval)
+
+	_, err = parseOptions([]string{"-manifest", "pihole.yaml", "-primary", "http://pi1.lan"}, getenv, io.Discard)
+	assert.ErrorContains(t, err, "exactly one of")
+
+	_, err = parseOptions([]string{"-manifest", "pihole.yaml"}, func(string) string { return "" }, io.Discard)
+	assert.ErrorContains(t, err, "-replica")
+
+	_, err = parseOptions([]string{"-manifest", "pihole.yaml", "-log-format", "xml"}, getenv, io.Discard)
+	assert.ErrorContains(t, err, "log format")
+}
+
+// newReplica serves the groups, hosts and CNAME records of an instance, recording
+// every change as "PUT <entry>" or "DELETE <entry>" in ops.
+func newReplica(t *testing.T, hosts *[]string, cnames *[]string, ops *[]string) *pihole.Client {
+	var mu sync.Mutex
+
+	arrays := map[string]*[]string{"hosts": hosts, "cnameRecords": cnames}
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/domains":
+			return piholetest.JSONResponse(http.StatusOK, `{"domains":[]}`), nil
+		}
+
+		for key, entries := range arrays {
+			path := "/api/config/dns/" + key
+			if req.Method == http.MethodGet && req.URL.Path == path {
+				b, _ := json.Marshal(*entries)
+				return piholetest.JSONResponse(http.StatusOK, `{"config":{"dns":{"`+key+`":`+string(b)+`}}}`), nil
+			}
+
+			escaped, ok := strings.CutPrefix(req.URL.EscapedPath(), path+"/")
+			if !ok {
+				continue
+			}
+			entry, err := url.PathUnescape(escaped)
+			require.NoError(t, err)
+			*ops = append(*ops, req.Method+" "+entry)
+
+			if req.Method == http.MethodPut {
+				*entries = append(*entries, entry)
+				return piholetest.Response(http.StatusCreated, ``), nil
+			}
+			*entries = slices.DeleteFunc(*entries, func(e string) bool { return e == entry })
+			return piholetest.Response(http.StatusNoContent, ``), nil
+		}
+
+		return piholetest.Response(http.StatusNotFound, ``), nil
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi2.lan", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestSyncOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pihole.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`version: 1
+dnsRecords:
+  - domain: nas.lan
+    ip: 10.0.0.5
+  - domain: printer.lan
+    ip: 10.0.0.6
+`), 0o600))
+
+	for _, dryRun := range []bool{true, false} {
+		hosts := []string{"10.0.0.5 nas.lan"}
+		var cnames, ops []string
+		client := newReplica(t, &hosts, &cnames, &ops)
+		if dryRun {
+			client.OnBeforeMutation(func(context.Context, pihole.Mutation) error { return errDryRun })
+		}
+
+		var logs bytes.Buffer
+		s := &syncer{
+			source:   func(context.Context) (*manifest.Manifest, error) { return manifest.LoadFile(path) },
+			replicas: []replica{{name: "http://pi2.lan", client: client}},
+			dryRun:   dryRun,
+			log:      slog.New(slog.NewJSONHandler(&logs, nil)),
+		}
+
+		require.NoError(t, s.syncOnce(context.Background()), logs.String())
+		assert.Contains(t, logs.String(), `"name":"printer.lan 10.0.0.6"`)
+		assert.Contains(t, logs.String(), `"changed":1,"unchanged":1,"failed":0`)
+
+		if dryRun {
+			assert.Empty(t, ops)
+			assert.Contains(t, logs.String(), `"msg":"would change"`)
+		} else {
+			assert.Equal(t, []string{"PUT 10.0.0.6 printer.lan"}, ops)
+		}
+	}
+}
+
+func TestSyncOnceConverges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pihole.yaml")
+	write := func(doc string) {
+		require.NoError(t, os.WriteFile(path, []byte("version: 1\n"+doc), 0o600))
+	}
+
+	hosts := []string{"10.0.0.5 nas.lan", "10.0.0.9 old.lan"}
+	var cnames, ops []string
+	client := newReplica(t, &hosts, &cnames, &ops)
+
+	var logs bytes.Buffer
+	s := &syncer{
+		source:   func(context.Context) (*manifest.Manifest, error) { return manifest.LoadFile(path) },
+		replicas: []replica{{name: "http://pi2.lan", client: client}},
+		log:      slog.New(slog.NewJSONHandler(&logs, nil)),
+	}
+
+	write("dnsRecords:\n  - {domain: nas.lan, ip: 10.0.0.5}\ncnameRecords:\n  - {domain: www.lan, target: web.lan}\n")
+	require.NoError(t, s.syncOnce(context.Background()), logs.String())
+	assert.Equal(t, []string{"www.lan,web.lan"}, cnames)
+
+	// A changed target replaces the entry instead of failing as a duplicate, and
+	// every later sync finds nothing to do.
+	write("dnsRecords:\n  - {domain: nas.lan, ip: 10.0.0.6, comment: moved}\ncnameRecords:\n  - {domain: www.lan, target: nas.lan}\n")
+	for i := 0; i < 2; i++ {
+		logs.Reset()
+		require.NoError(t, s.syncOnce(context.Background()), logs.String())
+		assert.Equal(t, []string{"www.lan,nas.lan"}, cnames)
+		assert.ElementsMatch(t, []string{"10.0.0.6 nas.lan # moved", "10.0.0.9 old.lan"}, hosts)
+	}
+	assert.Contains(t, logs.String(), `"changed":0,"unchanged":2,"failed":0`)
+
+	// Only -prune removes what the manifest no longer lists.
+	s.prune = true
+	require.NoError(t, s.syncOnce(context.Background()), logs.String())
+	assert.Equal(t, []string{"10.0.0.6 nas.lan # moved"}, hosts)
+	assert.Equal(t, "DELETE 10.0.0.9 old.lan", ops[len(ops)-1])
+}
@@ -0,0 +1,131 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// flightGroup coalesces concurrent GETs of the same path into one request, as
+// reconcilers often read the same endpoint from many goroutines at once.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	res  *sharedResponse
+	err  error
+}
+
+// sharedResponse is a buffered response handed to every caller of a coalesced GET.
+type sharedResponse struct {
+	status        string
+	statusCode    int
+	header        http.Header
+	body          []byte
+	contentLength int64
+	request       *http.Request
+}
+
+// do runs fn for key unless a call for key is already in flight, in which case it
+// waits for that call and returns its result. Waiting stops when ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*sharedResponse, error)) (*sharedResponse, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.res, true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.res, call.err = fn()
+
+	g.mu.Lock()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.res, false, call.err
+}
+
+// forget lets later calls start a new request rather than join those in flight,
+// whose responses may predate a mutation.
+func (g *flightGroup) forget() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	clear(g.calls)
+}
+
+// coalescedGet sends a GET, sharing the response with concurrent GETs of the same
+// path. Each caller gets its own copy of the body to decode, so it is only meant
+// for small JSON reads; archives and streamed arrays go through get.
+func (c *Client) coalescedGet(ctx context.Context, path string) (*http.Response, error) {
+	shared, joined, err := c.flights.do(ctx, path, func() (*sharedResponse, error) {
+		res, err := c.get(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, &RequestError{Method: http.MethodGet, Path: path, Err: err}
+		}
+
+		return &sharedResponse{
+			status:        res.Status,
+			statusCode:    res.StatusCode,
+			header:        res.Header,
+			body:          body,
+			contentLength: int64(len(body)),
+			request:       res.Request,
+		}, nil
+	})
+
+	// A caller joining a request whose own caller gave up sends its own.
+	if joined && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return c.get(ctx, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        shared.status,
+		StatusCode:    shared.statusCode,
+		Header:        shared.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(shared.body)),
+		ContentLength: shared.contentLength,
+		Request:       shared.request,
+	}, nil
+}
+
+// get sends a GET without coalescing, answering it from the cache where enabled.
+func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	if c.cache != nil {
+		if section, ok := cacheSection(path); ok {
+			return c.cachedGet(ctx, path, section)
+		}
+	}
+
+	return c.do(ctx, http.MethodGet, path, nil, "", "", -1)
+}
@@ -0,0 +1,133 @@
+package pihole
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescedGet(t *testing.T) {
+	isUnit(t)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		<-release
+		return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	const callers = 8
+	lists := make([]GroupList, callers)
+	var wg sync.WaitGroup
+	for i := range lists {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list, err := client.Groups.List(context.Background())
+			assert.NoError(t, err)
+			lists[i] = list
+		}()
+	}
+
+	// Let every caller join the request in flight before it completes.
+	require.Eventually(t, func() bool {
+		client.flights.mu.Lock()
+		defer client.flights.mu.Unlock()
+		return len(client.flights.calls) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+	for _, list := range lists {
+		require.Len(t, list, 1)
+		assert.Equal(t, "Default", list[0].Name)
+	}
+
+	// Callers decode their own copy.
+	lists[0][0].Name = "changed"
+	assert.Equal(t, "Default", lists[1][0].Name)
+
+	// Sequential calls are not coalesced.
+	_, err = client.Groups.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestCoalescedGet_JoinerOutlivesCanceledCaller(t *testing.T) {
+	isUnit(t)
+
+	var requests atomic.Int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if requests.Add(1) == 1 {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return newHTTPResponse(http.StatusOK, `{"version":{}}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := client.Get(ctx, "/api/info/version")
+		leaderDone <- err
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+
+	joined := make(chan *http.Response)
+	go func() {
+		res, err := client.Get(context.Background(), "/api/info/version")
+		assert.NoError(t, err)
+		joined <- res
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-leaderDone, context.Canceled)
+	res := <-joined
+	require.NotNil(t, res)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":{}}`, string(body))
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestFlightGroup_Forget(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go g.do(context.Background(), "/api/groups", func() (*sharedResponse, error) {
+		close(started)
+		<-release
+		return &sharedResponse{statusCode: http.StatusOK}, nil
+	})
+	<-started
+
+	// A mutation was sent while the first read is in flight.
+	g.forget()
+
+	_, joined, err := g.do(context.Background(), "/api/groups", func() (*sharedResponse, error) {
+		return &sharedResponse{statusCode: http.StatusOK}, nil
+	})
+	require.NoError(t, err)
+	assert.False(t, joined)
+	close(release)
+}
@@ -0,0 +1,34 @@
+package pihole
+
+import "strings"
+
+// Records carry machine-readable metadata as key=value tokens in their comment, next
+// to any free text, e.g. "web server managed-by=myapp".
+
+// commentTag returns the value of the key=value token for key in comment.
+func commentTag(comment string, key string) (string, bool) {
+	prefix := key + "="
+	for _, field := range strings.Fields(comment) {
+		if value, ok := strings.CutPrefix(field, prefix); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// setCommentTag returns comment with the token for key set to value, replacing an
+// existing token for key in place or appending a new one.
+func setCommentTag(comment string, key string, value string) string {
+	prefix := key + "="
+	fields := strings.Fields(comment)
+
+	for i, field := range fields {
+		if strings.HasPrefix(field, prefix) {
+			fields[i] = prefix + value
+			return strings.Join(fields, " ")
+		}
+	}
+
+	return strings.Join(append(fields, prefix+value), " ")
+}
@@ -0,0 +1,89 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CompareOptions tunes CompareConfig.
+type CompareOptions struct {
+	// IgnorePaths lists settings excluded from the comparison, such as settings
+	// expected to differ between the members of an HA pair. A pattern matches a
+	// dotted path exactly or any setting below it, and a * segment matches any
+	// single key, e.g. "dhcp" or "webserver.*.port".
+	IgnorePaths []string
+}
+
+// CompareConfig fetches the configuration of both instances and returns the settings
+// that differ, sorted by path. Before holds a's value and After b's. Masked secrets
+// are always equal and never reported.
+func CompareConfig(ctx context.Context, a *Client, b *Client, opts CompareOptions) ([]ConfigChange, error) {
+	var (
+		snapshotA, snapshotB *ConfigSnapshot
+		errA, errB           error
+		wg                   sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		snapshotA, errA = a.Config.Snapshot(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		snapshotB, errB = b.Config.Snapshot(ctx)
+	}()
+	wg.Wait()
+
+	if errA != nil {
+		errA = fmt.Errorf("failed to fetch config of %s: %w", a.baseURL, errA)
+	}
+	if errB != nil {
+		errB = fmt.Errorf("failed to fetch config of %s: %w", b.baseURL, errB)
+	}
+	if err := errors.Join(errA, errB); err != nil {
+		return nil, err
+	}
+
+	changes := make([]ConfigChange, 0)
+	for _, change := range snapshotA.Diff(snapshotB) {
+		if opts.ignored(change.Path) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func (opts CompareOptions) ignored(path string) bool {
+	for _, pattern := range opts.IgnorePaths {
+		if matchConfigPath(pattern, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchConfigPath reports whether path is pattern or lies below it, treating *
+// segments of pattern as wildcards.
+func matchConfigPath(pattern string, path string) bool {
+	patternKeys := strings.Split(pattern, ".")
+	pathKeys := strings.Split(path, ".")
+
+	if len(patternKeys) > len(pathKeys) {
+		return false
+	}
+
+	for i, key := range patternKeys {
+		if key != "*" && key != pathKeys[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,40 @@
+package pihole
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareConfig(t *testing.T) {
+	isUnit(t)
+
+	primary, _ := newConfigTestClient(t, map[string]any{
+		"dns":       map[string]any{"upstreams": []any{"1.1.1.1"}, "hostRecord": "pi-one.lan"},
+		"dhcp":      map[string]any{"active": true},
+		"webserver": map[string]any{"port": "80", "api": map[string]any{"password": maskedConfigValue}},
+	})
+	secondary, _ := newConfigTestClient(t, map[string]any{
+		"dns":       map[string]any{"upstreams": []any{"9.9.9.9"}, "hostRecord": "pi-two.lan"},
+		"dhcp":      map[string]any{"active": false},
+		"webserver": map[string]any{"port": "8080", "api": map[string]any{"password": maskedConfigValue}},
+	})
+
+	changes, err := CompareConfig(context.Background(), primary, secondary, CompareOptions{
+		IgnorePaths: []string{"dhcp", "dns.hostRecord", "*.port"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigChange{
+		{Path: "dns.upstreams", Before: []any{"1.1.1.1"}, After: []any{"9.9.9.9"}},
+	}, changes)
+}
+
+func TestMatchConfigPath(t *testing.T) {
+	assert.True(t, matchConfigPath("dns", "dns.upstreams"))
+	assert.True(t, matchConfigPath("dns.upstreams", "dns.upstreams"))
+	assert.True(t, matchConfigPath("*.port", "webserver.port"))
+	assert.False(t, matchConfigPath("dns.up", "dns.upstreams"))
+	assert.False(t, matchConfigPath("dns.upstreams.extra", "dns.upstreams"))
+}
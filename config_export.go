@@ -0,0 +1,200 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is the encoding of an exported configuration document.
+type ExportFormat string
+
+const (
+	ExportYAML ExportFormat = "yaml"
+	ExportJSON ExportFormat = "json"
+)
+
+// ExportOptions selects what Export includes besides the configuration.
+type ExportOptions struct {
+	// Records exports the local DNS and CNAME records as their own sections, which
+	// removes dns.hosts and dns.cnameRecords from the configuration section.
+	Records bool
+
+	// Domains exports the allow and deny list entries. The groups they reference are
+	// exported with them.
+	Domains bool
+
+	// Groups exports the groups other than Pi-hole's Default group.
+	Groups bool
+}
+
+// exportVersion is the schema version of exported documents, kept in step with
+// manifest.Version so that exports load as manifests.
+const exportVersion = 1
+
+// exportDocument mirrors the layout of manifest.Manifest.
+type exportDocument struct {
+	Version      int             `json:"version"`
+	Config       map[string]any  `json:"config,omitempty" yaml:"config,omitempty"`
+	Groups       []exportGroup   `json:"groups,omitempty" yaml:"groups,omitempty"`
+	DNSRecords   DNSRecordList   `json:"dnsRecords,omitempty" yaml:"dnsRecords,omitempty"`
+	CNAMERecords CNAMERecordList `json:"cnameRecords,omitempty" yaml:"cnameRecords,omitempty"`
+	Domains      []exportDomain  `json:"domains,omitempty" yaml:"domains,omitempty"`
+}
+
+type exportGroup struct {
+	Name     string `json:"name"`
+	Comment  string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Disabled bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+type exportDomain struct {
+	Domain   string     `json:"domain"`
+	Type     DomainType `json:"type"`
+	Kind     DomainKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Comment  string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Groups   []string   `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Disabled bool       `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Export writes the configuration and the sections selected by opts as a manifest
+// document
+func (c configAPI) Export(ctx context.Context, w io.Writer, format ExportFormat, opts ExportOptions) error {
+	if format != ExportYAML && format != ExportJSON {
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	doc := exportDocument{Version: exportVersion, Config: exportConfig(snapshot.Config, opts)}
+
+	if opts.Records {
+		set, err := c.client.LocalRecords.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch local records: %w", err)
+		}
+
+		set.DNS.Sort()
+		set.CNAME.Sort()
+		doc.DNSRecords = set.DNS
+		doc.CNAMERecords = set.CNAME
+	}
+
+	if opts.Domains || opts.Groups {
+		if err := c.exportLists(ctx, &doc, opts); err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	default:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+}
+
+// exportLists adds the groups and domains to doc, referencing groups by name.
+func (c configAPI) exportLists(ctx context.Context, doc *exportDocument, opts ExportOptions) error {
+	groups, err := c.client.Groups.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	names := make(map[int]string, len(groups))
+	for _, group := range groups {
+		names[int(group.ID)] = group.Name
+	}
+
+	referenced := make(map[string]bool)
+	if opts.Domains {
+		domains, err := c.client.Domains.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch domains: %w", err)
+		}
+
+		doc.Domains = make([]exportDomain, 0, len(domains))
+		for _, domain := range domains {
+			entry := exportDomain{
+				Domain:   domain.Domain,
+				Type:     domain.Type,
+				Comment:  domain.Comment,
+				Disabled: !domain.Enabled,
+			}
+			if domain.Kind != DomainKindExact {
+				entry.Kind = domain.Kind
+			}
+
+			// Entries only in the Default group leave Groups empty, which is what a
+			// manifest defaults to.
+			if len(domain.Groups) != 1 || domain.Groups[0] != 0 {
+				for _, id := range domain.Groups {
+					if name, ok := names[id]; ok {
+						entry.Groups = append(entry.Groups, name)
+						referenced[name] = true
+					}
+				}
+				sort.Strings(entry.Groups)
+			}
+
+			doc.Domains = append(doc.Domains, entry)
+		}
+
+		sort.Slice(doc.Domains, func(i, j int) bool {
+			a, b := doc.Domains[i], doc.Domains[j]
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			return a.Domain < b.Domain
+		})
+	}
+
+	for _, group := range groups {
+		if group.ID == 0 || !(opts.Groups || referenced[group.Name]) {
+			continue
+		}
+
+		doc.Groups = append(doc.Groups, exportGroup{Name: group.Name, Comment: group.Comment, Disabled: !group.Enabled})
+	}
+
+	sort.Slice(doc.Groups, func(i, j int) bool {
+		return doc.Groups[i].Name < doc.Groups[j].Name
+	})
+
+	return nil
+}
+
+// exportConfig returns a copy of tree without masked secrets, which cannot be
+// applied back, and without the record lists when they are exported separately.
+func exportConfig(tree map[string]any, opts ExportOptions) map[string]any {
+	config := make(map[string]any)
+	for path, value := range flattenConfig(tree) {
+		if value == maskedConfigValue {
+			continue
+		}
+		if opts.Records && (path == "dns.hosts" || path == "dns.cnameRecords") {
+			continue
+		}
+
+		setConfigPath(config, path, value)
+	}
+
+	return config
+}
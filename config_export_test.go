@@ -0,0 +1,124 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	hosts := []any{"10.0.0.2 web.lan", "10.0.0.1 nas.lan"}
+	cnames := []any{"www.lan,web.lan"}
+	tree := map[string]any{
+		"dns": map[string]any{
+			"upstreams":    []any{"1.1.1.1"},
+			"hosts":        hosts,
+			"cnameRecords": cnames,
+		},
+		"webserver": map[string]any{
+			"api": map[string]any{"password": maskedConfigValue},
+		},
+	}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config":
+			b, err := json.Marshal(configResponse{Config: tree})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			b, err := json.Marshal(map[string]any{"config": map[string]any{"dns": map[string]any{"hosts": hosts}}})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/cnameRecords":
+			b, err := json.Marshal(map[string]any{"config": map[string]any{"dns": map[string]any{"cnameRecords": cnames}}})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":3,"name":"kids","enabled":true},{"id":2,"name":"iot","enabled":false}]}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/domains":
+			return newHTTPResponse(http.StatusOK, `{"domains":[
+				{"id":2,"domain":"tracker.example","type":"deny","kind":"regex","groups":[0],"enabled":true},
+				{"id":1,"domain":"ads.example","type":"deny","kind":"exact","groups":[3,0],"enabled":true},
+				{"id":3,"domain":"good.example","type":"allow","kind":"exact","groups":[0],"enabled":false}
+			]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestConfigExport_YAML(t *testing.T) {
+	isUnit(t)
+
+	client := newExportTestClient(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, client.Config.Export(context.Background(), &buf, ExportYAML, ExportOptions{Records: true, Domains: true}))
+
+	assert.Equal(t, `version: 1
+config:
+  dns:
+    upstreams:
+      - 1.1.1.1
+groups:
+  - name: kids
+dnsRecords:
+  - ip: 10.0.0.1
+    domain: nas.lan
+  - ip: 10.0.0.2
+    domain: web.lan
+cnameRecords:
+  - domain: www.lan
+    target: web.lan
+domains:
+  - domain: good.example
+    type: allow
+    disabled: true
+  - domain: ads.example
+    type: deny
+    groups:
+      - Default
+      - kids
+  - domain: tracker.example
+    type: deny
+    kind: regex
+`, buf.String())
+}
+
+func TestConfigExport_JSONConfigOnly(t *testing.T) {
+	isUnit(t)
+
+	client := newExportTestClient(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, client.Config.Export(context.Background(), &buf, ExportJSON, ExportOptions{Groups: true}))
+
+	var doc exportDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, exportVersion, doc.Version)
+	assert.Contains(t, doc.Config["dns"], "hosts")
+	assert.NotContains(t, doc.Config, "webserver")
+	assert.Equal(t, []exportGroup{{Name: "iot", Disabled: true}, {Name: "kids"}}, doc.Groups)
+	assert.Empty(t, doc.DNSRecords)
+	assert.Empty(t, doc.Domains)
+}
+
+func TestConfigExport_UnsupportedFormat(t *testing.T) {
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test"})
+	require.NoError(t, err)
+
+	assert.Error(t, client.Config.Export(context.Background(), &bytes.Buffer{}, "toml", ExportOptions{}))
+}
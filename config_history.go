@@ -0,0 +1,143 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConfigHistory is a local change history of an instance's configuration, built by
+// comparing consecutive snapshots. It answers questions such as "what changed last
+// night" during incident review. It is safe for concurrent use.
+type ConfigHistory struct {
+	mu      sync.Mutex
+	last    *ConfigSnapshot
+	entries []ConfigHistoryEntry
+	limit   int
+	err     error
+}
+
+// ConfigHistoryEntry is the set of settings that changed between two snapshots.
+// Since is when the earlier snapshot was taken and Taken when the change was seen,
+// so the change happened somewhere in between.
+type ConfigHistoryEntry struct {
+	Since   time.Time      `json:"since"`
+	Taken   time.Time      `json:"taken"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// NewConfigHistory returns a history starting from the snapshot. It keeps at most
+// limit entries, dropping the oldest first; a limit of 0 keeps them all.
+func NewConfigHistory(snapshot *ConfigSnapshot, limit int) *ConfigHistory {
+	return &ConfigHistory{last: snapshot, limit: limit}
+}
+
+// History snapshots the configuration every interval until ctx is done and records
+// the settings that changed in the returned history, which keeps at most limit
+// entries
+func (c configAPI) History(ctx context.Context, interval time.Duration, limit int) (*ConfigHistory, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid history interval %s", interval)
+	}
+
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch initial snapshot: %w", err)
+	}
+
+	history := NewConfigHistory(snapshot, limit)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			snapshot, err := c.Snapshot(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				history.setErr(err)
+				continue
+			}
+
+			history.setErr(nil)
+			history.Record(snapshot)
+		}
+	}()
+
+	return history, nil
+}
+
+// Record compares the snapshot with the previous one and adds an entry if any
+// setting changed, returning the changes. Snapshots older than the previous one are
+// ignored.
+func (h *ConfigHistory) Record(snapshot *ConfigSnapshot) []ConfigChange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last == nil {
+		h.last = snapshot
+		return nil
+	}
+	if snapshot.Taken.Before(h.last.Taken) {
+		return nil
+	}
+
+	changes := h.last.Diff(snapshot)
+	if len(changes) > 0 {
+		h.entries = append(h.entries, ConfigHistoryEntry{Since: h.last.Taken, Taken: snapshot.Taken, Changes: changes})
+		if h.limit > 0 && len(h.entries) > h.limit {
+			h.entries = append([]ConfigHistoryEntry(nil), h.entries[len(h.entries)-h.limit:]...)
+		}
+	}
+	h.last = snapshot
+
+	return changes
+}
+
+// List returns the recorded entries, oldest first.
+func (h *ConfigHistory) List() []ConfigHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]ConfigHistoryEntry(nil), h.entries...)
+}
+
+// Between returns the entries whose changes were seen in [from, to), oldest first.
+func (h *ConfigHistory) Between(from time.Time, to time.Time) []ConfigHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := make([]ConfigHistoryEntry, 0)
+	for _, entry := range h.entries {
+		if !entry.Taken.Before(from) && entry.Taken.Before(to) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Err returns the error of the last poll, or nil if it succeeded. A failed poll is
+// retried at the next interval, so the history has a gap rather than stopping.
+func (h *ConfigHistory) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+func (h *ConfigHistory) setErr(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err = err
+}
@@ -0,0 +1,79 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHistory_Record(t *testing.T) {
+	start := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)
+	snapshot := func(hours int, upstreams ...any) *ConfigSnapshot {
+		return &ConfigSnapshot{
+			Taken:  start.Add(time.Duration(hours) * time.Hour),
+			Config: map[string]any{"dns": map[string]any{"upstreams": upstreams}},
+		}
+	}
+
+	history := NewConfigHistory(snapshot(0, "1.1.1.1"), 2)
+
+	assert.Empty(t, history.Record(snapshot(1, "1.1.1.1")))
+	assert.Len(t, history.Record(snapshot(2, "9.9.9.9")), 1)
+	assert.Empty(t, history.Record(snapshot(1, "8.8.8.8")), "older snapshots are ignored")
+	history.Record(snapshot(3, "8.8.8.8"))
+	history.Record(snapshot(4, "1.1.1.1"))
+
+	entries := history.List()
+	require.Len(t, entries, 2, "the oldest entry is dropped")
+	assert.Equal(t, start.Add(2*time.Hour), entries[0].Since)
+	assert.Equal(t, start.Add(3*time.Hour), entries[0].Taken)
+	assert.Equal(t, []ConfigChange{{Path: "dns.upstreams", Before: []any{"9.9.9.9"}, After: []any{"8.8.8.8"}}}, entries[0].Changes)
+
+	assert.Equal(t, entries[1:], history.Between(start.Add(4*time.Hour), start.Add(5*time.Hour)))
+}
+
+func TestConfigHistory_Poll(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	upstreams := []any{"1.1.1.1"}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, err := json.Marshal(configResponse{Config: map[string]any{"dns": map[string]any{"upstreams": upstreams}}})
+		require.NoError(t, err)
+		return newHTTPResponse(http.StatusOK, string(b)), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	history, err := client.Config.History(ctx, 5*time.Millisecond, 0)
+	require.NoError(t, err)
+
+	mu.Lock()
+	upstreams = []any{"9.9.9.9"}
+	mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		return len(history.List()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	entry := history.List()[0]
+	assert.Equal(t, []ConfigChange{{Path: "dns.upstreams", Before: []any{"1.1.1.1"}, After: []any{"9.9.9.9"}}}, entry.Changes)
+	assert.NoError(t, history.Err())
+
+	_, err = client.Config.History(ctx, 0, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ConfigAPI interface {
+	// Snapshot captures the full configuration document.
+	Snapshot(ctx context.Context) (*ConfigSnapshot, error)
+
+	// Restore rolls the configuration back to a snapshot, patching only the settings
+	// that changed since. It returns the changes it applied.
+	Restore(ctx context.Context, snapshot *ConfigSnapshot) ([]ConfigChange, error)
+
+	// History snapshots the configuration every interval until ctx is done and
+	// records each change, with its time and diff, in the returned history. It keeps
+	// at most limit entries, or all of them if limit is 0.
+	History(ctx context.Context, interval time.Duration, limit int) (*ConfigHistory, error)
+
+	// Export writes the configuration, and optionally the local records, domains and
+	// groups, as a stable-ordered YAML or JSON document that loads as a manifest.
+	// Masked secrets are left out.
+	Export(ctx context.Context, w io.Writer, format ExportFormat, opts ExportOptions) error
+}
+
+type configAPI struct {
+	client *Client
+}
+
+// maskedConfigValue is what Pi-hole returns in place of secrets such as the web
+// interface password. It must never be written back.
+const maskedConfigValue = "********"
+
+// ConfigSnapshot is the configuration tree of an instance at a point in time, as
+// served by /api/config.
+type ConfigSnapshot struct {
+	Taken  time.Time      `json:"taken"`
+	Config map[string]any `json:"config"`
+}
+
+// ConfigChange is a setting that differs between two configuration trees. Path is
+// the dotted path of the setting, e.g. dns.upstreams. Before or After is nil when the
+// setting only exists on one side.
+type ConfigChange struct {
+	Path   string `json:"path"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+type configResponse struct {
+	Config map[string]any `json:"config"`
+}
+
+// Snapshot fetches the configuration document
+func (c configAPI) Snapshot(ctx context.Context) (*ConfigSnapshot, error) {
+	var resConfig configResponse
+	if err := c.client.getJSON(ctx, "/api/config", &resConfig); err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+
+	return &ConfigSnapshot{Taken: time.Now(), Config: resConfig.Config}, nil
+}
+
+// Restore patches the settings that differ from the snapshot back to its values
+func (c configAPI) Restore(ctx context.Context, snapshot *ConfigSnapshot) (changes []ConfigChange, err error) {
+	current, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes = make([]ConfigChange, 0)
+	patch := make(map[string]any)
+	for _, change := range current.Diff(snapshot) {
+		if change.After == nil || change.After == maskedConfigValue {
+			continue
+		}
+
+		setConfigPath(patch, change.Path, change.After)
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	m := Mutation{Operation: AuditConfigRestore, Target: "config", Before: *current, After: *snapshot}
+	defer func() {
+		c.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := c.client.beforeMutation(ctx, m); err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.request(ctx, http.MethodPatch, "/api/config", configResponse{Config: patch})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		apiErr, err := newAPIError(res, b)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &apiErr
+	}
+
+	return changes, nil
+}
+
+// Diff returns the settings that differ from s in other, sorted by path. Lists are
+// compared as a whole.
+func (s *ConfigSnapshot) Diff(other *ConfigSnapshot) []ConfigChange {
+	return diffConfig(s.Config, other.Config)
+}
+
+func diffConfig(before map[string]any, after map[string]any) []ConfigChange {
+	a := flattenConfig(before)
+	b := flattenConfig(after)
+
+	changes := make([]ConfigChange, 0)
+	for path, value := range a {
+		other, ok := b[path]
+		if !ok {
+			changes = append(changes, ConfigChange{Path: path, Before: value})
+			continue
+		}
+
+		if !reflect.DeepEqual(value, other) {
+			changes = append(changes, ConfigChange{Path: path, Before: value, After: other})
+		}
+	}
+
+	for path, value := range b {
+		if _, ok := a[path]; !ok {
+			changes = append(changes, ConfigChange{Path: path, After: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// flattenConfig maps the dotted path of every leaf setting to its value.
+func flattenConfig(tree map[string]any) map[string]any {
+	leaves := make(map[string]any)
+
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		for key, value := range node {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+
+			if child, ok := value.(map[string]any); ok {
+				walk(path, child)
+				continue
+			}
+
+			leaves[path] = value
+		}
+	}
+	walk("", tree)
+
+	return leaves
+}
+
+// setConfigPath sets the leaf at a dotted path, creating intermediate objects.
+func setConfigPath(tree map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	node := tree
+
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := node[key].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			node[key] = child
+		}
+		node = child
+	}
+
+	node[keys[len(keys)-1]] = value
+}
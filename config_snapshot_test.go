@@ -0,0 +1,83 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfigTestClient stubs /api/config backed by tree, which PATCH requests merge into.
+func newConfigTestClient(t *testing.T, tree map[string]any) (*Client, *[]map[string]any) {
+	t.Helper()
+
+	patches := make([]map[string]any, 0)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config":
+			b, err := json.Marshal(configResponse{Config: tree})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodPatch && req.URL.Path == "/api/config":
+			var body configResponse
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			patches = append(patches, body.Config)
+			for path, value := range flattenConfig(body.Config) {
+				setConfigPath(tree, path, value)
+			}
+			return newHTTPResponse(http.StatusOK, `{}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client, &patches
+}
+
+func TestConfigSnapshotRestore(t *testing.T) {
+	isUnit(t)
+
+	tree := map[string]any{
+		"dns": map[string]any{
+			"upstreams":    []any{"1.1.1.1"},
+			"domainNeeded": true,
+		},
+		"webserver": map[string]any{
+			"api": map[string]any{"password": maskedConfigValue},
+		},
+	}
+	client, patches := newConfigTestClient(t, tree)
+	ctx := context.Background()
+
+	snapshot, err := client.Config.Snapshot(ctx)
+	require.NoError(t, err)
+
+	// Simulate a risky change, then roll it back.
+	tree["dns"].(map[string]any)["upstreams"] = []any{"9.9.9.9"}
+	tree["dns"].(map[string]any)["domainNeeded"] = false
+
+	current, err := client.Config.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigChange{
+		{Path: "dns.domainNeeded", Before: true, After: false},
+		{Path: "dns.upstreams", Before: []any{"1.1.1.1"}, After: []any{"9.9.9.9"}},
+	}, snapshot.Diff(current))
+
+	changes, err := client.Config.Restore(ctx, snapshot)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	require.Len(t, *patches, 1)
+	assert.Equal(t, map[string]any{"dns": map[string]any{"upstreams": []any{"1.1.1.1"}, "domainNeeded": true}}, (*patches)[0])
+
+	changes, err = client.Config.Restore(ctx, snapshot)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, *patches, 1)
+}
@@ -0,0 +1,121 @@
+package pihole
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ConfigValidationError is returned by New when the Config is invalid. It lists every
+// problem found rather than only the first, so that a CLI or provider can report
+// them all at once. It matches ErrClientValidation with errors.Is.
+type ConfigValidationError struct {
+	Problems []ConfigProblem
+}
+
+// ConfigProblem is a single problem with a Config field.
+type ConfigProblem struct {
+	// Field is the name of the Config field, e.g. BaseURL or Transport.ResolveTo.
+	Field string
+	// Message describes what is wrong with the field.
+	Message string
+	// Err is the underlying error, if any, such as the error reading a secret file.
+	Err error
+}
+
+func (p ConfigProblem) String() string {
+	return p.Field + ": " + p.Message
+}
+
+func (e *ConfigValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrClientValidation, strings.Join(problems, "; "))
+}
+
+func (e *ConfigValidationError) Is(target error) bool {
+	return target == ErrClientValidation
+}
+
+// Unwrap returns the underlying errors of the problems, so that errors.Is matches
+// e.g. fs.ErrNotExist for a missing secret file.
+func (e *ConfigValidationError) Unwrap() []error {
+	var errs []error
+	for _, problem := range e.Problems {
+		if problem.Err != nil {
+			errs = append(errs, problem.Err)
+		}
+	}
+
+	return errs
+}
+
+// validate loads the secret files and checks the config, returning a
+// ConfigValidationError listing every problem. baseURL is the normalized BaseURL.
+func (c *Config) validate(baseURL string) error {
+	problems := c.loadSecrets()
+
+	if problem := baseURLProblem(c.BaseURL, baseURL); problem != "" {
+		problems = append(problems, ConfigProblem{Field: "BaseURL", Message: problem})
+	}
+
+	if c.APIToken != "" && c.APIKey != "" && c.APIToken != c.APIKey {
+		problems = append(problems, ConfigProblem{Field: "APIKey", Message: "conflicts with APIToken; set only APIToken"})
+	}
+
+	if c.HttpClient != nil && !c.Transport.isZero() {
+		problems = append(problems, ConfigProblem{Field: "Transport", Message: "cannot be combined with HttpClient"})
+	} else if c.HttpClient == nil {
+		problems = append(problems, c.Transport.problems(baseURL)...)
+	}
+
+	switch c.SessionTransport {
+	case "", SessionTransportHeader, SessionTransportCookie:
+	default:
+		problems = append(problems, ConfigProblem{Field: "SessionTransport", Message: fmt.Sprintf("unknown value %q", c.SessionTransport)})
+	}
+
+	if c.CacheTTL < 0 {
+		problems = append(problems, ConfigProblem{Field: "CacheTTL", Message: "must not be negative"})
+	}
+
+	if c.SlowRequestThreshold < 0 {
+		problems = append(problems, ConfigProblem{Field: "SlowRequestThreshold", Message: "must not be negative"})
+	}
+
+	if len(problems) > 0 {
+		return &ConfigValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// problems checks the transport settings against the normalized BaseURL.
+func (t TransportConfig) problems(baseURL string) []ConfigProblem {
+	var problems []ConfigProblem
+
+	switch t.Protocol {
+	case HTTPProtocolAuto, HTTPProtocol1:
+	case HTTPProtocol2:
+		if !h2cSupported && strings.HasPrefix(strings.ToLower(baseURL), "http://") {
+			problems = append(problems, ConfigProblem{Field: "Transport.Protocol", Message: "HTTP/2 over plain HTTP (h2c) requires Go 1.24 or later"})
+		}
+	default:
+		problems = append(problems, ConfigProblem{Field: "Transport.Protocol", Message: fmt.Sprintf("unknown value %q", t.Protocol)})
+	}
+
+	if t.DialContext != nil && t.Resolver != nil {
+		problems = append(problems, ConfigProblem{Field: "Transport.Resolver", Message: "cannot be combined with Transport.DialContext"})
+	}
+
+	if t.ResolveTo != "" {
+		if ip, _ := splitResolveTo(t.ResolveTo); net.ParseIP(ip) == nil {
+			problems = append(problems, ConfigProblem{Field: "Transport.ResolveTo", Message: fmt.Sprintf("must be an IP address, optionally with a port, got %q", t.ResolveTo)})
+		}
+	}
+
+	return problems
+}
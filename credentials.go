@@ -0,0 +1,79 @@
+package pihole
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvURL          = "PIHOLE_URL"
+	EnvPassword     = "PIHOLE_PASSWORD"
+	EnvPasswordFile = "PIHOLE_PASSWORD_FILE"
+	EnvAPIToken     = "PIHOLE_API_TOKEN"
+	EnvAPITokenFile = "PIHOLE_API_TOKEN_FILE"
+)
+
+// ConfigFromEnv returns a Config populated from the PIHOLE_URL, PIHOLE_PASSWORD,
+// PIHOLE_PASSWORD_FILE, PIHOLE_API_TOKEN and PIHOLE_API_TOKEN_FILE environment
+// variables. Secret files are read when the config is passed to New.
+func ConfigFromEnv() Config {
+	return Config{
+		BaseURL:      os.Getenv(EnvURL),
+		Password:     os.Getenv(EnvPassword),
+		PasswordFile: os.Getenv(EnvPasswordFile),
+		APIToken:     os.Getenv(EnvAPIToken),
+		APITokenFile: os.Getenv(EnvAPITokenFile),
+	}
+}
+
+// FromEnv returns a new Pi-hole client configured from the environment, see
+// ConfigFromEnv.
+func FromEnv() (*Client, error) {
+	return New(ConfigFromEnv())
+}
+
+// loadSecrets replaces PasswordFile and APITokenFile with the secrets they hold,
+// returning the problems that prevented it.
+func (c *Config) loadSecrets() []ConfigProblem {
+	var problems []ConfigProblem
+
+	if c.PasswordFile != "" {
+		if c.Password != "" {
+			problems = append(problems, ConfigProblem{Field: "PasswordFile", Message: "cannot be combined with Password"})
+		} else if password, err := readSecretFile(c.PasswordFile); err != nil {
+			problems = append(problems, ConfigProblem{Field: "PasswordFile", Message: err.Error(), Err: err})
+		} else {
+			c.Password = password
+		}
+	}
+
+	if c.APITokenFile != "" {
+		if c.APIToken != "" || c.APIKey != "" {
+			problems = append(problems, ConfigProblem{Field: "APITokenFile", Message: "cannot be combined with APIToken"})
+		} else if token, err := readSecretFile(c.APITokenFile); err != nil {
+			problems = append(problems, ConfigProblem{Field: "APITokenFile", Message: err.Error(), Err: err})
+		} else {
+			c.APIToken = token
+		}
+	}
+
+	return problems
+}
+
+// readSecretFile reads a secret and trims the trailing newline most secret files end
+// with. Paths such as /dev/fd/3 read a secret passed on an inherited descriptor.
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	secret := strings.TrimSpace(string(b))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+
+	return secret, nil
+}
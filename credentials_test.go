@@ -0,0 +1,54 @@
+package pihole
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSecretFiles(t *testing.T) {
+	isUnit(t)
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+	require.NoError(t, os.WriteFile(tokenFile, []byte("  token\n"), 0o600))
+
+	client, err := New(Config{BaseURL: "http://pi.test", PasswordFile: passwordFile, APITokenFile: tokenFile})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", client.password)
+	assert.Equal(t, "token", client.apiKey)
+
+	_, err = New(Config{BaseURL: "http://pi.test", Password: "x", PasswordFile: passwordFile})
+	assert.ErrorIs(t, err, ErrClientValidation)
+
+	_, err = New(Config{BaseURL: "http://pi.test", PasswordFile: filepath.Join(dir, "missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	_, err = New(Config{BaseURL: "http://pi.test", APITokenFile: empty})
+	assert.ErrorIs(t, err, ErrClientValidation)
+}
+
+func TestFromEnv(t *testing.T) {
+	isUnit(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token\n"), 0o600))
+
+	t.Setenv(EnvURL, "http://pi.test")
+	t.Setenv(EnvPassword, "")
+	t.Setenv(EnvPasswordFile, "")
+	t.Setenv(EnvAPIToken, "")
+	t.Setenv(EnvAPITokenFile, tokenFile)
+
+	client, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "http://pi.test", client.baseURL)
+	assert.Equal(t, "token", client.apiKey)
+}
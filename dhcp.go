@@ -0,0 +1,284 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type DHCP interface {
+	// Leases lists all active DHCP leases.
+	Leases(ctx context.Context) (LeaseList, error)
+
+	// FindLease returns the first lease matching every field set in the query.
+	FindLease(ctx context.Context, query LeaseQuery) (*Lease, error)
+
+	// PromoteLease creates a local DNS record named after the lease under domainSuffix,
+	// unless the name already ends in it, and a static DHCP reservation for it when
+	// reserve is true.
+	PromoteLease(ctx context.Context, lease Lease, domainSuffix string, reserve bool) (*DNSRecord, error)
+}
+
+var (
+	ErrorDHCPLeaseNotFound = newNotFoundError("dhcp lease not found")
+	ErrInvalidMAC          = errors.New("invalid MAC address")
+)
+
+type dhcp struct {
+	client *Client
+}
+
+type Lease struct {
+	Expires  time.Time
+	Name     string
+	MAC      string
+	IP       string
+	ClientID string
+}
+
+type LeaseList []Lease
+
+// LeaseQuery selects leases by MAC, hostname, and/or IP. Empty fields are ignored and
+// set fields must all match. MAC addresses are accepted in any notation understood
+// by net.ParseMAC and hostnames are compared case-insensitively.
+type LeaseQuery struct {
+	MAC      string
+	Hostname string
+	IP       string
+}
+
+type leaseResponse struct {
+	Expires  int64  `json:"expires"`
+	Name     string `json:"name"`
+	HWAddr   string `json:"hwaddr"`
+	IP       string `json:"ip"`
+	ClientID string `json:"clientid"`
+}
+
+type leaseListResponse struct {
+	Leases []leaseResponse `json:"leases"`
+}
+
+type dhcpHostsResponse struct {
+	Config struct {
+		DHCP struct {
+			Hosts []string `json:"hosts"`
+		} `json:"dhcp"`
+	} `json:"config"`
+}
+
+func (res leaseListResponse) toLeaseList() LeaseList {
+	list := make(LeaseList, 0, len(res.Leases))
+	for _, entry := range res.Leases {
+		lease := Lease{
+			Name:     entry.Name,
+			MAC:      entry.HWAddr,
+			IP:       entry.IP,
+			ClientID: entry.ClientID,
+		}
+
+		if mac, err := NormalizeMAC(entry.HWAddr); err == nil {
+			lease.MAC = mac
+		}
+
+		// Leases without an expiry (infinite leases) are reported as 0.
+		if entry.Expires > 0 {
+			lease.Expires = time.Unix(entry.Expires, 0)
+		}
+
+		list = append(list, lease)
+	}
+
+	return list
+}
+
+// NormalizeMAC returns mac in lowercase, colon-separated form.
+func NormalizeMAC(mac string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMAC, mac)
+	}
+
+	return hw.String(), nil
+}
+
+// Find returns the leases in the list matching the query.
+func (l LeaseList) Find(query LeaseQuery) (LeaseList, error) {
+	var mac string
+	if query.MAC != "" {
+		normalized, err := NormalizeMAC(query.MAC)
+		if err != nil {
+			return nil, err
+		}
+		mac = normalized
+	}
+
+	var ip net.IP
+	if query.IP != "" {
+		ip = net.ParseIP(strings.TrimSpace(query.IP))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", query.IP)
+		}
+	}
+
+	hostname := strings.TrimSuffix(strings.TrimSpace(query.Hostname), ".")
+	if query.Hostname != "" && hostname == "" {
+		return nil, fmt.Errorf("invalid hostname %q", query.Hostname)
+	}
+
+	matches := make(LeaseList, 0)
+	for _, lease := range l {
+		if mac != "" && lease.MAC != mac {
+			continue
+		}
+
+		if ip != nil && !ip.Equal(net.ParseIP(lease.IP)) {
+			continue
+		}
+
+		if hostname != "" && !strings.EqualFold(lease.Name, hostname) {
+			continue
+		}
+
+		matches = append(matches, lease)
+	}
+
+	return matches, nil
+}
+
+// Leases returns all active DHCP leases
+func (d dhcp) Leases(ctx context.Context) (LeaseList, error) {
+	res, err := d.client.Get(ctx, "/api/dhcp/leases")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newDHCPAPIError(res, b)
+	}
+
+	var resList leaseListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse DHCP lease list body: %w", err)
+	}
+
+	return resList.toLeaseList(), nil
+}
+
+// FindLease returns the first lease matching the query
+func (d dhcp) FindLease(ctx context.Context, query LeaseQuery) (*Lease, error) {
+	if query == (LeaseQuery{}) {
+		return nil, fmt.Errorf("lease query must set at least one of MAC, Hostname or IP")
+	}
+
+	leases, err := d.Leases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DHCP leases: %w", err)
+	}
+
+	matches, err := leases.Find(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %+v", ErrorDHCPLeaseNotFound, query)
+	}
+
+	return &matches[0], nil
+}
+
+// PromoteLease turns an active lease into a local DNS record and optionally a static reservation
+func (d dhcp) PromoteLease(ctx context.Context, lease Lease, domainSuffix string, reserve bool) (*DNSRecord, error) {
+	name := strings.ToLower(strings.TrimSpace(lease.Name))
+	if name == "" || name == "*" {
+		return nil, fmt.Errorf("lease for %s has no hostname to promote", lease.IP)
+	}
+
+	if net.ParseIP(lease.IP) == nil {
+		return nil, fmt.Errorf("lease for %s has invalid IP address %q", name, lease.IP)
+	}
+
+	domain := name
+	if suffix := strings.ToLower(strings.Trim(strings.TrimSpace(domainSuffix), ".")); suffix != "" && !strings.HasSuffix(name, "."+suffix) {
+		domain = fmt.Sprintf("%s.%s", name, suffix)
+	}
+
+	record, err := d.client.LocalDNS.Create(ctx, domain, lease.IP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DNS record for lease %s: %w", name, err)
+	}
+
+	if reserve {
+		if err := d.reserve(ctx, lease, name); err != nil {
+			return record, fmt.Errorf("failed to create static reservation for lease %s: %w", name, err)
+		}
+	}
+
+	return record, nil
+}
+
+func (d dhcp) reserve(ctx context.Context, lease Lease, name string) (err error) {
+	defer func() {
+		var after any
+		if err == nil {
+			after = lease
+		}
+		d.client.afterMutation(ctx, Mutation{Operation: AuditDHCPReserve, Target: name, After: after}, err)
+	}()
+
+	if err := d.client.beforeMutation(ctx, Mutation{Operation: AuditDHCPReserve, Target: name, After: lease}); err != nil {
+		return err
+	}
+
+	mac, err := NormalizeMAC(lease.MAC)
+	if err != nil {
+		return err
+	}
+
+	host := strings.Join([]string{mac, lease.IP, name}, ",")
+
+	res, done, err := d.client.mutate(ctx, http.MethodPut, fmt.Sprintf("/api/config/dhcp/hosts/%s", url.PathEscape(host)), nil, func(ctx context.Context) (bool, error) {
+		return d.reserved(ctx, host)
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		b, _ := io.ReadAll(res.Body)
+		return newDHCPAPIError(res, b)
+	}
+
+	return nil
+}
+
+// reserved reports whether host is one of the static reservations in dhcp.hosts.
+func (d dhcp) reserved(ctx context.Context, host string) (bool, error) {
+	var resHosts dhcpHostsResponse
+	if err := d.client.getJSON(ctx, "/api/config/dhcp/hosts", &resHosts); err != nil {
+		return false, err
+	}
+
+	for _, entry := range resHosts.Config.DHCP.Hosts {
+		if strings.EqualFold(entry, host) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
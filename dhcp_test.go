@@ -0,0 +1,109 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLeasesBody = `{"leases":[
+	{"expires":1700000000,"name":"laptop","hwaddr":"AA:BB:CC:00:11:22","ip":"192.168.1.20","clientid":"01:aa:bb:cc:00:11:22"},
+	{"expires":0,"name":"printer","hwaddr":"aa:bb:cc:00:11:33","ip":"192.168.1.30","clientid":"*"}
+]}`
+
+func TestDHCP_FindLease(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/dhcp/leases" {
+			return newHTTPResponse(http.StatusOK, testLeasesBody), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	lease, err := client.DHCP.FindLease(ctx, LeaseQuery{MAC: "aa-bb-cc-00-11-22"})
+	require.NoError(t, err)
+	assert.Equal(t, "laptop", lease.Name)
+	assert.Equal(t, "aa:bb:cc:00:11:22", lease.MAC)
+	assert.Equal(t, int64(1700000000), lease.Expires.Unix())
+
+	lease, err = client.DHCP.FindLease(ctx, LeaseQuery{Hostname: "PRINTER", IP: "192.168.1.30"})
+	require.NoError(t, err)
+	assert.Equal(t, "aa:bb:cc:00:11:33", lease.MAC)
+	assert.True(t, lease.Expires.IsZero())
+
+	_, err = client.DHCP.FindLease(ctx, LeaseQuery{Hostname: "laptop", IP: "192.168.1.30"})
+	assert.ErrorIs(t, err, ErrorDHCPLeaseNotFound)
+
+	_, err = client.DHCP.FindLease(ctx, LeaseQuery{MAC: "not-a-mac"})
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	_, err = client.DHCP.FindLease(ctx, LeaseQuery{})
+	assert.Error(t, err)
+
+	// A blank hostname must not match every lease.
+	_, err = client.DHCP.FindLease(ctx, LeaseQuery{Hostname: "  "})
+	assert.ErrorContains(t, err, "invalid hostname")
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	for _, input := range []string{"AA:BB:CC:00:11:22", "aa-bb-cc-00-11-22", "aabb.cc00.1122", " aa:bb:cc:00:11:22 "} {
+		mac, err := NormalizeMAC(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, "aa:bb:cc:00:11:22", mac)
+	}
+}
+
+func TestDHCP_PromoteLease(t *testing.T) {
+	isUnit(t)
+
+	var (
+		hostPath    string
+		reservePath string
+	)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/api/config/dns/hosts/"):
+			hostPath = req.URL.Path
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["192.168.1.20 laptop.home.lan"]}}}`), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/api/config/dhcp/hosts/"):
+			reservePath = req.URL.Path
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	lease := Lease{Name: "Laptop", MAC: "AA-BB-CC-00-11-22", IP: "192.168.1.20"}
+
+	record, err := client.DHCP.PromoteLease(context.Background(), lease, ".home.lan.", true)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop.home.lan", record.Domain)
+	assert.Equal(t, "/api/config/dns/hosts/192.168.1.20 laptop.home.lan", hostPath)
+	assert.Equal(t, "/api/config/dhcp/hosts/aa:bb:cc:00:11:22,192.168.1.20,laptop", reservePath)
+
+	// Names that already carry the suffix keep it once.
+	lease.Name = "laptop.HOME.lan"
+	record, err = client.DHCP.PromoteLease(context.Background(), lease, "home.lan", false)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop.home.lan", record.Domain)
+	assert.Equal(t, "/api/config/dns/hosts/192.168.1.20 laptop.home.lan", hostPath)
+
+	_, err = client.DHCP.PromoteLease(context.Background(), Lease{Name: "*", IP: "192.168.1.21"}, "home.lan", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package pihole
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidRegexDomain = errors.New("invalid regex domain")
+)
+
+// posixCharacterClasses are the bracket classes understood by FTL's regex engine.
+var posixCharacterClasses = map[string]bool{
+	"alnum":  true,
+	"alpha":  true,
+	"blank":  true,
+	"cntrl":  true,
+	"digit":  true,
+	"graph":  true,
+	"lower":  true,
+	"print":  true,
+	"punct":  true,
+	"space":  true,
+	"upper":  true,
+	"xdigit": true,
+}
+
+var regexQueryTypes = map[string]bool{
+	"A":      true,
+	"AAAA":   true,
+	"ANY":    true,
+	"SRV":    true,
+	"SOA":    true,
+	"PTR":    true,
+	"TXT":    true,
+	"NAPTR":  true,
+	"MX":     true,
+	"DS":     true,
+	"RRSIG":  true,
+	"DNSKEY": true,
+	"NS":     true,
+	"SVCB":   true,
+	"HTTPS":  true,
+	"OTHER":  true,
+}
+
+var regexBackReference = regexp.MustCompile(`\\[1-9]`)
+
+// ValidateRegexDomain checks a regex domain entry against FTL's regex dialect,
+// including the ;querytype=, ;reply= and ;invert extensions. It catches patterns
+// the server would reject as well as anchors placed where they can never match.
+func ValidateRegexDomain(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("%w: empty pattern", ErrInvalidRegexDomain)
+	}
+
+	parts := strings.Split(pattern, ";")
+	expr := parts[0]
+
+	if expr == "" {
+		return fmt.Errorf("%w: %q has no expression", ErrInvalidRegexDomain, pattern)
+	}
+
+	for _, option := range parts[1:] {
+		if err := validateRegexOption(option); err != nil {
+			return fmt.Errorf("%w: %q: %s", ErrInvalidRegexDomain, pattern, err)
+		}
+	}
+
+	if err := validateRegexExpression(expr); err != nil {
+		return fmt.Errorf("%w: %q: %s", ErrInvalidRegexDomain, pattern, err)
+	}
+
+	return nil
+}
+
+func validateRegexOption(option string) error {
+	name, value, hasValue := strings.Cut(option, "=")
+
+	switch name {
+	case "invert":
+		if hasValue {
+			return fmt.Errorf("option invert does not take a value")
+		}
+	case "querytype":
+		if value == "" {
+			return fmt.Errorf("option querytype requires a value")
+		}
+
+		for _, qtype := range strings.Split(strings.TrimPrefix(value, "!"), ",") {
+			if !regexQueryTypes[strings.ToUpper(qtype)] {
+				return fmt.Errorf("unknown query type %q", qtype)
+			}
+		}
+	case "reply":
+		if value == "" {
+			return fmt.Errorf("option reply requires a value")
+		}
+	default:
+		return fmt.Errorf("unknown option %q", option)
+	}
+
+	return nil
+}
+
+func validateRegexExpression(expr string) error {
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			i++
+		case '[':
+			end, err := scanBracketExpression(expr, i)
+			if err != nil {
+				return err
+			}
+			i = end
+		case '(':
+			if i+1 < len(expr) && expr[i+1] == '?' {
+				return fmt.Errorf("perl-style group %q is not supported", expr[i:min(i+3, len(expr))])
+			}
+		case '^':
+			if i > 0 && expr[i-1] != '(' && expr[i-1] != '|' {
+				return fmt.Errorf("anchor ^ at offset %d can never match", i)
+			}
+		case '$':
+			if i+1 < len(expr) && expr[i+1] != ')' && expr[i+1] != '|' {
+				return fmt.Errorf("anchor $ at offset %d can never match", i)
+			}
+		}
+	}
+
+	// Go's regexp has no back-references, so only compile patterns without them.
+	if regexBackReference.MatchString(expr) {
+		return nil
+	}
+
+	if _, err := regexp.Compile(expr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// scanBracketExpression returns the offset of the ']' closing the bracket
+// expression opened at start, validating any named character classes inside it.
+func scanBracketExpression(expr string, start int) (int, error) {
+	i := start + 1
+	if i < len(expr) && expr[i] == '^' {
+		i++
+	}
+	if i < len(expr) && expr[i] == ']' {
+		i++
+	}
+
+	for ; i < len(expr); i++ {
+		if expr[i] == ']' {
+			return i, nil
+		}
+
+		if strings.HasPrefix(expr[i:], "[:") {
+			end := strings.Index(expr[i+2:], ":]")
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated character class at offset %d", i)
+			}
+
+			class := expr[i+2 : i+2+end]
+			if !posixCharacterClasses[class] {
+				return 0, fmt.Errorf("unsupported character class [:%s:]", class)
+			}
+
+			i += end + 3
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated bracket expression at offset %d", start)
+}
@@ -0,0 +1,42 @@
+package pihole
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRegexDomain(t *testing.T) {
+	tcs := []struct {
+		name    string
+		pattern string
+		valid   bool
+	}{
+		{name: "anchored subdomain match", pattern: `(\.|^)example\.com$`, valid: true},
+		{name: "posix character class", pattern: `^ad[[:digit:]]+\.`, valid: true},
+		{name: "shorthand classes", pattern: `^\d+\.\w+$`, valid: true},
+		{name: "back-reference", pattern: `^(ab)\1\.example$`, valid: true},
+		{name: "querytype option", pattern: `^tracker\.;querytype=!A,AAAA`, valid: true},
+		{name: "invert and reply options", pattern: `\.lan$;invert;reply=NXDOMAIN`, valid: true},
+		{name: "empty", pattern: ` `},
+		{name: "misplaced start anchor", pattern: `ads^\.example`},
+		{name: "misplaced end anchor", pattern: `ads$\.example`},
+		{name: "unknown character class", pattern: `[[:word:]]+\.example`},
+		{name: "perl lookahead", pattern: `(?!good)\.example`},
+		{name: "unterminated bracket", pattern: `[a-z\.example`},
+		{name: "unbalanced group", pattern: `(ads\.example`},
+		{name: "unknown option", pattern: `ads\.;foo=bar`},
+		{name: "unknown query type", pattern: `ads\.;querytype=BOGUS`},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRegexDomain(tc.pattern)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidRegexDomain)
+			}
+		})
+	}
+}
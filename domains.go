@@ -0,0 +1,442 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Domains interface {
+	// List all allow and deny domain entries.
+	List(ctx context.Context) (DomainList, error)
+
+	// Get a domain entry by its ID.
+	Get(ctx context.Context, id int64) (*Domain, error)
+
+	// SetEnabled enables or disables a domain entry, keeping its comment and groups.
+	SetEnabled(ctx context.Context, id int64, enabled bool) (*Domain, error)
+
+	// Update sets the type, comment, groups and enabled state of a domain entry to
+	// those of entry. The entry keeps its domain and kind; an empty Type keeps its
+	// type.
+	Update(ctx context.Context, id int64, entry DomainEntry) (*Domain, error)
+
+	// Delete a domain entry by its ID. A missing entry is not an error.
+	Delete(ctx context.Context, id int64) error
+
+	// AddBatch adds many domain entries of the same kind, continuing past individual
+	// failures. The error joins an *OperationError for each entry that failed.
+	AddBatch(ctx context.Context, kind DomainKind, entries []DomainEntry) ([]DomainBatchResult, error)
+}
+
+// DomainType is the list a domain entry belongs to.
+type DomainType string
+
+const (
+	DomainTypeAllow DomainType = "allow"
+	DomainTypeDeny  DomainType = "deny"
+)
+
+// DomainKind controls how a domain entry is matched.
+type DomainKind string
+
+const (
+	DomainKindExact DomainKind = "exact"
+	DomainKindRegex DomainKind = "regex"
+)
+
+var (
+	ErrorDomainNotFound = newNotFoundError("domain entry not found")
+)
+
+type domains struct {
+	client *Client
+}
+
+type Domain struct {
+	ID           int64
+	Domain       string
+	Unicode      string
+	Type         DomainType
+	Kind         DomainKind
+	Comment      string
+	Groups       []int
+	Enabled      bool
+	DateAdded    time.Time
+	DateModified time.Time
+}
+
+type DomainList []Domain
+
+// DomainEntry describes a domain to be added to the allow or deny list.
+type DomainEntry struct {
+	Domain  string
+	Type    DomainType
+	Comment string
+	Groups  []int
+
+	// Disabled adds the entry without enabling it.
+	Disabled bool
+}
+
+// DomainBatchStatus is the outcome of a single entry submitted through AddBatch.
+type DomainBatchStatus string
+
+const (
+	DomainBatchCreated      DomainBatchStatus = "created"
+	DomainBatchDuplicate    DomainBatchStatus = "duplicate"
+	DomainBatchInvalidRegex DomainBatchStatus = "invalid_regex"
+	DomainBatchFailed       DomainBatchStatus = "failed"
+)
+
+type DomainBatchResult struct {
+	Entry  DomainEntry
+	Status DomainBatchStatus
+	Err    error
+}
+
+type domainResponse struct {
+	ID           int64  `json:"id"`
+	Domain       string `json:"domain"`
+	Unicode      string `json:"unicode"`
+	Type         string `json:"type"`
+	Kind         string `json:"kind"`
+	Comment      string `json:"comment"`
+	Groups       []int  `json:"groups"`
+	Enabled      bool   `json:"enabled"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type domainListResponse struct {
+	Domains   []domainResponse         `json:"domains"`
+	Processed *domainProcessedResponse `json:"processed"`
+}
+
+type domainProcessedResponse struct {
+	Success []domainProcessedItem `json:"success"`
+	Errors  []domainProcessedItem `json:"errors"`
+}
+
+type domainProcessedItem struct {
+	Item  string `json:"item"`
+	Error string `json:"error"`
+}
+
+type domainUpdateRequest struct {
+	Type    DomainType `json:"type"`
+	Kind    DomainKind `json:"kind"`
+	Comment string     `json:"comment"`
+	Groups  []int      `json:"groups"`
+	Enabled bool       `json:"enabled"`
+}
+
+type domainRequest struct {
+	Domain  string `json:"domain"`
+	Comment string `json:"comment,omitempty"`
+	Groups  []int  `json:"groups,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (res domainResponse) toDomain() Domain {
+	return Domain{
+		ID:           res.ID,
+		Domain:       res.Domain,
+		Unicode:      res.Unicode,
+		Type:         DomainType(res.Type),
+		Kind:         DomainKind(res.Kind),
+		Comment:      res.Comment,
+		Groups:       res.Groups,
+		Enabled:      res.Enabled,
+		DateAdded:    time.Unix(res.DateAdded, 0),
+		DateModified: time.Unix(res.DateModified, 0),
+	}
+}
+
+func (res domainListResponse) toDomainList() DomainList {
+	list := make(DomainList, 0, len(res.Domains))
+	for _, entry := range res.Domains {
+		list = append(list, entry.toDomain())
+	}
+
+	return list
+}
+
+// List returns all allow and deny domain entries
+func (d domains) List(ctx context.Context) (DomainList, error) {
+	res, err := d.client.Get(ctx, "/api/domains")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newDomainAPIError(res, b)
+	}
+
+	var resList domainListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse domain list body: %w", err)
+	}
+
+	return resList.toDomainList(), nil
+}
+
+// Get returns a domain entry by its ID
+func (d domains) Get(ctx context.Context, id int64) (*Domain, error) {
+	list, err := d.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch domain entries: %w", err)
+	}
+
+	for _, domain := range list {
+		if domain.ID == id {
+			return &domain, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d", ErrorDomainNotFound, id)
+}
+
+// SetEnabled toggles the enabled flag of a domain entry without deleting it
+func (d domains) SetEnabled(ctx context.Context, id int64, enabled bool) (*Domain, error) {
+	domain, err := d.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.update(ctx, domain, DomainEntry{Type: domain.Type, Comment: domain.Comment, Groups: domain.Groups, Disabled: !enabled})
+}
+
+// Update rewrites the type, comment, groups and enabled flag of a domain entry
+func (d domains) Update(ctx context.Context, id int64, entry DomainEntry) (*Domain, error) {
+	domain, err := d.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.update(ctx, domain, entry)
+}
+
+func (d domains) update(ctx context.Context, domain *Domain, entry DomainEntry) (updated *Domain, err error) {
+	proposed := *domain
+	if entry.Type != "" {
+		proposed.Type = entry.Type
+	}
+	proposed.Comment = entry.Comment
+	proposed.Groups = entry.Groups
+	proposed.Enabled = !entry.Disabled
+
+	defer func() {
+		d.client.afterMutation(ctx, Mutation{Operation: AuditDomainUpdate, Target: domain.Domain, Before: *domain, After: auditValue(updated)}, err)
+	}()
+
+	if err := d.client.beforeMutation(ctx, Mutation{Operation: AuditDomainUpdate, Target: domain.Domain, Before: *domain, After: proposed}); err != nil {
+		return nil, err
+	}
+
+	if proposed.Type != DomainTypeAllow && proposed.Type != DomainTypeDeny {
+		return nil, fmt.Errorf("invalid domain type %q for %s", proposed.Type, domain.Domain)
+	}
+
+	res, done, err := d.client.mutate(ctx, http.MethodPut, domainPath(domain.Type, domain.Kind, domain.Domain), domainUpdateRequest{
+		Type:    proposed.Type,
+		Kind:    domain.Kind,
+		Comment: proposed.Comment,
+		Groups:  proposed.Groups,
+		Enabled: proposed.Enabled,
+	}, func(ctx context.Context) (bool, error) {
+		current, err := d.find(ctx, proposed.Type, domain.Kind, domain.Domain)
+		if err != nil || current == nil {
+			return false, err
+		}
+
+		return current.Comment == proposed.Comment && slices.Equal(current.Groups, proposed.Groups) && current.Enabled == proposed.Enabled, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return d.Get(ctx, domain.ID)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newDomainAPIError(res, b)
+	}
+
+	return d.Get(ctx, domain.ID)
+}
+
+// AddBatch submits each entry individually so that a single duplicate or invalid
+// regex does not abort the remaining entries. The returned results are in the same
+// order as entries, and the error joins an *OperationError for each failed entry.
+// Duplicates are not failures.
+func (d domains) AddBatch(ctx context.Context, kind DomainKind, entries []DomainEntry) ([]DomainBatchResult, error) {
+	results := make([]DomainBatchResult, 0, len(entries))
+	var errs []error
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return results, errors.Join(append(errs, err)...)
+		}
+
+		status, err := d.add(ctx, kind, entry)
+		results = append(results, DomainBatchResult{Entry: entry, Status: status, Err: err})
+		if err != nil {
+			errs = append(errs, &OperationError{Operation: AuditDomainAdd, Target: entry.Domain, Err: err})
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (d domains) add(ctx context.Context, kind DomainKind, entry DomainEntry) (status DomainBatchStatus, err error) {
+	defer func() {
+		var after any
+		if err == nil && status == DomainBatchCreated {
+			after = entry
+		}
+		d.client.afterMutation(ctx, Mutation{Operation: AuditDomainAdd, Target: entry.Domain, After: after}, err)
+	}()
+
+	if err := d.client.beforeMutation(ctx, Mutation{Operation: AuditDomainAdd, Target: entry.Domain, After: entry}); err != nil {
+		return DomainBatchFailed, err
+	}
+
+	if entry.Type != DomainTypeAllow && entry.Type != DomainTypeDeny {
+		return DomainBatchFailed, fmt.Errorf("invalid domain type %q for %s", entry.Type, entry.Domain)
+	}
+
+	if kind == DomainKindRegex {
+		if err := ValidateRegexDomain(entry.Domain); err != nil {
+			return DomainBatchInvalidRegex, err
+		}
+	}
+
+	res, done, err := d.client.mutate(ctx, http.MethodPost, domainsPath(entry.Type, kind), domainRequest{
+		Domain:  entry.Domain,
+		Comment: entry.Comment,
+		Groups:  entry.Groups,
+		Enabled: !entry.Disabled,
+	}, func(ctx context.Context) (bool, error) {
+		current, err := d.find(ctx, entry.Type, kind, entry.Domain)
+		return current != nil, err
+	})
+	if err != nil {
+		return DomainBatchFailed, err
+	}
+	if done {
+		return DomainBatchCreated, nil
+	}
+
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusCreated {
+		apiErr := newDomainAPIError(res, b)
+		if domainErr, ok := apiErr.(*DomainAPIError); ok && domainErr.Key == "regex_error" {
+			return DomainBatchInvalidRegex, apiErr
+		}
+
+		return DomainBatchFailed, apiErr
+	}
+
+	var resList domainListResponse
+	if err := json.Unmarshal(b, &resList); err != nil {
+		return DomainBatchFailed, fmt.Errorf("failed to parse domain create body: %w", err)
+	}
+
+	if resList.Processed != nil {
+		for _, item := range resList.Processed.Errors {
+			if strings.Contains(item.Error, "UNIQUE constraint failed") {
+				return DomainBatchDuplicate, nil
+			}
+
+			return DomainBatchFailed, fmt.Errorf("failed to add domain %s: %s", item.Item, item.Error)
+		}
+	}
+
+	return DomainBatchCreated, nil
+}
+
+// Delete removes the domain entry with the given ID
+func (d domains) Delete(ctx context.Context, id int64) error {
+	domain, err := d.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrorDomainNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed looking up domain entry %d for deletion: %w", id, err)
+	}
+
+	return d.remove(ctx, domain.Type, domain.Kind, domain.Domain)
+}
+
+// remove deletes a domain entry, which batches use to roll back additions.
+func (d domains) remove(ctx context.Context, domainType DomainType, kind DomainKind, domain string) (err error) {
+	m := Mutation{Operation: AuditDomainDelete, Target: domain}
+	defer func() {
+		d.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := d.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	res, done, err := d.client.mutate(ctx, http.MethodDelete, domainPath(domainType, kind, domain), nil, func(ctx context.Context) (bool, error) {
+		current, err := d.find(ctx, domainType, kind, domain)
+		return current == nil, err
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newDomainAPIError(res, b)
+	}
+
+	return nil
+}
+
+// find returns the entry for domain on the given list, or nil if there is none.
+func (d domains) find(ctx context.Context, domainType DomainType, kind DomainKind, domain string) (*Domain, error) {
+	list, err := d.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range list {
+		if entry.Type == domainType && entry.Kind == kind && entry.Domain == domain {
+			return &entry, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func domainsPath(domainType DomainType, kind DomainKind) string {
+	return fmt.Sprintf("/api/domains/%s/%s", domainType, kind)
+}
+
+func domainPath(domainType DomainType, kind DomainKind, domain string) string {
+	return fmt.Sprintf("%s/%s", domainsPath(domainType, kind), url.PathEscape(domain))
+}
@@ -0,0 +1,166 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomains_AddBatchReportsPerEntryResults(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.URL.Path != "/api/domains/deny/regex" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		var body domainRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+
+		switch body.Domain {
+		case `(\.|^)ads\.example$`:
+			return newHTTPResponse(http.StatusCreated, `{"domains":[],"processed":{"success":[{"item":"(\\.|^)ads\\.example$"}],"errors":[]}}`), nil
+		case `(\.|^)dup\.example$`:
+			return newHTTPResponse(http.StatusCreated, `{"domains":[],"processed":{"success":[],"errors":[{"item":"(\\.|^)dup\\.example$","error":"UNIQUE constraint failed: domainlist.domain, domainlist.type"}]}}`), nil
+		default:
+			return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"regex_error","message":"Regex validation failed","hint":"Missing ')'"}}`), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	results, err := client.Domains.AddBatch(context.Background(), DomainKindRegex, []DomainEntry{
+		{Domain: `(\.|^)ads\.example$`, Type: DomainTypeDeny},
+		{Domain: `(\.|^)dup\.example$`, Type: DomainTypeDeny},
+		{Domain: `rejected\.example$`, Type: DomainTypeDeny},
+		{Domain: `(broken`, Type: DomainTypeDeny},
+		{Domain: `missing-type`},
+	})
+	require.Len(t, results, 5)
+
+	failed := OperationErrors(err)
+	require.Len(t, failed, 3)
+	for i, target := range []string{`rejected\.example$`, `(broken`, `missing-type`} {
+		assert.Equal(t, AuditDomainAdd, failed[i].Operation)
+		assert.Equal(t, target, failed[i].Target)
+	}
+	assert.ErrorIs(t, err, ErrInvalidRegexDomain)
+
+	assert.Equal(t, DomainBatchCreated, results[0].Status)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, DomainBatchDuplicate, results[1].Status)
+	assert.Equal(t, DomainBatchInvalidRegex, results[2].Status)
+
+	var apiErr *DomainAPIError
+	require.ErrorAs(t, results[2].Err, &apiErr)
+	assert.Equal(t, "regex_error", apiErr.Key)
+
+	assert.Equal(t, DomainBatchInvalidRegex, results[3].Status)
+	assert.ErrorIs(t, results[3].Err, ErrInvalidRegexDomain)
+
+	assert.Equal(t, DomainBatchFailed, results[4].Status)
+	assert.Error(t, results[4].Err)
+}
+
+func TestDomainListResponse_toDomainList(t *testing.T) {
+	var resp domainListResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"domains":[{"id":3,"domain":"ads.example","unicode":"ads.example","type":"deny","kind":"exact","comment":null,"groups":[0],"enabled":true,"date_added":1700000000,"date_modified":1700000100}]}`), &resp))
+
+	list := resp.toDomainList()
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(3), list[0].ID)
+	assert.Equal(t, DomainTypeDeny, list[0].Type)
+	assert.Equal(t, DomainKindExact, list[0].Kind)
+	assert.Equal(t, []int{0}, list[0].Groups)
+	assert.True(t, list[0].Enabled)
+	assert.Equal(t, int64(1700000100), list[0].DateModified.Unix())
+}
+
+func TestDomains_SetEnabledKeepsCommentAndGroups(t *testing.T) {
+	isUnit(t)
+
+	enabled := true
+	var update domainUpdateRequest
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/domains":
+			return newHTTPResponse(http.StatusOK, fmt.Sprintf(`{"domains":[{"id":7,"domain":"ads.example","type":"deny","kind":"exact","comment":"feed","groups":[0,2],"enabled":%t}]}`, enabled)), nil
+		case req.Method == http.MethodPut && req.URL.Path == "/api/domains/deny/exact/ads.example":
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&update))
+			enabled = update.Enabled
+			return newHTTPResponse(http.StatusOK, `{"domains":[],"processed":{"success":[{"item":"ads.example"}],"errors":[]}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	domain, err := client.Domains.SetEnabled(context.Background(), 7, false)
+	require.NoError(t, err)
+	assert.False(t, domain.Enabled)
+	assert.Equal(t, "feed", update.Comment)
+	assert.Equal(t, []int{0, 2}, update.Groups)
+
+	_, err = client.Domains.SetEnabled(context.Background(), 8, false)
+	assert.ErrorIs(t, err, ErrorDomainNotFound)
+}
+
+func TestDomains_Update(t *testing.T) {
+	isUnit(t)
+
+	var update domainUpdateRequest
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/domains":
+			return newHTTPResponse(http.StatusOK, `{"domains":[{"id":7,"domain":"ads.example","type":"deny","kind":"exact","comment":"feed","groups":[0,2],"enabled":true}]}`), nil
+		case req.Method == http.MethodPut && req.URL.Path == "/api/domains/deny/exact/ads.example":
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&update))
+			return newHTTPResponse(http.StatusOK, `{"domains":[],"processed":{"success":[{"item":"ads.example"}],"errors":[]}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	_, err = client.Domains.Update(context.Background(), 7, DomainEntry{Comment: "", Groups: []int{3}, Disabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, domainUpdateRequest{Type: DomainTypeDeny, Kind: DomainKindExact, Comment: "", Groups: []int{3}, Enabled: false}, update)
+
+	_, err = client.Domains.Update(context.Background(), 7, DomainEntry{Type: "block"})
+	assert.ErrorContains(t, err, "invalid domain type")
+}
+
+func TestDomains_Delete(t *testing.T) {
+	isUnit(t)
+
+	var deleted []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/domains":
+			return newHTTPResponse(http.StatusOK, `{"domains":[{"id":7,"domain":"(\\.|^)ads\\.example$","type":"deny","kind":"regex","groups":[0],"enabled":true}]}`), nil
+		case req.Method == http.MethodDelete:
+			deleted = append(deleted, req.URL.EscapedPath())
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	require.NoError(t, client.Domains.Delete(context.Background(), 7))
+	require.NoError(t, client.Domains.Delete(context.Background(), 8))
+	assert.Equal(t, []string{"/api/domains/deny/regex/%28%5C.%7C%5E%29ads%5C.example$"}, deleted)
+}
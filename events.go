@@ -0,0 +1,302 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subsystem names the part of Pi-hole an event comes from.
+type Subsystem string
+
+const (
+	SubsystemLocalDNS   Subsystem = "local_dns"
+	SubsystemLocalCNAME Subsystem = "local_cname"
+	SubsystemBlocking   Subsystem = "blocking"
+	SubsystemMessages   Subsystem = "messages"
+	SubsystemLists      Subsystem = "lists"
+)
+
+// EventType is what happened, qualified by its subsystem, e.g. local_dns.added.
+type EventType string
+
+const (
+	EventLocalDNSAdded     EventType = "local_dns.added"
+	EventLocalDNSRemoved   EventType = "local_dns.removed"
+	EventLocalDNSChanged   EventType = "local_dns.changed"
+	EventLocalCNAMEAdded   EventType = "local_cname.added"
+	EventLocalCNAMERemoved EventType = "local_cname.removed"
+	EventLocalCNAMEChanged EventType = "local_cname.changed"
+	EventBlockingChanged   EventType = "blocking.changed"
+	EventMessageAdded      EventType = "messages.added"
+	EventAdlistChanged     EventType = "lists.changed"
+
+	// EventPollFailed is published by every subsystem when a poll fails. Its payload
+	// is the error.
+	EventPollFailed EventType = "poll.failed"
+)
+
+// Subsystem returns the subsystem of a subsystem-qualified event type, or "" for
+// EventPollFailed.
+func (t EventType) Subsystem() Subsystem {
+	if t == EventPollFailed {
+		return ""
+	}
+
+	subsystem, _, _ := strings.Cut(string(t), ".")
+	return Subsystem(subsystem)
+}
+
+// Event is published on an EventBus. Payload is the watcher's own event: a
+// RecordEvent, CNAMERecordEvent, BlockingEvent, MessageEvent or AdlistEvent, or the
+// error for EventPollFailed.
+type Event struct {
+	Type      EventType
+	Subsystem Subsystem
+	Payload   any
+}
+
+// EventBus polls each subsystem once per interval on behalf of all its subscribers,
+// instead of every consumer running its own watchers.
+type EventBus struct {
+	client   *Client
+	interval time.Duration
+
+	mu            sync.Mutex
+	subscriptions []*subscription
+}
+
+type subscription struct {
+	ctx   context.Context
+	types []EventType
+
+	mu     sync.Mutex
+	ch     chan Event
+	closed bool
+}
+
+// Events returns an event bus polling the client every interval. Subscribe first,
+// then call Run.
+func (c *Client) Events(interval time.Duration) *EventBus {
+	return &EventBus{client: c, interval: interval}
+}
+
+// Subscribe returns a channel receiving the events of the given types, or all events
+// when none are given. The channel is closed when ctx is done or Run returns. A
+// subscriber that does not keep up delays the others.
+func (b *EventBus) Subscribe(ctx context.Context, types ...EventType) <-chan Event {
+	sub := &subscription{ctx: ctx, types: types, ch: make(chan Event)}
+
+	b.mu.Lock()
+	b.subscriptions = append(b.subscriptions, sub)
+	b.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		b.unsubscribe(sub)
+	})
+
+	return sub.ch
+}
+
+// Run polls the subsystems that have subscribers until ctx is done. Subsystems only
+// subscribed to after Run started are not polled. Run fails if a watcher cannot
+// fetch its initial state.
+func (b *EventBus) Run(ctx context.Context) error {
+	defer b.closeAll()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sources := make([]<-chan Event, 0)
+	for _, subsystem := range b.subscribedSubsystems() {
+		events, err := b.watch(ctx, subsystem)
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", subsystem, err)
+		}
+		sources = append(sources, events)
+	}
+
+	var wg sync.WaitGroup
+	for _, events := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range events {
+				b.publish(ctx, event)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (b *EventBus) watch(ctx context.Context, subsystem Subsystem) (<-chan Event, error) {
+	switch subsystem {
+	case SubsystemLocalDNS:
+		events, err := b.client.LocalDNS.Watch(ctx, b.interval)
+		return forwardEvents(ctx, events, subsystem, func(e RecordEvent) (EventType, error) {
+			return recordEventType(subsystem, e.Type), e.Err
+		}), err
+	case SubsystemLocalCNAME:
+		events, err := b.client.LocalCNAME.Watch(ctx, b.interval)
+		return forwardEvents(ctx, events, subsystem, func(e CNAMERecordEvent) (EventType, error) {
+			return recordEventType(subsystem, e.Type), e.Err
+		}), err
+	case SubsystemBlocking:
+		events, err := b.client.Blocking.Watch(ctx, b.interval)
+		return forwardEvents(ctx, events, subsystem, func(e BlockingEvent) (EventType, error) {
+			return EventBlockingChanged, e.Err
+		}), err
+	case SubsystemMessages:
+		events, err := b.client.Messages.Watch(ctx, b.interval)
+		return forwardEvents(ctx, events, subsystem, func(e MessageEvent) (EventType, error) {
+			return EventMessageAdded, e.Err
+		}), err
+	case SubsystemLists:
+		events, err := b.client.Lists.Watch(ctx, b.interval)
+		return forwardEvents(ctx, events, subsystem, func(e AdlistEvent) (EventType, error) {
+			return EventAdlistChanged, e.Err
+		}), err
+	default:
+		return nil, fmt.Errorf("unknown subsystem %q", subsystem)
+	}
+}
+
+// forwardEvents wraps the events of a watcher, publishing failed polls as
+// EventPollFailed with the error as payload.
+func forwardEvents[E any](ctx context.Context, events <-chan E, subsystem Subsystem, classify func(E) (EventType, error)) <-chan Event {
+	if events == nil {
+		return nil
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for event := range events {
+			forwarded := Event{Subsystem: subsystem, Payload: event}
+			eventType, err := classify(event)
+			if err != nil {
+				forwarded.Type, forwarded.Payload = EventPollFailed, err
+			} else {
+				forwarded.Type = eventType
+			}
+
+			select {
+			case out <- forwarded:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func recordEventType(subsystem Subsystem, t RecordEventType) EventType {
+	return EventType(string(subsystem) + "." + string(t))
+}
+
+// subscribedSubsystems returns the subsystems at least one live subscription wants
+// events from, in a fixed order.
+func (b *EventBus) subscribedSubsystems() []Subsystem {
+	all := []Subsystem{SubsystemLocalDNS, SubsystemLocalCNAME, SubsystemBlocking, SubsystemMessages, SubsystemLists}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wanted := make([]Subsystem, 0, len(all))
+	for _, subsystem := range all {
+		for _, sub := range b.subscriptions {
+			if sub.wants(subsystem) {
+				wanted = append(wanted, subsystem)
+				break
+			}
+		}
+	}
+
+	return wanted
+}
+
+func (b *EventBus) publish(ctx context.Context, event Event) {
+	b.mu.Lock()
+	subscriptions := slices.Clone(b.subscriptions)
+	b.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		if sub.matches(event) {
+			sub.send(ctx, event)
+		}
+	}
+}
+
+func (b *EventBus) unsubscribe(sub *subscription) {
+	b.mu.Lock()
+	b.subscriptions = slices.DeleteFunc(b.subscriptions, func(s *subscription) bool {
+		return s == sub
+	})
+	b.mu.Unlock()
+
+	sub.close()
+}
+
+func (b *EventBus) closeAll() {
+	b.mu.Lock()
+	subscriptions := b.subscriptions
+	b.subscriptions = nil
+	b.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.close()
+	}
+}
+
+// wants reports whether the subscription takes events from subsystem.
+func (s *subscription) wants(subsystem Subsystem) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	if len(s.types) == 0 {
+		return true
+	}
+
+	for _, t := range s.types {
+		if t.Subsystem() == subsystem {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *subscription) matches(event Event) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, event.Type)
+}
+
+func (s *subscription) send(ctx context.Context, event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- event:
+	case <-s.ctx.Done():
+	case <-ctx.Done():
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,107 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus_SharesOnePoller(t *testing.T) {
+	isUnit(t)
+
+	var (
+		mu    sync.Mutex
+		state = "enabled"
+		polls = make(map[string]int)
+	)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		polls[req.URL.Path]++
+		switch req.URL.Path {
+		case "/api/dns/blocking":
+			return newHTTPResponse(http.StatusOK, `{"blocking":"`+state+`","timer":null}`), nil
+		case "/api/info/messages":
+			return newHTTPResponse(http.StatusOK, `{"messages":[]}`), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := client.Events(5 * time.Millisecond)
+	first := bus.Subscribe(ctx, EventBlockingChanged)
+	second := bus.Subscribe(ctx, EventBlockingChanged, EventMessageAdded)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- bus.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return polls["/api/dns/blocking"] > 0 && polls["/api/info/messages"] > 0
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	state = "disabled"
+	mu.Unlock()
+
+	for _, events := range []<-chan Event{first, second} {
+		select {
+		case event := <-events:
+			assert.Equal(t, EventBlockingChanged, event.Type)
+			assert.Equal(t, SubsystemBlocking, event.Subsystem)
+			require.IsType(t, BlockingEvent{}, event.Payload)
+			assert.Equal(t, BlockingDisabled, event.Payload.(BlockingEvent).New.State)
+		case <-time.After(time.Second):
+			t.Fatal("no blocking event")
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.NotContains(t, polls, "/api/config/dns/hosts")
+	assert.NotContains(t, polls, "/api/lists")
+}
+
+func TestEventBus_ClosesCancelledSubscriptions(t *testing.T) {
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test"})
+	require.NoError(t, err)
+
+	bus := client.Events(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := bus.Subscribe(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+
+	assert.Empty(t, bus.subscribedSubsystems())
+}
+
+func TestEventType_Subsystem(t *testing.T) {
+	assert.Equal(t, SubsystemLocalDNS, EventLocalDNSAdded.Subsystem())
+	assert.Equal(t, SubsystemLists, EventAdlistChanged.Subsystem())
+	assert.Equal(t, Subsystem(""), EventPollFailed.Subsystem())
+}
@@ -0,0 +1,296 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Groups interface {
+	// List all groups.
+	List(ctx context.Context) (GroupList, error)
+
+	// Get a group by its name.
+	Get(ctx context.Context, name string) (*Group, error)
+
+	// Create a group.
+	Create(ctx context.Context, entry GroupEntry) (*Group, error)
+
+	// Update sets the comment and enabled state of the group called name to those
+	// of entry, renaming it if entry has another name.
+	Update(ctx context.Context, name string, entry GroupEntry) (*Group, error)
+
+	// Delete a group by its name. Domains, lists and clients lose their assignment
+	// to it. A missing group is not an error.
+	Delete(ctx context.Context, name string) error
+}
+
+var (
+	ErrorGroupNotFound = newNotFoundError("group not found")
+)
+
+type groups struct {
+	client *Client
+}
+
+type Group struct {
+	ID           int64
+	Name         string
+	Comment      string
+	Enabled      bool
+	DateAdded    time.Time
+	DateModified time.Time
+}
+
+type GroupList []Group
+
+// GroupEntry describes a group to be created.
+type GroupEntry struct {
+	Name    string
+	Comment string
+
+	// Disabled creates the group without enabling it.
+	Disabled bool
+}
+
+type groupResponse struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Comment      string `json:"comment"`
+	Enabled      bool   `json:"enabled"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type groupListResponse struct {
+	Groups    []groupResponse          `json:"groups"`
+	Processed *domainProcessedResponse `json:"processed"`
+}
+
+type groupRequest struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+type groupUpdateRequest struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (res groupResponse) toGroup() Group {
+	return Group{
+		ID:           res.ID,
+		Name:         res.Name,
+		Comment:      res.Comment,
+		Enabled:      res.Enabled,
+		DateAdded:    time.Unix(res.DateAdded, 0),
+		DateModified: time.Unix(res.DateModified, 0),
+	}
+}
+
+func (res groupListResponse) toGroupList() GroupList {
+	list := make(GroupList, 0, len(res.Groups))
+	for _, entry := range res.Groups {
+		list = append(list, entry.toGroup())
+	}
+
+	return list
+}
+
+// List returns all groups
+func (g groups) List(ctx context.Context) (GroupList, error) {
+	res, err := g.client.Get(ctx, "/api/groups")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newGroupAPIError(res, b)
+	}
+
+	var resList groupListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse group list body: %w", err)
+	}
+
+	return resList.toGroupList(), nil
+}
+
+// Get returns a group by its name
+func (g groups) Get(ctx context.Context, name string) (*Group, error) {
+	list, err := g.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	for _, group := range list {
+		if group.Name == name {
+			return &group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrorGroupNotFound, name)
+}
+
+// Create creates a group
+func (g groups) Create(ctx context.Context, entry GroupEntry) (created *Group, err error) {
+	defer func() {
+		g.client.afterMutation(ctx, Mutation{Operation: AuditGroupCreate, Target: entry.Name, After: auditValue(created)}, err)
+	}()
+
+	if err := g.client.beforeMutation(ctx, Mutation{Operation: AuditGroupCreate, Target: entry.Name, After: entry}); err != nil {
+		return nil, err
+	}
+
+	res, done, err := g.client.mutate(ctx, http.MethodPost, "/api/groups", groupRequest{
+		Name:    entry.Name,
+		Comment: entry.Comment,
+		Enabled: !entry.Disabled,
+	}, func(ctx context.Context) (bool, error) {
+		current, err := g.lookup(ctx, entry.Name)
+		return current != nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return g.Get(ctx, entry.Name)
+	}
+
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusCreated {
+		return nil, newGroupAPIError(res, b)
+	}
+
+	var resList groupListResponse
+	if err := json.Unmarshal(b, &resList); err != nil {
+		return nil, fmt.Errorf("failed to parse group create body: %w", err)
+	}
+
+	if resList.Processed != nil {
+		for _, item := range resList.Processed.Errors {
+			return nil, fmt.Errorf("failed to create group %s: %s", item.Item, item.Error)
+		}
+	}
+
+	return g.Get(ctx, entry.Name)
+}
+
+// Update rewrites the name, comment and enabled flag of a group
+func (g groups) Update(ctx context.Context, name string, entry GroupEntry) (updated *Group, err error) {
+	group, err := g.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Name == "" {
+		entry.Name = group.Name
+	}
+
+	defer func() {
+		g.client.afterMutation(ctx, Mutation{Operation: AuditGroupUpdate, Target: name, Before: *group, After: auditValue(updated)}, err)
+	}()
+
+	if err := g.client.beforeMutation(ctx, Mutation{Operation: AuditGroupUpdate, Target: name, Before: *group, After: entry}); err != nil {
+		return nil, err
+	}
+
+	res, done, err := g.client.mutate(ctx, http.MethodPut, "/api/groups/"+url.PathEscape(group.Name), groupUpdateRequest{
+		Name:    entry.Name,
+		Comment: entry.Comment,
+		Enabled: !entry.Disabled,
+	}, func(ctx context.Context) (bool, error) {
+		current, err := g.lookup(ctx, entry.Name)
+		return current != nil && current.Comment == entry.Comment && current.Enabled == !entry.Disabled, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return g.Get(ctx, entry.Name)
+	}
+
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return nil, newGroupAPIError(res, b)
+	}
+
+	var resList groupListResponse
+	if err := json.Unmarshal(b, &resList); err != nil {
+		return nil, fmt.Errorf("failed to parse group update body: %w", err)
+	}
+
+	if resList.Processed != nil {
+		for _, item := range resList.Processed.Errors {
+			return nil, fmt.Errorf("failed to update group %s: %s", item.Item, item.Error)
+		}
+	}
+
+	return g.Get(ctx, entry.Name)
+}
+
+// Delete removes a group by its name
+func (g groups) Delete(ctx context.Context, name string) (err error) {
+	group, err := g.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrorGroupNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed looking up group %s for deletion: %w", name, err)
+	}
+
+	m := Mutation{Operation: AuditGroupDelete, Target: name, Before: *group}
+	defer func() {
+		g.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := g.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	res, done, err := g.client.mutate(ctx, http.MethodDelete, "/api/groups/"+url.PathEscape(group.Name), nil, func(ctx context.Context) (bool, error) {
+		current, err := g.lookup(ctx, group.Name)
+		return current == nil, err
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newGroupAPIError(res, b)
+	}
+
+	return nil
+}
+
+// lookup returns the group called name, or nil if there is none.
+func (g groups) lookup(ctx context.Context, name string) (*Group, error) {
+	group, err := g.Get(ctx, name)
+	if errors.Is(err, ErrorGroupNotFound) {
+		return nil, nil
+	}
+
+	return group, err
+}
@@ -0,0 +1,97 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups_Create(t *testing.T) {
+	isUnit(t)
+
+	var created groupRequest
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/groups":
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&created))
+			return newHTTPResponse(http.StatusCreated, `{"groups":[],"processed":{"success":[{"item":"kids"}],"errors":[]}}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids","comment":"Kids devices","enabled":false,"date_added":1700000000}]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	group, err := client.Groups.Create(context.Background(), GroupEntry{Name: "kids", Comment: "Kids devices", Disabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, groupRequest{Name: "kids", Comment: "Kids devices", Enabled: false}, created)
+	assert.Equal(t, int64(4), group.ID)
+	assert.False(t, group.Enabled)
+
+	_, err = client.Groups.Get(context.Background(), "guests")
+	assert.ErrorIs(t, err, ErrorGroupNotFound)
+}
+
+func TestGroups_Update(t *testing.T) {
+	isUnit(t)
+
+	var (
+		updatePath string
+		update     groupUpdateRequest
+	)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPut:
+			updatePath = req.URL.EscapedPath()
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&update))
+			return newHTTPResponse(http.StatusOK, `{"groups":[],"processed":{"success":[{"item":"kids"}],"errors":[]}}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids room","comment":"Kids devices","enabled":true}]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	group, err := client.Groups.Update(context.Background(), "kids room", GroupEntry{Disabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, "/api/groups/kids%20room", updatePath)
+	assert.Equal(t, groupUpdateRequest{Name: "kids room", Comment: "", Enabled: false}, update)
+	assert.Equal(t, int64(4), group.ID)
+
+	_, err = client.Groups.Update(context.Background(), "guests", GroupEntry{})
+	assert.ErrorIs(t, err, ErrorGroupNotFound)
+}
+
+func TestGroups_Delete(t *testing.T) {
+	isUnit(t)
+
+	var deleted []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodDelete:
+			deleted = append(deleted, req.URL.EscapedPath())
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids room","enabled":true}]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	require.NoError(t, client.Groups.Delete(context.Background(), "kids room"))
+	require.NoError(t, client.Groups.Delete(context.Background(), "guests"))
+	assert.Equal(t, []string{"/api/groups/kids%20room"}, deleted)
+}
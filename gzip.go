@@ -0,0 +1,68 @@
+package pihole
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipMinSize is the smallest request body worth compressing.
+const gzipMinSize = 1024
+
+func (c *Client) shouldCompress(body []byte) bool {
+	return c.gzip && len(body) >= gzipMinSize && !c.gzipRequestsRejected.Load()
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gzipBody closes both the decompressor and the underlying response body.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// decompressResponse transparently decompresses gzip-encoded responses. net/http only
+// does this itself when it added the Accept-Encoding header, which is not the case for
+// custom transports or when the client asks for gzip explicitly.
+func decompressResponse(res *http.Response) error {
+	if !strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") || res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(res.Body)
+	if err == io.EOF {
+		// An empty body carries no gzip header.
+		res.Header.Del("Content-Encoding")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decompress response body: %w", err)
+	}
+
+	res.Body = gzipBody{Reader: reader, body: res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package pihole
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GzipDecompressesResponses(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+
+		compressed, err := gzipBytes([]byte(`{"count":3}`))
+		require.NoError(t, err)
+
+		res := newHTTPResponse(http.StatusOK, string(compressed))
+		res.Header.Set("Content-Encoding", "gzip")
+		return res, nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+		Gzip:       true,
+	})
+	require.NoError(t, err)
+
+	count, err := client.Messages.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestClient_GzipObservesThrottlingAfterDecompression(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		compressed, err := gzipBytes([]byte(`{"error":{"key":"api_seats_exceeded","message":"API seats exceeded","hint":null}}`))
+		require.NoError(t, err)
+
+		res := newHTTPResponse(http.StatusTooManyRequests, string(compressed))
+		res.Header.Set("Content-Encoding", "gzip")
+		return res, nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+		Gzip:       true,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Stats.QueryTypes(context.Background())
+	var apiErr *StatsAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, seatsExceededKey, apiErr.Key)
+
+	client.limits.mu.Lock()
+	defer client.limits.mu.Unlock()
+	assert.True(t, client.limits.seatsExceeded)
+}
+
+func TestClient_GzipFallsBackToUncompressedRequests(t *testing.T) {
+	isUnit(t)
+
+	var encodings []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		encodings = append(encodings, req.Header.Get("Content-Encoding"))
+
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			if len(encodings) == 1 {
+				zr, err := gzip.NewReader(req.Body)
+				require.NoError(t, err)
+				body = zr
+			} else {
+				return newHTTPResponse(http.StatusUnsupportedMediaType, ``), nil
+			}
+		}
+
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(body).Decode(&payload))
+		assert.Len(t, payload["comment"], gzipMinSize)
+
+		return newHTTPResponse(http.StatusOK, `{}`), nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+		Gzip:       true,
+	})
+	require.NoError(t, err)
+
+	payload := map[string]string{"comment": strings.Repeat("a", gzipMinSize)}
+
+	for i := 0; i < 3; i++ {
+		res, err := client.Post(context.Background(), "/api/domains/deny/exact", payload)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+
+	// Accepted, then rejected and retried uncompressed, then no longer compressed.
+	assert.Equal(t, []string{"gzip", "gzip", "", ""}, encodings)
+}
@@ -0,0 +1,119 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Mutation describes a create, update or delete made through the client.
+type Mutation struct {
+	Operation AuditOperation
+
+	// Target identifies what the call acts on, such as a domain name or an action path.
+	Target string
+
+	// Before is the value being replaced or removed, and After the value being
+	// written. Before a call After is the requested value; after it, the value the
+	// server returned. Either is nil where the client does not know it.
+	Before any
+	After  any
+}
+
+// BeforeMutationHook is called before a mutation is sent. Returning an error vetoes
+// the mutation, which then fails with a *MutationVetoedError wrapping it.
+type BeforeMutationHook func(ctx context.Context, m Mutation) error
+
+// AfterMutationHook is called once a mutation has finished, with the error it
+// returned, including vetoes.
+type AfterMutationHook func(ctx context.Context, m Mutation, err error)
+
+var ErrMutationVetoed = errors.New("mutation vetoed")
+
+// MutationVetoedError is returned when a BeforeMutationHook rejects a mutation.
+type MutationVetoedError struct {
+	Mutation Mutation
+	Err      error
+}
+
+func (e *MutationVetoedError) Error() string {
+	return fmt.Sprintf("%s %s vetoed: %s", e.Mutation.Operation, e.Mutation.Target, e.Err)
+}
+
+func (e *MutationVetoedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MutationVetoedError) Is(target error) bool {
+	return target == ErrMutationVetoed
+}
+
+// mutationHooks holds the hooks registered on a client.
+type mutationHooks struct {
+	mu     sync.RWMutex
+	before []BeforeMutationHook
+	after  []AfterMutationHook
+
+	slowRequest []SlowRequestHook
+}
+
+func (h *mutationHooks) clone() *mutationHooks {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return &mutationHooks{
+		before: slices.Clone(h.before),
+		after:  slices.Clone(h.after),
+
+		slowRequest: slices.Clone(h.slowRequest),
+	}
+}
+
+// OnBeforeMutation registers a hook called before every create, update or delete, in
+// registration order. The first hook returning an error vetoes the mutation.
+func (c *Client) OnBeforeMutation(hook BeforeMutationHook) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+
+	c.hooks.before = append(c.hooks.before, hook)
+}
+
+// OnAfterMutation registers a hook called after every create, update or delete, in
+// registration order.
+func (c *Client) OnAfterMutation(hook AfterMutationHook) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+
+	c.hooks.after = append(c.hooks.after, hook)
+}
+
+// beforeMutation runs the before hooks, returning a *MutationVetoedError for the
+// first one that rejects m.
+func (c *Client) beforeMutation(ctx context.Context, m Mutation) error {
+	c.hooks.mu.RLock()
+	hooks := c.hooks.before
+	c.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, m); err != nil {
+			return &MutationVetoedError{Mutation: m, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// afterMutation records the outcome of m with the audit sink and the after hooks.
+func (c *Client) afterMutation(ctx context.Context, m Mutation, err error) {
+	c.audit(ctx, m, err)
+
+	c.hooks.mu.RLock()
+	hooks := c.hooks.after
+	c.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ctx, m, err)
+	}
+}
@@ -0,0 +1,82 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutationHooks_BeforeHookVetoes(t *testing.T) {
+	isUnit(t)
+
+	var puts int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.corp.internal"]}}}`), nil
+		case req.Method == http.MethodPut:
+			puts++
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	errOutsideZone := errors.New("records must be below corp.internal")
+	client.OnBeforeMutation(func(_ context.Context, m Mutation) error {
+		if m.Operation == AuditLocalDNSCreate && !strings.HasSuffix(m.Target, ".corp.internal") {
+			return errOutsideZone
+		}
+		return nil
+	})
+
+	var after []error
+	client.OnAfterMutation(func(_ context.Context, m Mutation, err error) {
+		after = append(after, err)
+	})
+
+	_, err = client.LocalDNS.Create(context.Background(), "nas.example.com", "10.0.0.1")
+	require.ErrorIs(t, err, ErrMutationVetoed)
+	require.ErrorIs(t, err, errOutsideZone)
+
+	var vetoErr *MutationVetoedError
+	require.ErrorAs(t, err, &vetoErr)
+	assert.Equal(t, "nas.example.com", vetoErr.Mutation.Target)
+	assert.Equal(t, DNSRecord{Domain: "nas.example.com", IP: "10.0.0.1"}, vetoErr.Mutation.After)
+	assert.Zero(t, puts)
+
+	record, err := client.LocalDNS.Create(context.Background(), "nas.corp.internal", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.corp.internal", record.Domain)
+	assert.Equal(t, 1, puts)
+
+	require.Len(t, after, 2)
+	assert.ErrorIs(t, after[0], ErrMutationVetoed)
+	assert.NoError(t, after[1])
+}
+
+func TestMutationHooks_ClonesDoNotShareRegistrations(t *testing.T) {
+	isUnit(t)
+
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test"})
+	require.NoError(t, err)
+
+	client.OnBeforeMutation(func(context.Context, Mutation) error { return nil })
+
+	clone, err := client.With()
+	require.NoError(t, err)
+	clone.OnBeforeMutation(func(context.Context, Mutation) error {
+		return errors.New("read-only clone")
+	})
+
+	assert.NoError(t, client.beforeMutation(context.Background(), Mutation{Operation: AuditAction}))
+	assert.ErrorIs(t, clone.beforeMutation(context.Background(), Mutation{Operation: AuditAction}), ErrMutationVetoed)
+}
@@ -0,0 +1,521 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Info interface {
+	// Sensors returns the temperature sensors and the configured hot limit.
+	Sensors(ctx context.Context) (*Sensors, error)
+
+	// Host returns the host's operating system and hardware details.
+	Host(ctx context.Context) (*Host, error)
+
+	// FTL returns FTL process metrics and database counters.
+	FTL(ctx context.Context) (*FTLInfo, error)
+
+	// Login reports whether the server requires authentication and 2FA, without
+	// authenticating.
+	Login(ctx context.Context) (*LoginInfo, error)
+
+	// WhoAmI returns how the server sees this client's request, including its source address.
+	WhoAmI(ctx context.Context) (*ClientIdentity, error)
+
+	// Gravity returns the size and last update time of the gravity database.
+	Gravity(ctx context.Context) (*GravityInfo, error)
+
+	// GravityStale reports whether gravity was last updated more than maxAge ago.
+	GravityStale(ctx context.Context, maxAge time.Duration) (bool, error)
+}
+
+type info struct {
+	client *Client
+}
+
+// Sensors reports temperatures in Unit, which follows the webserver's configured
+// temperature unit (C, F or K).
+type Sensors struct {
+	CPUTemp  float64
+	HotLimit float64
+	Unit     string
+	Zones    []SensorZone
+}
+
+type SensorZone struct {
+	Name   string
+	Path   string
+	Source string
+	Temps  []SensorTemp
+}
+
+// SensorTemp is a single temperature reading. Max and Crit are nil when the sensor
+// does not report them.
+type SensorTemp struct {
+	Name  string
+	Value float64
+	Max   *float64
+	Crit  *float64
+}
+
+// Hot reports whether the CPU temperature has reached the hot limit.
+func (s Sensors) Hot() bool {
+	return s.HotLimit > 0 && s.CPUTemp >= s.HotLimit
+}
+
+// Host describes the machine Pi-hole runs on. Fields the host does not expose,
+// such as DMI data on a Raspberry Pi, are left empty.
+type Host struct {
+	Uname HostUname
+	Model string
+	DMI   HostDMI
+}
+
+type HostUname struct {
+	DomainName string
+	Machine    string
+	NodeName   string
+	Release    string
+	SysName    string
+	Version    string
+}
+
+type HostDMI struct {
+	BIOSVendor     string
+	BoardName      string
+	BoardVendor    string
+	BoardVersion   string
+	ProductName    string
+	ProductFamily  string
+	ProductVersion string
+	SysVendor      string
+}
+
+type hostResponse struct {
+	Host hostHostResponse `json:"host"`
+}
+
+type hostHostResponse struct {
+	Uname hostUnameResponse `json:"uname"`
+	Model string            `json:"model"`
+	DMI   hostDMIResponse   `json:"dmi"`
+}
+
+type hostUnameResponse struct {
+	DomainName string `json:"domainname"`
+	Machine    string `json:"machine"`
+	NodeName   string `json:"nodename"`
+	Release    string `json:"release"`
+	SysName    string `json:"sysname"`
+	Version    string `json:"version"`
+}
+
+type hostDMIResponse struct {
+	BIOS struct {
+		Vendor string `json:"vendor"`
+	} `json:"bios"`
+	Board struct {
+		Name    string `json:"name"`
+		Vendor  string `json:"vendor"`
+		Version string `json:"version"`
+	} `json:"board"`
+	Product struct {
+		Name    string `json:"name"`
+		Family  string `json:"family"`
+		Version string `json:"version"`
+	} `json:"product"`
+	Sys struct {
+		Vendor string `json:"vendor"`
+	} `json:"sys"`
+}
+
+func (res hostResponse) toHost() *Host {
+	host := res.Host
+	return &Host{
+		Uname: HostUname(host.Uname),
+		Model: host.Model,
+		DMI: HostDMI{
+			BIOSVendor:     host.DMI.BIOS.Vendor,
+			BoardName:      host.DMI.Board.Name,
+			BoardVendor:    host.DMI.Board.Vendor,
+			BoardVersion:   host.DMI.Board.Version,
+			ProductName:    host.DMI.Product.Name,
+			ProductFamily:  host.DMI.Product.Family,
+			ProductVersion: host.DMI.Product.Version,
+			SysVendor:      host.DMI.Sys.Vendor,
+		},
+	}
+}
+
+type FTLInfo struct {
+	PID              int
+	Uptime           time.Duration
+	MemoryPercent    float64
+	CPUPercent       float64
+	PrivacyLevel     int
+	AllowDestructive bool
+	Clients          FTLClients
+	Database         FTLDatabase
+}
+
+// StartedAt returns when FTL started, given the time the info was fetched.
+func (f FTLInfo) StartedAt(now time.Time) time.Time {
+	return now.Add(-f.Uptime)
+}
+
+type FTLClients struct {
+	Total  int
+	Active int
+}
+
+// FTLDatabase holds the number of entries in the gravity database.
+type FTLDatabase struct {
+	Gravity        int
+	Groups         int
+	Lists          int
+	Clients        int
+	AllowedDomains FTLDomainCount
+	DeniedDomains  FTLDomainCount
+	AllowedRegex   FTLDomainCount
+	DeniedRegex    FTLDomainCount
+}
+
+// ActivePercentage returns the share of known clients that were active in the last
+// 24 hours, between 0 and 100.
+func (c FTLClients) ActivePercentage() float64 {
+	return percentage(c.Active, c.Total)
+}
+
+type FTLDomainCount struct {
+	Total   int
+	Enabled int
+}
+
+// Disabled returns the number of disabled entries.
+func (c FTLDomainCount) Disabled() int {
+	return c.Total - c.Enabled
+}
+
+type ftlResponse struct {
+	FTL ftlFTLResponse `json:"ftl"`
+}
+
+type ftlFTLResponse struct {
+	PID              int                 `json:"pid"`
+	Uptime           float64             `json:"uptime"`
+	MemoryPercent    float64             `json:"%mem"`
+	CPUPercent       float64             `json:"%cpu"`
+	PrivacyLevel     int                 `json:"privacy_level"`
+	AllowDestructive bool                `json:"allow_destructive"`
+	Clients          ftlClientsResponse  `json:"clients"`
+	Database         ftlDatabaseResponse `json:"database"`
+}
+
+type ftlClientsResponse struct {
+	Total  int `json:"total"`
+	Active int `json:"active"`
+}
+
+type ftlDatabaseResponse struct {
+	Gravity int                    `json:"gravity"`
+	Groups  int                    `json:"groups"`
+	Lists   int                    `json:"lists"`
+	Clients int                    `json:"clients"`
+	Domains ftlDomainListsResponse `json:"domains"`
+	Regex   ftlDomainListsResponse `json:"regex"`
+}
+
+type ftlDomainListsResponse struct {
+	Allowed ftlDomainCountResponse `json:"allowed"`
+	Denied  ftlDomainCountResponse `json:"denied"`
+}
+
+type ftlDomainCountResponse struct {
+	Total   int `json:"total"`
+	Enabled int `json:"enabled"`
+}
+
+func (res ftlResponse) toFTLInfo() *FTLInfo {
+	ftl := res.FTL
+	db := ftl.Database
+
+	return &FTLInfo{
+		PID:              ftl.PID,
+		Uptime:           time.Duration(ftl.Uptime * float64(time.Millisecond)),
+		MemoryPercent:    ftl.MemoryPercent,
+		CPUPercent:       ftl.CPUPercent,
+		PrivacyLevel:     ftl.PrivacyLevel,
+		AllowDestructive: ftl.AllowDestructive,
+		Clients:          FTLClients(ftl.Clients),
+		Database: FTLDatabase{
+			Gravity:        db.Gravity,
+			Groups:         db.Groups,
+			Lists:          db.Lists,
+			Clients:        db.Clients,
+			AllowedDomains: FTLDomainCount(db.Domains.Allowed),
+			DeniedDomains:  FTLDomainCount(db.Domains.Denied),
+			AllowedRegex:   FTLDomainCount(db.Regex.Allowed),
+			DeniedRegex:    FTLDomainCount(db.Regex.Denied),
+		},
+	}
+}
+
+// LoginInfo describes what an unauthenticated client needs to log in.
+type LoginInfo struct {
+	// AuthRequired is false when the server has no password set.
+	AuthRequired bool
+
+	// TOTP is true when two-factor authentication is enabled.
+	TOTP bool
+
+	// HTTPSPort is the port the webserver serves HTTPS on, or 0 when HTTPS is disabled.
+	HTTPSPort int
+
+	// DNS reports whether FTL's DNS resolver is running.
+	DNS bool
+}
+
+type loginInfoResponse struct {
+	HTTPSPort int  `json:"https_port"`
+	DNS       bool `json:"dns"`
+}
+
+// ClientIdentity is the request as received by Pi-hole. RemoteAddr is the address
+// Pi-hole sees after any NAT or proxies in between.
+type ClientIdentity struct {
+	RemoteAddr  string
+	HTTPVersion string
+	Method      string
+	Headers     http.Header
+}
+
+type clientIdentityResponse struct {
+	RemoteAddr  string                         `json:"remote_addr"`
+	HTTPVersion string                         `json:"http_version"`
+	Method      string                         `json:"method"`
+	Headers     []clientIdentityHeaderResponse `json:"headers"`
+}
+
+type clientIdentityHeaderResponse struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (res clientIdentityResponse) toClientIdentity() *ClientIdentity {
+	headers := make(http.Header, len(res.Headers))
+	for _, header := range res.Headers {
+		headers.Add(header.Name, header.Value)
+	}
+
+	return &ClientIdentity{
+		RemoteAddr:  res.RemoteAddr,
+		HTTPVersion: res.HTTPVersion,
+		Method:      res.Method,
+		Headers:     headers,
+	}
+}
+
+// GravityInfo describes the compiled gravity database. FTL does not report the size
+// of the gravity.db file itself, so size is given as the number of domains.
+type GravityInfo struct {
+	// Domains is the number of unique domains being blocked.
+	Domains int
+
+	// LastUpdate is when gravity last completed successfully, or zero if it never did.
+	LastUpdate time.Time
+}
+
+// Age returns how long ago gravity was last updated, relative to now.
+func (g GravityInfo) Age(now time.Time) time.Duration {
+	if g.LastUpdate.IsZero() {
+		return 0
+	}
+	return now.Sub(g.LastUpdate)
+}
+
+type gravitySummaryResponse struct {
+	Gravity gravityGravityResponse `json:"gravity"`
+}
+
+type gravityGravityResponse struct {
+	DomainsBeingBlocked int   `json:"domains_being_blocked"`
+	LastUpdate          int64 `json:"last_update"`
+}
+
+func (res gravitySummaryResponse) toGravityInfo() *GravityInfo {
+	gravity := &GravityInfo{Domains: res.Gravity.DomainsBeingBlocked}
+	if res.Gravity.LastUpdate > 0 {
+		gravity.LastUpdate = time.Unix(res.Gravity.LastUpdate, 0)
+	}
+
+	return gravity
+}
+
+type sensorsResponse struct {
+	Sensors sensorsSensorsResponse `json:"sensors"`
+}
+
+type sensorsSensorsResponse struct {
+	List     []sensorZoneResponse `json:"list"`
+	CPUTemp  float64              `json:"cpu_temp"`
+	HotLimit float64              `json:"hot_limit"`
+	Unit     string               `json:"unit"`
+}
+
+type sensorZoneResponse struct {
+	Name   string               `json:"name"`
+	Path   string               `json:"path"`
+	Source string               `json:"source"`
+	Temps  []sensorTempResponse `json:"temps"`
+}
+
+type sensorTempResponse struct {
+	Name   string   `json:"name"`
+	Sensor string   `json:"sensor"`
+	Value  float64  `json:"value"`
+	Max    *float64 `json:"max"`
+	Crit   *float64 `json:"crit"`
+}
+
+func (res sensorsResponse) toSensors() *Sensors {
+	sensors := &Sensors{
+		CPUTemp:  res.Sensors.CPUTemp,
+		HotLimit: res.Sensors.HotLimit,
+		Unit:     res.Sensors.Unit,
+		Zones:    make([]SensorZone, 0, len(res.Sensors.List)),
+	}
+
+	for _, zone := range res.Sensors.List {
+		temps := make([]SensorTemp, 0, len(zone.Temps))
+		for _, temp := range zone.Temps {
+			name := temp.Name
+			if name == "" {
+				name = temp.Sensor
+			}
+			temps = append(temps, SensorTemp{Name: name, Value: temp.Value, Max: temp.Max, Crit: temp.Crit})
+		}
+
+		sensors.Zones = append(sensors.Zones, SensorZone{
+			Name:   zone.Name,
+			Path:   zone.Path,
+			Source: zone.Source,
+			Temps:  temps,
+		})
+	}
+
+	return sensors
+}
+
+// Sensors returns temperature sensor readings
+func (i info) Sensors(ctx context.Context) (*Sensors, error) {
+	var resSensors sensorsResponse
+	if err := i.get(ctx, "/api/info/sensors", &resSensors); err != nil {
+		return nil, fmt.Errorf("failed to fetch sensors: %w", err)
+	}
+
+	return resSensors.toSensors(), nil
+}
+
+// Host returns host hardware and OS details
+func (i info) Host(ctx context.Context) (*Host, error) {
+	var resHost hostResponse
+	if err := i.get(ctx, "/api/info/host", &resHost); err != nil {
+		return nil, fmt.Errorf("failed to fetch host info: %w", err)
+	}
+
+	return resHost.toHost(), nil
+}
+
+// FTL returns FTL process metrics
+func (i info) FTL(ctx context.Context) (*FTLInfo, error) {
+	var resFTL ftlResponse
+	if err := i.get(ctx, "/api/info/ftl", &resFTL); err != nil {
+		return nil, fmt.Errorf("failed to fetch FTL info: %w", err)
+	}
+
+	return resFTL.toFTLInfo(), nil
+}
+
+// Login returns the authentication requirements of the server
+func (i info) Login(ctx context.Context) (*LoginInfo, error) {
+	var resLogin loginInfoResponse
+	if err := i.get(ctx, "/api/info/login", &resLogin); err != nil {
+		return nil, fmt.Errorf("failed to fetch login info: %w", err)
+	}
+
+	// An anonymous GET /api/auth reports a valid session only when no password is set.
+	res, err := i.client.Get(ctx, "/api/auth")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch auth status: %w", err)
+	}
+	defer res.Body.Close()
+
+	var resAuth sessionResponse
+	if err := json.NewDecoder(res.Body).Decode(&resAuth); err != nil {
+		return nil, fmt.Errorf("failed to parse auth status body: %w", err)
+	}
+
+	return &LoginInfo{
+		AuthRequired: !resAuth.Session.Valid,
+		TOTP:         resAuth.Session.TOTP,
+		HTTPSPort:    resLogin.HTTPSPort,
+		DNS:          resLogin.DNS,
+	}, nil
+}
+
+// WhoAmI returns the client identity seen by the server
+func (i info) WhoAmI(ctx context.Context) (*ClientIdentity, error) {
+	var resClient clientIdentityResponse
+	if err := i.get(ctx, "/api/info/client", &resClient); err != nil {
+		return nil, fmt.Errorf("failed to fetch client info: %w", err)
+	}
+
+	return resClient.toClientIdentity(), nil
+}
+
+// Gravity returns gravity database statistics
+func (i info) Gravity(ctx context.Context) (*GravityInfo, error) {
+	var resSummary gravitySummaryResponse
+	if err := i.get(ctx, "/api/stats/summary", &resSummary); err != nil {
+		return nil, fmt.Errorf("failed to fetch gravity info: %w", err)
+	}
+
+	return resSummary.toGravityInfo(), nil
+}
+
+// GravityStale reports whether gravity is older than maxAge or has never completed
+func (i info) GravityStale(ctx context.Context, maxAge time.Duration) (bool, error) {
+	gravity, err := i.Gravity(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if gravity.LastUpdate.IsZero() {
+		return true, nil
+	}
+
+	return gravity.Age(time.Now()) > maxAge, nil
+}
+
+func (i info) get(ctx context.Context, path string, v interface{}) error {
+	res, err := i.client.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return newInfoAPIError(res, b)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse info body: %w", err)
+	}
+
+	return nil
+}
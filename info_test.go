@@ -0,0 +1,179 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInfoTestClient(t *testing.T, responses map[string]string) *Client {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if body, ok := responses[req.URL.Path]; ok && req.Method == http.MethodGet {
+			return newHTTPResponse(http.StatusOK, body), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, `{"error":{"key":"not_found","message":"Not found","hint":null}}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestInfo_Sensors(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, map[string]string{
+		"/api/info/sensors": `{"sensors":{"list":[{"name":"cpu_thermal","path":"thermal_zone0","source":"/sys/class/thermal/thermal_zone0","temps":[{"name":null,"sensor":"temp1","value":61.2,"max":null,"crit":85.0}]}],"cpu_temp":61.2,"hot_limit":60,"unit":"C"}}`,
+	})
+
+	sensors, err := client.Info.Sensors(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "C", sensors.Unit)
+	assert.True(t, sensors.Hot())
+	require.Len(t, sensors.Zones, 1)
+	assert.Equal(t, "cpu_thermal", sensors.Zones[0].Name)
+	require.Len(t, sensors.Zones[0].Temps, 1)
+
+	temp := sensors.Zones[0].Temps[0]
+	assert.Equal(t, "temp1", temp.Name)
+	assert.InDelta(t, 61.2, temp.Value, 0.001)
+	assert.Nil(t, temp.Max)
+	require.NotNil(t, temp.Crit)
+	assert.InDelta(t, 85.0, *temp.Crit, 0.001)
+}
+
+func TestInfo_Host(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, map[string]string{
+		"/api/info/host": `{"host":{"uname":{"domainname":"(none)","machine":"aarch64","nodename":"pihole","release":"6.6.31","sysname":"Linux","version":"#1 SMP PREEMPT"},"model":"Raspberry Pi 4 Model B Rev 1.4","dmi":{"bios":{"vendor":null},"board":{"name":null,"vendor":null,"version":null},"product":{"name":null,"family":null,"version":null},"sys":{"vendor":"Raspberry Pi Foundation"}}}}`,
+	})
+
+	host, err := client.Info.Host(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "pihole", host.Uname.NodeName)
+	assert.Equal(t, "aarch64", host.Uname.Machine)
+	assert.Equal(t, "Raspberry Pi 4 Model B Rev 1.4", host.Model)
+	assert.Equal(t, "Raspberry Pi Foundation", host.DMI.SysVendor)
+	assert.Empty(t, host.DMI.BoardName)
+}
+
+func TestInfo_FTL(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, map[string]string{
+		"/api/info/ftl": `{"ftl":{"database":{"gravity":150000,"groups":3,"lists":5,"clients":8,"domains":{"allowed":{"total":4,"enabled":3},"denied":{"total":2,"enabled":2}},"regex":{"allowed":{"total":1,"enabled":1},"denied":{"total":6,"enabled":5}}},"privacy_level":0,"clients":{"total":12,"active":7},"pid":4242,"uptime":3600000,"%mem":2.5,"%cpu":0.7,"allow_destructive":true}}`,
+	})
+
+	ftl, err := client.Info.FTL(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4242, ftl.PID)
+	assert.Equal(t, time.Hour, ftl.Uptime)
+	assert.InDelta(t, 2.5, ftl.MemoryPercent, 0.001)
+	assert.InDelta(t, 0.7, ftl.CPUPercent, 0.001)
+	assert.Equal(t, FTLClients{Total: 12, Active: 7}, ftl.Clients)
+	assert.Equal(t, 150000, ftl.Database.Gravity)
+	assert.Equal(t, FTLDomainCount{Total: 6, Enabled: 5}, ftl.Database.DeniedRegex)
+	assert.True(t, ftl.AllowDestructive)
+
+	now := time.Unix(1700003600, 0)
+	assert.Equal(t, time.Unix(1700000000, 0), ftl.StartedAt(now))
+	assert.InDelta(t, 58.333, ftl.Clients.ActivePercentage(), 0.001)
+	assert.Equal(t, 1, ftl.Database.DeniedRegex.Disabled())
+	assert.Zero(t, FTLClients{}.ActivePercentage())
+}
+
+func TestInfo_LoginIsAnonymous(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Empty(t, req.Header.Get(authHeader))
+		assert.Empty(t, req.Header.Get(apiKeyHeader))
+
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/info/login":
+			return newHTTPResponse(http.StatusOK, `{"https_port":443,"dns":true}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/auth":
+			return newHTTPResponse(http.StatusUnauthorized, `{"session":{"valid":false,"totp":true,"sid":null,"validity":-1,"message":"no valid session"}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		APIToken:   "token",
+		SessionID:  "test",
+		HttpClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	login, err := client.Info.Login(context.Background())
+	require.NoError(t, err)
+	assert.True(t, login.AuthRequired)
+	assert.True(t, login.TOTP)
+	assert.Equal(t, 443, login.HTTPSPort)
+	assert.True(t, login.DNS)
+}
+
+func TestInfo_WhoAmI(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, map[string]string{
+		"/api/info/client": `{"remote_addr":"10.0.0.7","http_version":"1.1","method":"GET","headers":[{"name":"user-agent","value":"go-pihole"},{"name":"X-Forwarded-For","value":"192.168.1.20"}]}`,
+	})
+
+	identity, err := client.Info.WhoAmI(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.7", identity.RemoteAddr)
+	assert.Equal(t, "1.1", identity.HTTPVersion)
+	assert.Equal(t, "192.168.1.20", identity.Headers.Get("X-Forwarded-For"))
+	assert.Equal(t, "go-pihole", identity.Headers.Get("User-Agent"))
+}
+
+func TestInfo_GravityStale(t *testing.T) {
+	isUnit(t)
+
+	lastUpdate := time.Now().Add(-36 * time.Hour).Unix()
+	client := newInfoTestClient(t, map[string]string{
+		"/api/stats/summary": fmt.Sprintf(`{"queries":{"total":10},"gravity":{"domains_being_blocked":123456,"last_update":%d}}`, lastUpdate),
+	})
+
+	gravity, err := client.Info.Gravity(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 123456, gravity.Domains)
+	assert.Equal(t, lastUpdate, gravity.LastUpdate.Unix())
+
+	stale, err := client.Info.GravityStale(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, stale)
+
+	stale, err = client.Info.GravityStale(context.Background(), 48*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	never := newInfoTestClient(t, map[string]string{
+		"/api/stats/summary": `{"gravity":{"domains_being_blocked":0,"last_update":0}}`,
+	})
+
+	stale, err = never.Info.GravityStale(context.Background(), 48*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, stale)
+}
+
+func TestInfo_ReturnsAPIError(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, nil)
+
+	_, err := client.Info.Sensors(context.Background())
+	var apiErr *InfoAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
@@ -0,0 +1,86 @@
+// Package keyring reads Pi-hole credentials from the operating system's keychain, so
+// CLI tools do not need admin passwords in shell history or plaintext config. It uses
+// the platform's own tools: security(1) on macOS and secret-tool(1) from libsecret on
+// Linux. Other platforms return ErrUnsupported.
+//
+// Store a password with the platform tool first, for example:
+//
+//	security add-generic-password -s pihole -a https://pi.hole -w
+//	secret-tool store --label="Pi-hole" service pihole account https://pi.hole
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+)
+
+// DefaultService is the keychain service Load looks passwords up under.
+const DefaultService = "pihole"
+
+var (
+	ErrNotFound    = errors.New("keyring: secret not found")
+	ErrUnsupported = errors.New("keyring: unsupported platform")
+)
+
+// run executes a lookup command and returns its standard output. It is replaced in
+// tests.
+var run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFound(exitErr.ExitCode(), stderr.String()) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("keyring: %s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// Get returns the secret stored for account under service.
+func Get(ctx context.Context, service string, account string) (string, error) {
+	name, args, err := lookupCommand(service, account)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := run(ctx, name, args...)
+	if err != nil {
+		return "", err
+	}
+
+	secret := strings.TrimRight(string(out), "\r\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+// Load fills config.Password from the keychain entry for config.BaseURL under
+// DefaultService, unless the config already carries a password or API token.
+func Load(ctx context.Context, config *pihole.Config) error {
+	if config.Password != "" || config.PasswordFile != "" || config.APIToken != "" || config.APITokenFile != "" || config.APIKey != "" {
+		return nil
+	}
+
+	password, err := Get(ctx, DefaultService, config.BaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to load password for %s: %w", config.BaseURL, err)
+	}
+	config.Password = password
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package keyring
+
+// errSecItemNotFound is the exit status of security(1) when no item matches.
+const errSecItemNotFound = 44
+
+func lookupCommand(service string, account string) (string, []string, error) {
+	return "security", []string{"find-generic-password", "-s", service, "-a", account, "-w"}, nil
+}
+
+func isNotFound(exitCode int, stderr string) bool {
+	return exitCode == errSecItemNotFound
+}
@@ -0,0 +1,10 @@
+package keyring
+
+func lookupCommand(service string, account string) (string, []string, error) {
+	return "secret-tool", []string{"lookup", "service", service, "account", account}, nil
+}
+
+// secret-tool exits with status 1 and prints nothing when no item matches.
+func isNotFound(exitCode int, stderr string) bool {
+	return exitCode == 1 && stderr == ""
+}
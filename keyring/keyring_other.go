@@ -0,0 +1,11 @@
+//go:build !darwin && !linux
+
+package keyring
+
+func lookupCommand(service string, account string) (string, []string, error) {
+	return "", nil, ErrUnsupported
+}
+
+func isNotFound(exitCode int, stderr string) bool {
+	return false
+}
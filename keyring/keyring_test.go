@@ -0,0 +1,50 @@
+//go:build darwin || linux
+
+package keyring
+
+import (
+	"context"
+	"testing"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubRun(t *testing.T, secrets map[string]string) {
+	t.Helper()
+
+	original := run
+	t.Cleanup(func() { run = original })
+
+	run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		wantName, _, err := lookupCommand("", "")
+		require.NoError(t, err)
+		require.Equal(t, wantName, name)
+
+		for i, arg := range args {
+			if (arg == "-a" || arg == "account") && i+1 < len(args) {
+				if secret, ok := secrets[args[i+1]]; ok {
+					return []byte(secret + "\n"), nil
+				}
+			}
+		}
+
+		return nil, ErrNotFound
+	}
+}
+
+func TestLoad(t *testing.T) {
+	stubRun(t, map[string]string{"https://pi.hole": "s3cret"})
+
+	config := pihole.Config{BaseURL: "https://pi.hole"}
+	require.NoError(t, Load(context.Background(), &config))
+	assert.Equal(t, "s3cret", config.Password)
+
+	config = pihole.Config{BaseURL: "https://pi.hole", APIToken: "token"}
+	require.NoError(t, Load(context.Background(), &config))
+	assert.Empty(t, config.Password)
+
+	config = pihole.Config{BaseURL: "https://other.lan"}
+	assert.ErrorIs(t, Load(context.Background(), &config), ErrNotFound)
+}
@@ -0,0 +1,168 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const seatsExceededKey = "api_seats_exceeded"
+
+// Limits reports how close the client is to Pi-hole's API session and rate limits.
+type Limits struct {
+	// Sessions is the number of valid API sessions on the server, including this one.
+	Sessions int
+
+	// MaxSessions is the server's webserver.api.max_sessions setting.
+	MaxSessions int
+
+	// SeatsExceeded is set when the last throttled response was an api_seats_exceeded
+	// error, meaning every session seat was taken.
+	SeatsExceeded bool
+
+	// LastThrottled is when the server last answered this client with 429 Too Many
+	// Requests, or zero if it never did.
+	LastThrottled time.Time
+
+	// RetryAfter is the delay the server requested in its last Retry-After header.
+	RetryAfter time.Duration
+}
+
+// Remaining returns the number of sessions that can still be opened.
+func (l Limits) Remaining() int {
+	return max(l.MaxSessions-l.Sessions, 0)
+}
+
+// Exhausted reports whether no further sessions can be opened.
+func (l Limits) Exhausted() bool {
+	return l.MaxSessions > 0 && l.Remaining() == 0
+}
+
+// Throttled reports whether the server asked the client to back off and the
+// requested delay has not passed yet at now.
+func (l Limits) Throttled(now time.Time) bool {
+	if l.LastThrottled.IsZero() {
+		return false
+	}
+
+	return now.Before(l.LastThrottled.Add(l.RetryAfter))
+}
+
+// limitTracker records the throttling signals seen on responses.
+type limitTracker struct {
+	mu            sync.Mutex
+	lastThrottled time.Time
+	retryAfter    time.Duration
+	seatsExceeded bool
+}
+
+// observe records a 429 response, restoring its body after peeking at the error key.
+func (t *limitTracker) observe(res *http.Response) {
+	if res.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	seatsExceeded := false
+	if res.Body != nil {
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(b))
+
+		if details, err := parseAPIError(b); err == nil {
+			seatsExceeded = details.Key == seatsExceededKey
+		}
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastThrottled = time.Now()
+	t.retryAfter = retryAfter
+	t.seatsExceeded = seatsExceeded
+}
+
+type sessionListResponse struct {
+	Sessions []struct {
+		Valid bool `json:"valid"`
+	} `json:"sessions"`
+}
+
+type maxSessionsResponse struct {
+	Config struct {
+		Webserver struct {
+			API struct {
+				MaxSessions int `json:"max_sessions"`
+			} `json:"api"`
+		} `json:"webserver"`
+	} `json:"config"`
+}
+
+// Limits returns the server's session usage and the throttling signals this client
+// has received, so callers can back off before hitting hard errors.
+func (c *Client) Limits(ctx context.Context) (*Limits, error) {
+	var resSessions sessionListResponse
+	if err := c.getJSON(ctx, "/api/auth/sessions", &resSessions); err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	var resMax maxSessionsResponse
+	if err := c.getJSON(ctx, "/api/config/webserver/api/max_sessions", &resMax); err != nil {
+		return nil, fmt.Errorf("failed to fetch session limit: %w", err)
+	}
+
+	limits := &Limits{MaxSessions: resMax.Config.Webserver.API.MaxSessions}
+	for _, session := range resSessions.Sessions {
+		if session.Valid {
+			limits.Sessions++
+		}
+	}
+
+	c.limits.mu.Lock()
+	limits.LastThrottled = c.limits.lastThrottled
+	limits.RetryAfter = c.limits.retryAfter
+	limits.SeatsExceeded = c.limits.seatsExceeded
+	c.limits.mu.Unlock()
+
+	return limits, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v interface{}) error {
+	res, err := c.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	return readJSON(res, v)
+}
+
+// readJSON decodes a 200 response into v and closes its body, returning the API
+// error of any other status.
+func readJSON(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		apiErr, err := newAPIError(res, b)
+		if err != nil {
+			return err
+		}
+		return &apiErr
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response body: %w", err)
+	}
+
+	return nil
+}
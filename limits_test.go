@@ -0,0 +1,47 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_LimitsReportsSessionsAndThrottling(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/auth/sessions":
+			return newHTTPResponse(http.StatusOK, `{"sessions":[{"id":0,"valid":true},{"id":1,"valid":true},{"id":2,"valid":false}]}`), nil
+		case "/api/config/webserver/api/max_sessions":
+			return newHTTPResponse(http.StatusOK, `{"config":{"webserver":{"api":{"max_sessions":3}}}}`), nil
+		default:
+			res := newHTTPResponse(http.StatusTooManyRequests, `{"error":{"key":"api_seats_exceeded","message":"API seats exceeded","hint":"increase webserver.api.max_sessions"}}`)
+			res.Header.Set("Retry-After", "30")
+			return res, nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	_, err = client.Stats.QueryTypes(context.Background())
+	var apiErr *StatsAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, seatsExceededKey, apiErr.Key)
+
+	limits, err := client.Limits(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, limits.Sessions)
+	assert.Equal(t, 3, limits.MaxSessions)
+	assert.Equal(t, 1, limits.Remaining())
+	assert.False(t, limits.Exhausted())
+	assert.True(t, limits.SeatsExceeded)
+	assert.Equal(t, 30*time.Second, limits.RetryAfter)
+	assert.True(t, limits.Throttled(time.Now()))
+	assert.False(t, limits.Throttled(time.Now().Add(time.Minute)))
+}
@@ -0,0 +1,323 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Lists interface {
+	// List all adlists with their gravity metadata.
+	List(ctx context.Context) (AdlistList, error)
+
+	// Get an adlist by its ID.
+	Get(ctx context.Context, id int64) (*Adlist, error)
+
+	// Stale returns enabled adlists that have not been updated within olderThan.
+	Stale(ctx context.Context, olderThan time.Duration) (AdlistList, error)
+
+	// Contains looks up each domain in the compiled gravity database and returns, in
+	// the same order, the adlists whose entries contain it exactly.
+	Contains(ctx context.Context, domains ...string) ([]GravityMembership, error)
+
+	// Watch polls the adlists and emits an event whenever the outcome of an adlist's
+	// last gravity download changes. The channel is closed when ctx is done.
+	Watch(ctx context.Context, interval time.Duration) (<-chan AdlistEvent, error)
+}
+
+var (
+	ErrorListNotFound = newNotFoundError("adlist not found")
+)
+
+// AdlistType is whether an adlist blocks or allows its domains.
+type AdlistType string
+
+const (
+	AdlistTypeBlock AdlistType = "block"
+	AdlistTypeAllow AdlistType = "allow"
+)
+
+// AdlistStatus is the outcome of the last gravity download of an adlist.
+type AdlistStatus int
+
+const (
+	AdlistStatusUnknown AdlistStatus = iota
+	AdlistStatusDownloaded
+	AdlistStatusUnchanged
+	AdlistStatusUnavailableCached
+	AdlistStatusUnavailable
+)
+
+func (s AdlistStatus) String() string {
+	switch s {
+	case AdlistStatusDownloaded:
+		return "downloaded"
+	case AdlistStatusUnchanged:
+		return "unchanged"
+	case AdlistStatusUnavailableCached:
+		return "unavailable, using local copy"
+	case AdlistStatusUnavailable:
+		return "unavailable, no local copy"
+	default:
+		return "unknown"
+	}
+}
+
+// Failed reports whether the last gravity run could not download the adlist.
+func (s AdlistStatus) Failed() bool {
+	return s == AdlistStatusUnavailableCached || s == AdlistStatusUnavailable
+}
+
+type lists struct {
+	client *Client
+}
+
+type Adlist struct {
+	ID             int64
+	Address        string
+	Type           AdlistType
+	Comment        string
+	Groups         []int
+	Enabled        bool
+	DateAdded      time.Time
+	DateModified   time.Time
+	DateUpdated    time.Time
+	Number         int
+	InvalidDomains int
+	ABPEntries     int
+	Status         AdlistStatus
+}
+
+type AdlistList []Adlist
+
+// AdlistEvent reports that the gravity download status of an adlist changed, for
+// example to AdlistStatusUnavailable when a download failed. Err is only set when a
+// poll failed.
+type AdlistEvent struct {
+	Old *Adlist
+	New *Adlist
+	Err error
+}
+
+type adlistResponse struct {
+	ID             int64  `json:"id"`
+	Address        string `json:"address"`
+	Type           string `json:"type"`
+	Comment        string `json:"comment"`
+	Groups         []int  `json:"groups"`
+	Enabled        bool   `json:"enabled"`
+	DateAdded      int64  `json:"date_added"`
+	DateModified   int64  `json:"date_modified"`
+	DateUpdated    int64  `json:"date_updated"`
+	Number         int    `json:"number"`
+	InvalidDomains int    `json:"invalid_domains"`
+	ABPEntries     int    `json:"abp_entries"`
+	Status         int    `json:"status"`
+}
+
+// GravityMembership lists the adlists whose gravity entries contain Domain.
+type GravityMembership struct {
+	Domain string
+	Lists  AdlistList
+}
+
+// Present reports whether the domain is in gravity at all.
+func (m GravityMembership) Present() bool {
+	return len(m.Lists) > 0
+}
+
+// In reports whether the adlist with the given ID contains the domain.
+func (m GravityMembership) In(id int64) bool {
+	for _, list := range m.Lists {
+		if list.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+type gravitySearchResponse struct {
+	Search struct {
+		Gravity []gravitySearchEntry `json:"gravity"`
+	} `json:"search"`
+}
+
+// gravitySearchEntry is a gravity match, carrying the adlist it comes from.
+type gravitySearchEntry struct {
+	Domain string `json:"domain"`
+	adlistResponse
+}
+
+// gravitySearchLimit caps the matches returned per search. Exact searches match at
+// most one entry per adlist.
+const gravitySearchLimit = 10000
+
+type adlistListResponse struct {
+	Lists []adlistResponse `json:"lists"`
+}
+
+func (res adlistResponse) toAdlist() Adlist {
+	list := Adlist{
+		ID:             res.ID,
+		Address:        res.Address,
+		Type:           AdlistType(res.Type),
+		Comment:        res.Comment,
+		Groups:         res.Groups,
+		Enabled:        res.Enabled,
+		DateAdded:      time.Unix(res.DateAdded, 0),
+		DateModified:   time.Unix(res.DateModified, 0),
+		Number:         res.Number,
+		InvalidDomains: res.InvalidDomains,
+		ABPEntries:     res.ABPEntries,
+		Status:         AdlistStatus(res.Status),
+	}
+
+	if res.DateUpdated > 0 {
+		list.DateUpdated = time.Unix(res.DateUpdated, 0)
+	}
+
+	return list
+}
+
+func (res adlistListResponse) toAdlistList() AdlistList {
+	list := make(AdlistList, 0, len(res.Lists))
+	for _, entry := range res.Lists {
+		list = append(list, entry.toAdlist())
+	}
+
+	return list
+}
+
+// Stale returns the enabled adlists in the list that were never updated, were last
+// updated before cutoff, or failed to download in the last gravity run.
+func (l AdlistList) Stale(cutoff time.Time) AdlistList {
+	stale := make(AdlistList, 0)
+	for _, list := range l {
+		if !list.Enabled {
+			continue
+		}
+
+		if list.DateUpdated.IsZero() || list.DateUpdated.Before(cutoff) || list.Status.Failed() {
+			stale = append(stale, list)
+		}
+	}
+
+	return stale
+}
+
+// List returns all adlists
+func (l lists) List(ctx context.Context) (AdlistList, error) {
+	res, err := l.client.Get(ctx, "/api/lists")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newListAPIError(res, b)
+	}
+
+	var resList adlistListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse adlist list body: %w", err)
+	}
+
+	return resList.toAdlistList(), nil
+}
+
+// Get returns an adlist by its ID
+func (l lists) Get(ctx context.Context, id int64) (*Adlist, error) {
+	all, err := l.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch adlists: %w", err)
+	}
+
+	for _, list := range all {
+		if list.ID == id {
+			return &list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d", ErrorListNotFound, id)
+}
+
+// Stale returns enabled adlists that stopped updating
+func (l lists) Stale(ctx context.Context, olderThan time.Duration) (AdlistList, error) {
+	all, err := l.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch adlists: %w", err)
+	}
+
+	return all.Stale(time.Now().Add(-olderThan)), nil
+}
+
+// Contains runs an exact search for each domain and collects the gravity matches
+func (l lists) Contains(ctx context.Context, domains ...string) ([]GravityMembership, error) {
+	memberships := make([]GravityMembership, 0, len(domains))
+	for _, domain := range domains {
+		query := url.Values{}
+		query.Set("partial", "false")
+		query.Set("N", fmt.Sprint(gravitySearchLimit))
+
+		res, err := l.client.Get(ctx, "/api/search/"+url.PathEscape(domain)+"?"+query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode != http.StatusOK {
+			b, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			return nil, newListAPIError(res, b)
+		}
+
+		var resSearch gravitySearchResponse
+		err = json.NewDecoder(res.Body).Decode(&resSearch)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse search body for %s: %w", domain, err)
+		}
+
+		membership := GravityMembership{Domain: domain, Lists: make(AdlistList, 0)}
+		for _, entry := range resSearch.Search.Gravity {
+			if !sameDomain(entry.Domain, domain) || membership.In(entry.ID) {
+				continue
+			}
+
+			membership.Lists = append(membership.Lists, entry.toAdlist())
+		}
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, nil
+}
+
+// Watch polls the adlists every interval and emits an event for each adlist whose
+// status differs from the previous poll
+func (l lists) Watch(ctx context.Context, interval time.Duration) (<-chan AdlistEvent, error) {
+	return watch(ctx, interval, l.List, diffAdlists, func(err error) AdlistEvent {
+		return AdlistEvent{Err: err}
+	})
+}
+
+func diffAdlists(before AdlistList, after AdlistList) []AdlistEvent {
+	byID := make(map[int64]Adlist, len(before))
+	for _, list := range before {
+		byID[list.ID] = list
+	}
+
+	events := make([]AdlistEvent, 0)
+	for _, list := range after {
+		old, ok := byID[list.ID]
+		if ok && old.Status != list.Status {
+			events = append(events, AdlistEvent{Old: &old, New: &list})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,105 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLists_Stale(t *testing.T) {
+	isUnit(t)
+
+	now := time.Now()
+	fresh := now.Add(-time.Hour).Unix()
+	old := now.Add(-72 * time.Hour).Unix()
+
+	body := fmt.Sprintf(`{"lists":[
+		{"id":1,"address":"https://fresh.example/list","type":"block","enabled":true,"date_updated":%d,"number":1200,"invalid_domains":3,"status":1},
+		{"id":2,"address":"https://old.example/list","type":"block","enabled":true,"date_updated":%d,"number":50,"status":2},
+		{"id":3,"address":"https://failing.example/list","type":"block","enabled":true,"date_updated":%d,"number":10,"status":3},
+		{"id":4,"address":"https://never.example/list","type":"block","enabled":true,"date_updated":0,"status":0},
+		{"id":5,"address":"https://disabled.example/list","type":"block","enabled":false,"date_updated":%d,"status":4}
+	]}`, fresh, old, fresh, old)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/lists" {
+			return newHTTPResponse(http.StatusOK, body), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	stale, err := client.Lists.Stale(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+
+	ids := make([]int64, 0, len(stale))
+	for _, list := range stale {
+		ids = append(ids, list.ID)
+	}
+	assert.Equal(t, []int64{2, 3, 4}, ids)
+
+	list, err := client.Lists.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1200, list.Number)
+	assert.Equal(t, 3, list.InvalidDomains)
+	assert.Equal(t, AdlistStatusDownloaded, list.Status)
+	assert.Equal(t, fresh, list.DateUpdated.Unix())
+
+	_, err = client.Lists.Get(context.Background(), 42)
+	assert.ErrorIs(t, err, ErrorListNotFound)
+}
+
+func TestLists_Contains(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || req.URL.Query().Get("partial") != "false" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		switch req.URL.Path {
+		case "/api/search/ads.example":
+			return newHTTPResponse(http.StatusOK, `{"search":{"domains":[],"gravity":[
+				{"domain":"ads.example","id":1,"address":"https://corp.example/mandated","type":"block","enabled":true},
+				{"domain":"ads.example","id":3,"address":"https://community.example/list","type":"block","enabled":true}
+			]}}`), nil
+		case "/api/search/missing.example":
+			return newHTTPResponse(http.StatusOK, `{"search":{"domains":[],"gravity":[]}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	memberships, err := client.Lists.Contains(context.Background(), "ads.example", "missing.example")
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+
+	assert.Equal(t, "ads.example", memberships[0].Domain)
+	assert.True(t, memberships[0].Present())
+	assert.True(t, memberships[0].In(1))
+	assert.False(t, memberships[0].In(2))
+	assert.Equal(t, "https://corp.example/mandated", memberships[0].Lists[0].Address)
+
+	assert.Equal(t, "missing.example", memberships[1].Domain)
+	assert.False(t, memberships[1].Present())
+}
+
+func TestDiffAdlists(t *testing.T) {
+	before := AdlistList{{ID: 1, Status: AdlistStatusDownloaded}, {ID: 2, Status: AdlistStatusUnchanged}}
+	after := AdlistList{{ID: 1, Status: AdlistStatusUnavailable}, {ID: 2, Status: AdlistStatusUnchanged}, {ID: 3}}
+
+	events := diffAdlists(before, after)
+	require.Len(t, events, 1)
+	assert.Equal(t, AdlistStatusDownloaded, events[0].Old.Status)
+	assert.Equal(t, AdlistStatusUnavailable, events[0].New.Status)
+}
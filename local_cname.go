@@ -10,27 +10,76 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type LocalCNAME interface {
-	// List all CNAME records.
+	// List all CNAME records, sorted by domain and target.
 	List(ctx context.Context) (CNAMERecordList, error)
 
+	// ListWithReport lists the CNAME records that could be parsed along with a report
+	// of the entries that were skipped, regardless of Config.LenientParsing.
+	ListWithReport(ctx context.Context) (CNAMERecordList, *ParseReport, error)
+
+	// ListRaw returns the CNAME entries as stored, in config order, without parsing
+	// them. Parse the entries of interest with Parse.
+	ListRaw(ctx context.Context) ([]string, error)
+
+	// Parse parses a CNAME entry as returned by ListRaw, with the client's record
+	// codec.
+	Parse(raw string) (CNAMERecord, error)
+
 	// Create a CNAME record.
 	Create(ctx context.Context, domain string, target string) (*CNAMERecord, error)
 
 	// CreateRecord creates a CNAME record using the provided record definition.
+	// Creating a record for a domain that already has one returns a
+	// *DuplicateRecordError.
 	CreateRecord(ctx context.Context, record *CNAMERecord) (*CNAMERecord, error)
 
-	// Get a CNAME record by its domain.
+	// Update points the CNAME record for record's domain at record's target and TTL.
+	// FTL rejects two entries for one domain, so the old entry, and any stale
+	// duplicates, are removed before the new one is added, and put back if it is
+	// rejected.
+	Update(ctx context.Context, record *CNAMERecord) (*CNAMERecord, error)
+
+	// Get a CNAME record by its domain. If stale duplicates point the domain at
+	// several targets, Get returns a *AmbiguousRecordError listing them.
 	Get(ctx context.Context, domain string) (*CNAMERecord, error)
 
-	// Delete a CNAME record by its domain.
+	// GetByID returns the CNAME record whose ID matches. Like Get, it returns a
+	// *AmbiguousRecordError when the domain has duplicates.
+	GetByID(ctx context.Context, id string) (*CNAMERecord, error)
+
+	// DeleteByID deletes the CNAME record whose ID matches.
+	DeleteByID(ctx context.Context, id string) error
+
+	// Delete a CNAME record by its domain. It refuses to guess when the domain has
+	// duplicates; use DeleteByDomainTarget to remove them.
 	Delete(ctx context.Context, domain string) error
+
+	// DeleteByDomainTarget deletes only the CNAME records pointing domain at target,
+	// whatever their TTL. A missing record is not an error.
+	DeleteByDomainTarget(ctx context.Context, domain string, target string) error
+
+	// ListPages parses the CNAME entries incrementally and calls fn with pages of at
+	// most pageSize records, in config order rather than sorted. Return
+	// ErrStopPaging from fn to stop early; any other error is returned as is.
+	ListPages(ctx context.Context, pageSize int, fn func(CNAMERecordList) error) error
+
+	// SetTTL changes the TTL of the CNAME record for domain, keeping its target. FTL
+	// rejects two entries for one domain, so the old entry is removed before the new
+	// one is added, and put back if the new one is rejected.
+	SetTTL(ctx context.Context, domain string, ttl int) (*CNAMERecord, error)
+
+	// Watch polls the CNAME records and emits an event for each record added, removed
+	// or changed since the previous poll, including edits made outside this client.
+	// The channel is closed when ctx is done.
+	Watch(ctx context.Context, interval time.Duration) (<-chan CNAMERecordEvent, error)
 }
 
 var (
-	ErrorLocalCNAMENotFound = errors.New("local CNAME record not found")
+	ErrorLocalCNAMENotFound = newNotFoundError("local CNAME record not found")
 )
 
 type localCNAME struct {
@@ -40,6 +89,8 @@ type localCNAME struct {
 type CNAMERecord struct {
 	Domain string
 	Target string
+
+	// TTL and HasTTL are serialized as a single optional ttl field.
 	TTL    int
 	HasTTL bool
 	raw    string
@@ -58,42 +109,58 @@ type cnameRecordDNSListResponse struct {
 }
 
 func (res cnameRecordListResponse) toCNAMERecordList() (CNAMERecordList, error) {
+	list, _, err := res.parse(DefaultRecordCodec, false)
+	return list, err
+}
+
+// parse converts the CNAME entries with codec, failing on the first invalid entry
+// unless lenient is set, in which case invalid entries are skipped and returned.
+func (res cnameRecordListResponse) parse(codec RecordCodec, lenient bool) (CNAMERecordList, []SkippedEntry, error) {
 	list := make(CNAMERecordList, 0, len(res.Config.DNS.CNAMERecords))
+	skipped := make([]SkippedEntry, 0)
 
 	for _, entry := range res.Config.DNS.CNAMERecords {
-		record, err := parseCNAMERecord(entry)
+		record, err := decodeCNAME(codec, entry)
 		if err != nil {
-			return nil, err
+			if !lenient {
+				return nil, nil, err
+			}
+
+			skipped = append(skipped, SkippedEntry{Raw: entry, Err: err})
+			continue
 		}
 
 		list = append(list, record)
 	}
 
-	return list, nil
+	return list, skipped, nil
 }
 
+// parseCNAMERecord parses a domain,target[,ttl] tuple without splitting it, so that
+// parsing large configs does not allocate per entry.
 func parseCNAMERecord(raw string) (CNAMERecord, error) {
-	entry := strings.Split(raw, ",")
-	if len(entry) < 2 || len(entry) > 3 {
+	domain, rest, ok := strings.Cut(raw, ",")
+	if !ok {
+		return CNAMERecord{}, fmt.Errorf("invalid CNAME record: %q", raw)
+	}
+	target, ttlStr, hasTTL := strings.Cut(rest, ",")
+	if strings.IndexByte(ttlStr, ',') >= 0 {
 		return CNAMERecord{}, fmt.Errorf("invalid CNAME record: %q", raw)
 	}
 
 	record := CNAMERecord{
-		Domain: strings.TrimSpace(entry[0]),
-		Target: strings.TrimSpace(entry[1]),
+		Domain: strings.TrimSpace(domain),
+		Target: strings.TrimSpace(target),
 		raw:    strings.TrimSpace(raw),
 	}
 
-	if len(entry) == 3 {
-		ttlStr := strings.TrimSpace(entry[2])
-		if ttlStr != "" {
-			ttl, err := strconv.Atoi(ttlStr)
-			if err != nil {
-				return CNAMERecord{}, fmt.Errorf("invalid TTL in CNAME record %q: %w", raw, err)
-			}
-			record.TTL = ttl
-			record.HasTTL = true
+	if ttlStr = strings.TrimSpace(ttlStr); hasTTL && ttlStr != "" {
+		ttl, err := strconv.Atoi(ttlStr)
+		if err != nil {
+			return CNAMERecord{}, fmt.Errorf("invalid TTL in CNAME record %q: %w", raw, err)
 		}
+		record.TTL = ttl
+		record.HasTTL = true
 	}
 
 	return record, nil
@@ -101,26 +168,43 @@ func parseCNAMERecord(raw string) (CNAMERecord, error) {
 
 type CNAMERecordList []CNAMERecord
 
-// List returns all CNAME records
+// List returns all CNAME records sorted by domain and target
 func (cname localCNAME) List(ctx context.Context) (CNAMERecordList, error) {
+	records, _, err := cname.list(ctx, cname.client.lenientParsing)
+	return records, err
+}
+
+// ListWithReport returns the valid CNAME records and a report of the entries that
+// were skipped
+func (cname localCNAME) ListWithReport(ctx context.Context) (CNAMERecordList, *ParseReport, error) {
+	return cname.list(ctx, true)
+}
+
+func (cname localCNAME) list(ctx context.Context, lenient bool) (CNAMERecordList, *ParseReport, error) {
 	res, err := cname.client.Get(ctx, "/api/config/dns/cnameRecords")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, nil, newCNAMEAPIError(res, b)
+	}
+
 	var resList *cnameRecordListResponse
 	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
-		return nil, fmt.Errorf("failed to parse custom CNAME list body: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse custom CNAME list body: %w", err)
 	}
 
-	records, err := resList.toCNAMERecordList()
+	records, skipped, err := resList.parse(cname.client.recordCodec(), lenient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse custom CNAME list body: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse custom CNAME list body: %w", err)
 	}
+	records.Sort()
 
-	return records, nil
+	return records, newParseReport(resList.Config.DNS.CNAMERecords, len(records), skipped), nil
 }
 
 // Create creates a CNAME record
@@ -129,37 +213,173 @@ func (cname localCNAME) Create(ctx context.Context, domain string, target string
 }
 
 // CreateRecord creates a CNAME record using the provided record definition.
-func (cname localCNAME) CreateRecord(ctx context.Context, record *CNAMERecord) (*CNAMERecord, error) {
-	value := encodeCNAMERecord(record)
-	res, err := cname.client.Put(ctx, fmt.Sprintf("/api/config/dns/cnameRecords/%s", value), nil)
+func (cname localCNAME) CreateRecord(ctx context.Context, record *CNAMERecord) (created *CNAMERecord, err error) {
+	if record.HasTTL {
+		if err := validateTTL(record.TTL); err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() {
+		cname.client.afterMutation(ctx, Mutation{Operation: AuditLocalCNAMECreate, Target: record.Domain, After: auditValue(created)}, err)
+	}()
+
+	if err := cname.client.beforeMutation(ctx, Mutation{Operation: AuditLocalCNAMECreate, Target: record.Domain, After: *record}); err != nil {
+		return nil, err
+	}
+
+	existing, err := cname.Get(ctx, record.Domain)
+	if err == nil {
+		return nil, &DuplicateRecordError{Existing: existing.LocalRecord()}
+	}
+	if !errors.Is(err, ErrorLocalCNAMENotFound) {
+		return nil, fmt.Errorf("failed looking up CNAME record %s before creation: %w", record.Domain, err)
+	}
+
+	if err := cname.put(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return cname.Get(ctx, record.Domain)
+}
+
+// Update replaces the CNAME entries for the domain of record with record
+func (cname localCNAME) Update(ctx context.Context, record *CNAMERecord) (updated *CNAMERecord, err error) {
+	if record.HasTTL {
+		if err := validateTTL(record.TTL); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := cname.List(ctx)
 	if err != nil {
+		return nil, fmt.Errorf("failed looking up CNAME record %s for update: %w", record.Domain, err)
+	}
+
+	current := make([]CNAMERecord, 0)
+	for _, candidate := range list {
+		if sameDomain(candidate.Domain, record.Domain) {
+			current = append(current, candidate)
+		}
+	}
+	switch {
+	case len(current) == 0:
+		return nil, fmt.Errorf("%w: %s", ErrorLocalCNAMENotFound, record.Domain)
+	case len(current) == 1 && current[0].Equal(*record):
+		return &current[0], nil
+	}
+
+	defer func() {
+		cname.client.afterMutation(ctx, Mutation{Operation: AuditLocalCNAMEUpdate, Target: record.Domain, Before: current[0], After: auditValue(updated)}, err)
+	}()
+
+	if err := cname.client.beforeMutation(ctx, Mutation{Operation: AuditLocalCNAMEUpdate, Target: record.Domain, Before: current[0], After: *record}); err != nil {
 		return nil, err
 	}
 
+	if err := cname.replace(ctx, current, record); err != nil {
+		return nil, fmt.Errorf("failed to update CNAME record %s: %w", record.Domain, err)
+	}
+
+	return cname.Get(ctx, record.Domain)
+}
+
+// put adds the CNAME entry for record. A retried request that the server already
+// applied counts as success.
+func (cname localCNAME) put(ctx context.Context, record *CNAMERecord) error {
+	value := escapeCNAMEValue(encodeCNAME(cname.client.recordCodec(), record))
+
+	res, done, err := cname.client.mutate(ctx, http.MethodPut, fmt.Sprintf("/api/config/dns/cnameRecords/%s", value), nil, func(ctx context.Context) (bool, error) {
+		return cname.exists(ctx, record)
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusCreated {
 		b, _ := io.ReadAll(res.Body)
-		return nil, newCNAMEAPIError(res.StatusCode, b)
+		return newCNAMEAPIError(res, b)
 	}
 
-	return cname.Get(ctx, record.Domain)
+	return nil
 }
 
-// Get returns a CNAME record by the passed domain
+// Get returns a CNAME record by the passed domain, keeping only the entries that
+// may name it
 func (cname localCNAME) Get(ctx context.Context, domain string) (*CNAMERecord, error) {
-	list, err := cname.List(ctx)
+	list := make(CNAMERecordList, 0)
+	err := cname.client.streamConfigArray(ctx, "/api/config/dns/cnameRecords", "cnameRecords", newCNAMEAPIError, func(entry string) error {
+		candidate := cname.client.mayName(entry, domain)
+		if !candidate && cname.client.lenientParsing {
+			return nil
+		}
+
+		record, err := cname.Parse(entry)
+		if err != nil {
+			if cname.client.lenientParsing {
+				return nil
+			}
+			return fmt.Errorf("failed to parse custom CNAME list body: %w", err)
+		}
+
+		if candidate {
+			list = append(list, record)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch custom CNAME records: %w", err)
 	}
+	list.Sort()
+
+	return list.single(domain, func(record CNAMERecord) bool {
+		return sameDomain(record.Domain, domain)
+	})
+}
 
+// single returns the one record match selects, a *AmbiguousRecordError if it selects
+// several, or ErrorLocalCNAMENotFound for key if it selects none.
+func (list CNAMERecordList) single(key string, match func(CNAMERecord) bool) (*CNAMERecord, error) {
+	var found CNAMERecordList
 	for _, record := range list {
-		if strings.EqualFold(record.Domain, domain) {
-			return &record, nil
+		if match(record) {
+			found = append(found, record)
 		}
 	}
 
-	return nil, fmt.Errorf("%w: %s", ErrorLocalCNAMENotFound, domain)
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrorLocalCNAMENotFound, key)
+	case 1:
+		return &found[0], nil
+	default:
+		records := make([]LocalRecord, 0, len(found))
+		for _, record := range found {
+			records = append(records, record.LocalRecord())
+		}
+		return nil, &AmbiguousRecordError{Name: key, Records: records}
+	}
+}
+
+// exists reports whether the CNAME config holds exactly the entry for record.
+func (cname localCNAME) exists(ctx context.Context, record *CNAMERecord) (bool, error) {
+	list, err := cname.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, candidate := range list {
+		if candidate.Equal(*record) {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 // Delete removes a CNAME record by domain
@@ -172,39 +392,96 @@ func (cname localCNAME) Delete(ctx context.Context, domain string) error {
 		return fmt.Errorf("failed looking up CNAME record %s for deletion: %w", domain, err)
 	}
 
-	value := encodeCNAMERecord(record)
+	return cname.delete(ctx, record)
+}
 
-	res, err := cname.client.Delete(ctx, fmt.Sprintf("/api/config/dns/cnameRecords/%s", value))
+// GetByID returns the CNAME record with the given ID
+func (cname localCNAME) GetByID(ctx context.Context, id string) (*CNAMERecord, error) {
+	list, err := cname.List(ctx)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to fetch custom CNAME records: %w", err)
 	}
 
-	defer res.Body.Close()
+	return list.single(id, func(record CNAMERecord) bool {
+		return record.ID() == id
+	})
+}
 
-	if res.StatusCode != http.StatusNoContent {
-		b, _ := io.ReadAll(res.Body)
-		return newCNAMEAPIError(res.StatusCode, b)
+// DeleteByID removes the CNAME record with the given ID
+func (cname localCNAME) DeleteByID(ctx context.Context, id string) error {
+	record, err := cname.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrorLocalCNAMENotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed looking up CNAME record %s for deletion: %w", id, err)
+	}
+
+	return cname.delete(ctx, record)
+}
+
+// DeleteByDomainTarget removes the CNAME records pointing domain at target
+func (cname localCNAME) DeleteByDomainTarget(ctx context.Context, domain string, target string) error {
+	list, err := cname.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed looking up CNAME record %s -> %s for deletion: %w", domain, target, err)
+	}
+
+	for _, record := range list {
+		if sameDomain(record.Domain, domain) && sameDomain(record.Target, target) {
+			if err := cname.delete(ctx, &record); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func encodeCNAMERecord(record *CNAMERecord) string {
-	if record == nil {
-		return ""
+func (cname localCNAME) delete(ctx context.Context, record *CNAMERecord) (err error) {
+	m := Mutation{Operation: AuditLocalCNAMEDelete, Target: record.Domain, Before: *record}
+	defer func() {
+		cname.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := cname.client.beforeMutation(ctx, m); err != nil {
+		return err
 	}
 
-	if record.raw != "" && record.Domain != "" {
-		return escapeCNAMEValue(record.raw)
+	return cname.remove(ctx, record)
+}
+
+// remove deletes the CNAME entry of record. A retried request that the server
+// already applied counts as success.
+func (cname localCNAME) remove(ctx context.Context, record *CNAMERecord) error {
+	value := escapeCNAMEValue(encodeCNAME(cname.client.recordCodec(), record))
+
+	res, done, err := cname.client.mutate(ctx, http.MethodDelete, fmt.Sprintf("/api/config/dns/cnameRecords/%s", value), nil, func(ctx context.Context) (bool, error) {
+		exists, err := cname.exists(ctx, record)
+		return !exists, err
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
 	}
 
-	parts := []string{strings.TrimSpace(record.Domain), strings.TrimSpace(record.Target)}
-	if record.HasTTL {
-		parts = append(parts, strconv.Itoa(record.TTL))
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newCNAMEAPIError(res, b)
 	}
 
-	raw := strings.Join(parts, ",")
-	return escapeCNAMEValue(raw)
+	return nil
+}
+
+// Raw returns the domain,target[,ttl] tuple Pi-hole stores for the record, exactly as
+// read from the server or encoded from the fields for records built locally or edited
+// since.
+func (r CNAMERecord) Raw() string {
+	return encodeCNAME(DefaultRecordCodec, &r)
 }
 
 func escapeCNAMEValue(value string) string {
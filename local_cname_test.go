@@ -142,6 +142,8 @@ func TestLocalCNAME_CreateRecordWithTTLAndDeletePreservesTuple(t *testing.T) {
 				t.Fatalf("unexpected PUT path: %s", req.URL.Path)
 			}
 			return newHTTPResponse(http.StatusCreated, ``), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/cnameRecords" && receivedPUTPath == "":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":[]}}}`), nil
 		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/cnameRecords":
 			return newHTTPResponse(http.StatusOK, fmt.Sprintf(`{"config":{"dns":{"cnameRecords":["%s"]}}}`, rawTuple)), nil
 		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/config/dns/cnameRecords/"):
@@ -204,3 +206,97 @@ func TestLocalCNAME_DeleteReturnsAPIError(t *testing.T) {
 	assert.Equal(t, "not_found", apiErr.Key)
 	assert.Equal(t, "missing", apiErr.Message)
 }
+
+func TestLocalCNAME_CreateReturnsDuplicateRecordError(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/cnameRecords":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.example.com,web.example.com"]}}}`), nil
+		case req.Method == http.MethodPut:
+			t.Fatalf("unexpected create request for duplicate CNAME")
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	_, err = client.LocalCNAME.Create(context.Background(), "www.example.com", "other.example.com")
+	require.ErrorIs(t, err, ErrDuplicateRecord)
+
+	var dupErr *DuplicateRecordError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, "web.example.com", dupErr.Existing.Value)
+}
+
+func TestLocalCNAME_DuplicatesAreAmbiguous(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"app.lan,old.lan", "app.lan,new.lan,300", "other.lan,old.lan"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+	ctx := context.Background()
+
+	_, err := client.LocalCNAME.Get(ctx, "app.lan")
+	var ambiguous *AmbiguousRecordError
+	require.ErrorAs(t, err, &ambiguous)
+	assert.ErrorIs(t, err, ErrAmbiguousRecord)
+	assert.Equal(t, "app.lan", ambiguous.Name)
+	assert.Len(t, ambiguous.Records, 2)
+
+	require.ErrorIs(t, client.LocalCNAME.Delete(ctx, "app.lan"), ErrAmbiguousRecord)
+	assert.Empty(t, ops)
+
+	require.NoError(t, client.LocalCNAME.DeleteByDomainTarget(ctx, "APP.lan.", "old.lan"))
+	assert.Equal(t, []string{"DELETE app.lan,old.lan"}, ops)
+
+	record, err := client.LocalCNAME.Get(ctx, "app.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "new.lan", record.Target)
+
+	require.NoError(t, client.LocalCNAME.DeleteByDomainTarget(ctx, "app.lan", "missing.lan"))
+	assert.Len(t, ops, 1)
+}
+
+func BenchmarkCNAMERecordListResponse_toCNAMERecordList(b *testing.B) {
+	entries := make([]string, 50000)
+	for i := range entries {
+		if i%2 == 0 {
+			entries[i] = fmt.Sprintf("www%d.lan,host%d.lan", i, i)
+		} else {
+			entries[i] = fmt.Sprintf("www%d.lan, host%d.lan, 300", i, i)
+		}
+	}
+	resp := cnameRecordListResponse{Config: cnameRecordConfigListResponse{DNS: cnameRecordDNSListResponse{CNAMERecords: entries}}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := resp.toCNAMERecordList(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLocalCNAME_Update(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"www.lan,old.lan", "WWW.lan,stale.lan,60", "api.lan,web.lan"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+
+	record, err := client.LocalCNAME.Update(context.Background(), &CNAMERecord{Domain: "www.lan", Target: "web.lan"})
+	require.NoError(t, err)
+	assert.Equal(t, "web.lan", record.Target)
+	assert.Equal(t, []string{"api.lan,web.lan", "www.lan,web.lan"}, entries)
+	assert.Equal(t, "PUT www.lan,web.lan", ops[len(ops)-1])
+
+	_, err = client.LocalCNAME.Update(context.Background(), &CNAMERecord{Domain: "missing.lan", Target: "web.lan"})
+	assert.ErrorIs(t, err, ErrorLocalCNAMENotFound)
+}
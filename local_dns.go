@@ -7,26 +7,72 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type LocalDNS interface {
-	// List all DNS records.
+	// List all DNS records, sorted by domain and IP.
 	List(ctx context.Context) (DNSRecordList, error)
 
-	// Create a DNS record.
+	// ListWithReport lists the DNS records that could be parsed along with a report
+	// of the host lines that were skipped, regardless of Config.LenientParsing.
+	ListWithReport(ctx context.Context) (DNSRecordList, *ParseReport, error)
+
+	// ListRaw returns the host lines as stored, in config order, without parsing
+	// them. Parse the lines of interest with Parse.
+	ListRaw(ctx context.Context) ([]string, error)
+
+	// Parse parses a host line as returned by ListRaw, with the client's record
+	// codec.
+	Parse(raw string) (DNSRecord, error)
+
+	// Create a DNS record. Creating a record whose domain and IP already exist
+	// returns a *DuplicateRecordError.
 	Create(ctx context.Context, domain string, IP string) (*DNSRecord, error)
 
-	// Get a DNS record by its domain.
+	// CreateRecord creates a DNS record using the provided record definition,
+	// including its aliases, TTL and comment.
+	CreateRecord(ctx context.Context, record *DNSRecord) (*DNSRecord, error)
+
+	// Replace swaps the hosts line of old, as returned by List or Get, for record,
+	// which may change any of its fields. The new line is added before the old one
+	// is removed, so the name keeps resolving throughout.
+	Replace(ctx context.Context, old DNSRecord, record *DNSRecord) (*DNSRecord, error)
+
+	// Get a DNS record by its domain or one of its aliases.
 	Get(ctx context.Context, domain string) (*DNSRecord, error)
 
-	// Delete a DNS record by its domain.
+	// GetByID returns the DNS record whose ID matches.
+	GetByID(ctx context.Context, id string) (*DNSRecord, error)
+
+	// DeleteByID deletes the DNS record whose ID matches.
+	DeleteByID(ctx context.Context, id string) error
+
+	// Delete a DNS record by its domain or one of its aliases. The whole hosts line
+	// is removed, including any other names on it.
 	Delete(ctx context.Context, domain string) error
+
+	// ListPages parses the host lines incrementally and calls fn with pages of at
+	// most pageSize records, in config order rather than sorted. Return
+	// ErrStopPaging from fn to stop early; any other error is returned as is.
+	ListPages(ctx context.Context, pageSize int, fn func(DNSRecordList) error) error
+
+	// SetTTL changes the TTL of the record Get returns for domain, keeping its
+	// address, aliases and comment. The new hosts line is added before the old one
+	// is removed, so the name keeps resolving throughout.
+	SetTTL(ctx context.Context, domain string, ttl int) (*DNSRecord, error)
+
+	// Watch polls the DNS records and emits an event for each record added, removed
+	// or changed since the previous poll, including edits made outside this client.
+	// The channel is closed when ctx is done.
+	Watch(ctx context.Context, interval time.Duration) (<-chan RecordEvent, error)
 }
 
 var (
-	ErrorLocalDNSNotFound = errors.New("local dns record not found")
+	ErrorLocalDNSNotFound = newNotFoundError("local dns record not found")
 )
 
 type localDNS struct {
@@ -34,14 +80,42 @@ type localDNS struct {
 }
 
 type DNSRecord struct {
-	IP      string
-	Domain  string
+	IP     string
+	Domain string
+
+	// Aliases are the additional hostnames listed on the same hosts line.
+	Aliases []string
+
+	// TTL and HasTTL are serialized as a single optional ttl field.
 	TTL     int
 	HasTTL  bool
 	Comment string
 	raw     string
 }
 
+// Names returns the domain followed by its aliases.
+func (r DNSRecord) Names() []string {
+	return append([]string{r.Domain}, r.Aliases...)
+}
+
+// hasName reports whether the domain or one of the aliases matches name.
+func (r DNSRecord) hasName(name string) bool {
+	for _, candidate := range r.Names() {
+		if sameDomain(candidate, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Raw returns the hosts line Pi-hole stores for the record. Records read from the
+// server return the line exactly as stored, including aliases and comments; records
+// built locally or edited since return the line encoded from their fields.
+func (r DNSRecord) Raw() string {
+	return encodeHost(DefaultRecordCodec, &r)
+}
+
 type DNSRecordList []DNSRecord
 
 type dnsRecordListResponse struct {
@@ -57,117 +131,342 @@ type dnsRecordDNSListResponse struct {
 }
 
 func (res dnsRecordListResponse) toDNSRecordList() (DNSRecordList, error) {
+	list, _, err := res.parse(DefaultRecordCodec, false)
+	return list, err
+}
+
+// parse converts the host lines with codec, failing on the first invalid line unless
+// lenient is set, in which case invalid lines are skipped and returned.
+func (res dnsRecordListResponse) parse(codec RecordCodec, lenient bool) (DNSRecordList, []SkippedEntry, error) {
 	list := make(DNSRecordList, 0, len(res.Config.DNS.Hosts))
+	skipped := make([]SkippedEntry, 0)
 
 	for _, entry := range res.Config.DNS.Hosts {
-		record, err := parseDNSRecord(entry)
+		record, err := decodeHost(codec, entry)
 		if err != nil {
-			return nil, err
+			if !lenient {
+				return nil, nil, err
+			}
+
+			skipped = append(skipped, SkippedEntry{Raw: entry, Err: err})
+			continue
 		}
 
 		list = append(list, record)
 	}
 
-	return list, nil
+	return list, skipped, nil
 }
 
+// parseDNSRecord parses a hosts line. It scans the line in place rather than
+// splitting it, since large configs hold tens of thousands of lines; the only
+// allocation is the alias slice of lines that have aliases.
 func parseDNSRecord(raw string) (DNSRecord, error) {
 	record := DNSRecord{raw: raw}
-	line := strings.TrimSpace(raw)
+	line := raw
 
-	if line == "" {
-		return record, fmt.Errorf("invalid DNS record: %q", raw)
-	}
-
-	commentIdx := strings.Index(line, "#")
-	if commentIdx >= 0 {
+	if commentIdx := strings.IndexByte(line, '#'); commentIdx >= 0 {
 		record.Comment = strings.TrimSpace(line[commentIdx+1:])
-		line = strings.TrimSpace(line[:commentIdx])
+		line = line[:commentIdx]
 	}
 
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
+	var ok bool
+	if record.IP, line, ok = nextField(line); !ok {
+		return record, fmt.Errorf("invalid DNS record: %q", raw)
+	}
+	if record.Domain, line, ok = nextField(line); !ok {
 		return record, fmt.Errorf("invalid DNS record: %q", raw)
 	}
 
-	record.IP = parts[0]
-	record.Domain = parts[1]
-
-	if len(parts) >= 3 {
-		if ttl, err := strconv.Atoi(parts[2]); err == nil {
+	if field, rest, ok := nextField(line); ok && maybeInt(field) {
+		if ttl, err := strconv.Atoi(field); err == nil {
 			record.TTL = ttl
 			record.HasTTL = true
-			if len(parts) > 3 {
-				extra := strings.Join(parts[3:], " ")
-				record.Comment = strings.TrimSpace(strings.Join([]string{record.Comment, extra}, " "))
-			}
-		} else {
-			extra := strings.Join(parts[2:], " ")
-			record.Comment = strings.TrimSpace(strings.Join([]string{record.Comment, extra}, " "))
+			line = rest
+		}
+	}
+
+	// Further names on the line are aliases resolving to the same address.
+	if n := countFields(line); n > 0 {
+		record.Aliases = make([]string, 0, n)
+		for alias, rest, ok := nextField(line); ok; alias, rest, ok = nextField(rest) {
+			record.Aliases = append(record.Aliases, alias)
 		}
 	}
 
 	return record, nil
 }
 
-// List returns a list of custom DNS records
+// nextField returns the first whitespace-separated field of s and the remainder
+// after it, or false if s holds only whitespace.
+func nextField(s string) (string, string, bool) {
+	start := 0
+	for start < len(s) && isFieldSpace(s[start]) {
+		start++
+	}
+	if start == len(s) {
+		return "", "", false
+	}
+
+	end := start
+	for end < len(s) && !isFieldSpace(s[end]) {
+		end++
+	}
+
+	return s[start:end], s[end:], true
+}
+
+// countFields returns the number of whitespace-separated fields in s.
+func countFields(s string) int {
+	n := 0
+	for _, rest, ok := nextField(s); ok; _, rest, ok = nextField(rest) {
+		n++
+	}
+
+	return n
+}
+
+// isFieldSpace reports whether b separates the fields of a hosts line.
+func isFieldSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+// maybeInt reports whether s could be a number, to skip strconv.Atoi and the error
+// it allocates for the names that usually follow the domain.
+func maybeInt(s string) bool {
+	return s[0] == '+' || s[0] == '-' || (s[0] >= '0' && s[0] <= '9')
+}
+
+// List returns a list of custom DNS records sorted by domain and IP
 func (dns localDNS) List(ctx context.Context) (DNSRecordList, error) {
+	records, _, err := dns.list(ctx, dns.client.lenientParsing)
+	return records, err
+}
+
+// ListWithReport returns the valid custom DNS records and a report of the host lines
+// that were skipped
+func (dns localDNS) ListWithReport(ctx context.Context) (DNSRecordList, *ParseReport, error) {
+	return dns.list(ctx, true)
+}
+
+func (dns localDNS) list(ctx context.Context, lenient bool) (DNSRecordList, *ParseReport, error) {
 	res, err := dns.client.Get(ctx, "/api/config/dns/hosts")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, nil, newDNSAPIError(res, b)
+	}
+
 	var resList *dnsRecordListResponse
 	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
-		return nil, fmt.Errorf("failed to parse customDNS list body: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse customDNS list body: %w", err)
 	}
 
-	records, err := resList.toDNSRecordList()
+	records, skipped, err := resList.parse(dns.client.recordCodec(), lenient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse customDNS list body: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse customDNS list body: %w", err)
 	}
+	records.Sort()
 
-	return records, nil
+	return records, newParseReport(resList.Config.DNS.Hosts, len(records), skipped), nil
 }
 
 // Create creates a custom DNS record
 func (dns localDNS) Create(ctx context.Context, domain string, IP string) (*DNSRecord, error) {
-	value := fmt.Sprintf("%s%%20%s", IP, domain)
+	return dns.CreateRecord(ctx, &DNSRecord{Domain: domain, IP: IP})
+}
 
-	res, err := dns.client.Put(ctx, fmt.Sprintf("/api/config/dns/hosts/%s", value), nil)
+// CreateRecord creates a custom DNS record including its aliases, TTL and comment
+func (dns localDNS) CreateRecord(ctx context.Context, record *DNSRecord) (created *DNSRecord, err error) {
+	if record.HasTTL {
+		if err := validateTTL(record.TTL); err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() {
+		dns.client.afterMutation(ctx, Mutation{Operation: AuditLocalDNSCreate, Target: record.Domain, After: auditValue(created)}, err)
+	}()
+
+	if err := dns.client.beforeMutation(ctx, Mutation{Operation: AuditLocalDNSCreate, Target: record.Domain, After: *record}); err != nil {
+		return nil, err
+	}
+
+	if err := dns.put(ctx, record); err != nil {
+		// Pi-hole rejects an existing entry with a generic 400, so look it up to
+		// tell duplicates apart from other failures.
+		var apiErr *DNSAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			if existing := dns.find(ctx, record.Domain, record.IP); existing != nil {
+				return nil, &DuplicateRecordError{Existing: existing.LocalRecord()}
+			}
+		}
+
+		return nil, err
+	}
+
+	return dns.Get(ctx, record.Domain)
+}
+
+// Replace adds the hosts line for record, then removes the one of old
+func (dns localDNS) Replace(ctx context.Context, old DNSRecord, record *DNSRecord) (updated *DNSRecord, err error) {
+	if record.HasTTL {
+		if err := validateTTL(record.TTL); err != nil {
+			return nil, err
+		}
+	}
+
+	if old.Equal(*record) {
+		return dns.lookup(ctx, old.Domain, old.IP)
+	}
+
+	defer func() {
+		dns.client.afterMutation(ctx, Mutation{Operation: AuditLocalDNSUpdate, Target: old.Domain, Before: old, After: auditValue(updated)}, err)
+	}()
+
+	if err := dns.client.beforeMutation(ctx, Mutation{Operation: AuditLocalDNSUpdate, Target: old.Domain, Before: old, After: *record}); err != nil {
+		return nil, err
+	}
+
+	if err := dns.put(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to add DNS record %s replacing %s %s: %w", record.Domain, old.Domain, old.IP, err)
+	}
+
+	if err := dns.remove(ctx, &old); err != nil {
+		return nil, fmt.Errorf("failed to remove the replaced DNS record %s %s, both entries remain: %w", old.Domain, old.IP, err)
+	}
+
+	return dns.lookup(ctx, record.Domain, record.IP)
+}
+
+// lookup returns the record whose domain and IP match.
+func (dns localDNS) lookup(ctx context.Context, domain string, IP string) (*DNSRecord, error) {
+	named, err := dns.named(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, record := range named {
+		if sameDomain(record.Domain, domain) && normalizeIP(record.IP) == normalizeIP(IP) {
+			return &record, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrorLocalDNSNotFound, domain, IP)
+}
+
+// put adds the hosts line for record. A retried request that the server already
+// applied counts as success.
+func (dns localDNS) put(ctx context.Context, record *DNSRecord) error {
+	value := url.PathEscape(encodeHost(dns.client.recordCodec(), record))
+
+	res, done, err := dns.client.mutate(ctx, http.MethodPut, fmt.Sprintf("/api/config/dns/hosts/%s", value), nil, func(ctx context.Context) (bool, error) {
+		return dns.exists(ctx, record)
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusCreated {
 		b, _ := io.ReadAll(res.Body)
-		return nil, newDNSAPIError(res.StatusCode, b)
+		return newDNSAPIError(res, b)
 	}
 
-	// if !dnsRes.Success {
-	// 	return nil, fmt.Errorf("failed to create DNS record %s %s : %s : %w", domain, IP, dnsRes.Message, err)
-	// }
-
-	return dns.Get(ctx, domain)
+	return nil
 }
 
-// Get returns a custom DNS record by its domain name
+// Get returns a custom DNS record by its domain name, streaming the host lines so
+// that large configs are not held in memory. Only lines that may name the domain
+// are parsed; the others are just checked for validity unless parsing leniently.
 func (dns localDNS) Get(ctx context.Context, domain string) (*DNSRecord, error) {
-	records, err := dns.List(ctx)
+	skip := func(entry string) bool {
+		return !dns.client.mayName(entry, domain) && (dns.client.lenientParsing || validHostLine(entry))
+	}
+
+	var found *DNSRecord
+	err := dns.each(ctx, skip, func(record DNSRecord) error {
+		// Keep the match List would sort first.
+		if record.hasName(domain) && (found == nil || lessDNSRecord(record, *found)) {
+			found = &record
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch custom DNS records: %w", err)
 	}
 
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrorLocalDNSNotFound, domain)
+	}
+
+	return found, nil
+}
+
+// named returns every record answering for domain, in the order of the hosts config.
+func (dns localDNS) named(ctx context.Context, domain string) ([]DNSRecord, error) {
+	skip := func(entry string) bool {
+		return !dns.client.mayName(entry, domain) && (dns.client.lenientParsing || validHostLine(entry))
+	}
+
+	records := make([]DNSRecord, 0)
+	err := dns.each(ctx, skip, func(record DNSRecord) error {
+		if record.hasName(domain) {
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch custom DNS records: %w", err)
+	}
+
+	return records, nil
+}
+
+// find returns the record matching domain and IP, or nil if there is none or the
+// records cannot be fetched.
+func (dns localDNS) find(ctx context.Context, domain string, IP string) *DNSRecord {
+	records, err := dns.List(ctx)
+	if err != nil {
+		return nil
+	}
+
 	for _, record := range records {
-		if strings.EqualFold(record.Domain, domain) {
-			return &record, nil
+		if record.hasName(domain) && normalizeIP(record.IP) == normalizeIP(IP) {
+			return &record
 		}
 	}
 
-	return nil, fmt.Errorf("%w: %s", ErrorLocalDNSNotFound, domain)
+	return nil
+}
+
+// exists reports whether the hosts config holds exactly the entry for record.
+func (dns localDNS) exists(ctx context.Context, record *DNSRecord) (bool, error) {
+	records, err := dns.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, candidate := range records {
+		if candidate.Equal(*record) {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 // Delete removes a custom DNS record
@@ -181,18 +480,73 @@ func (dns localDNS) Delete(ctx context.Context, domain string) error {
 		return fmt.Errorf("failed looking up custom DNS record %s for deletion: %w", domain, err)
 	}
 
-	value := fmt.Sprintf("%s%%20%s", record.IP, record.Domain)
+	return dns.delete(ctx, record)
+}
+
+// GetByID returns the custom DNS record with the given ID
+func (dns localDNS) GetByID(ctx context.Context, id string) (*DNSRecord, error) {
+	records, err := dns.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch custom DNS records: %w", err)
+	}
+
+	for _, record := range records {
+		if record.ID() == id {
+			return &record, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrorLocalDNSNotFound, id)
+}
 
-	res, err := dns.client.Delete(ctx, fmt.Sprintf("/api/config/dns/hosts/%s", value))
+// DeleteByID removes the custom DNS record with the given ID
+func (dns localDNS) DeleteByID(ctx context.Context, id string) error {
+	record, err := dns.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrorLocalDNSNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed looking up custom DNS record %s for deletion: %w", id, err)
+	}
+
+	return dns.delete(ctx, record)
+}
+
+func (dns localDNS) delete(ctx context.Context, record *DNSRecord) (err error) {
+	m := Mutation{Operation: AuditLocalDNSDelete, Target: record.Domain, Before: *record}
+	defer func() {
+		dns.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := dns.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	return dns.remove(ctx, record)
+}
+
+// remove deletes the hosts line of record. A retried request that the server already
+// applied counts as success.
+func (dns localDNS) remove(ctx context.Context, record *DNSRecord) error {
+	value := url.PathEscape(encodeHost(dns.client.recordCodec(), record))
+
+	res, done, err := dns.client.mutate(ctx, http.MethodDelete, fmt.Sprintf("/api/config/dns/hosts/%s", value), nil, func(ctx context.Context) (bool, error) {
+		exists, err := dns.exists(ctx, record)
+		return !exists, err
+	})
 	if err != nil {
 		return err
 	}
+	if done {
+		return nil
+	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusNoContent {
 		b, _ := io.ReadAll(res.Body)
-		return newDNSAPIError(res.StatusCode, b)
+		return newDNSAPIError(res, b)
 	}
 
 	return nil
@@ -155,3 +155,186 @@ func TestLocalDNS_CreateReturnsAPIError(t *testing.T) {
 	assert.Equal(t, "bad_request", apiErr.Key)
 	assert.Equal(t, "duplicate", apiErr.Message)
 }
+
+func TestLocalDNS_CreateReturnsDuplicateRecordError(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/api/config/dns/hosts/"):
+			return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"Item already present","hint":"Uniqueness of items is enforced"}}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["127.0.0.1 example.com # web"]}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	_, err = client.LocalDNS.Create(context.Background(), "Example.com", "127.0.0.1")
+	require.ErrorIs(t, err, ErrDuplicateRecord)
+
+	var dupErr *DuplicateRecordError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, LocalRecord{Kind: LocalRecordA, Name: "example.com", Value: "127.0.0.1", Comment: "web"}, dupErr.Existing)
+
+	_, err = client.LocalDNS.Create(context.Background(), "example.com", "127.0.0.2")
+	assert.NotErrorIs(t, err, ErrDuplicateRecord)
+	var apiErr *DNSAPIError
+	assert.ErrorAs(t, err, &apiErr)
+}
+
+func TestParseDNSRecordAliases(t *testing.T) {
+	record, err := parseDNSRecord("10.0.0.1 nas.lan storage.lan backup.lan # rack 2")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan", record.Domain)
+	assert.Equal(t, []string{"storage.lan", "backup.lan"}, record.Aliases)
+	assert.Equal(t, "rack 2", record.Comment)
+	assert.False(t, record.HasTTL)
+	assert.Equal(t, []string{"nas.lan", "storage.lan", "backup.lan"}, record.Names())
+
+	record, err = parseDNSRecord("10.0.0.1 nas.lan 3600 storage.lan")
+	require.NoError(t, err)
+	assert.Equal(t, 3600, record.TTL)
+	assert.Equal(t, []string{"storage.lan"}, record.Aliases)
+
+	record, err = parseDNSRecord(" \t10.0.0.1\tnas.lan  1password.lan\t")
+	require.NoError(t, err)
+	assert.False(t, record.HasTTL)
+	assert.Equal(t, []string{"1password.lan"}, record.Aliases)
+
+	record, err = parseDNSRecord("10.0.0.1 nas.lan#no space")
+	require.NoError(t, err)
+	assert.Nil(t, record.Aliases)
+	assert.Equal(t, "no space", record.Comment)
+
+	_, err = parseDNSRecord("  10.0.0.1  # nas.lan")
+	assert.Error(t, err)
+
+	assert.Equal(t, "10.0.0.1 nas.lan storage.lan", encodeHost(DefaultRecordCodec, &DNSRecord{IP: "10.0.0.1", Domain: "nas.lan", Aliases: []string{"storage.lan"}}))
+}
+
+func TestLocalDNS_GetAndDeleteByAliasKeepFullLine(t *testing.T) {
+	isUnit(t)
+
+	const line = "10.0.0.1 nas.lan storage.lan # rack 2"
+	var deletedPath string
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["`+line+`"]}}}`), nil
+		case req.Method == http.MethodDelete:
+			deletedPath = req.URL.Path
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{
+		BaseURL:    "http://pi.test",
+		SessionID:  "test",
+		HttpClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	record, err := client.LocalDNS.Get(context.Background(), "storage.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan", record.Domain)
+
+	require.NoError(t, client.LocalDNS.Delete(context.Background(), "storage.lan"))
+	assert.Equal(t, "/api/config/dns/hosts/"+line, deletedPath)
+}
+
+func TestLocalDNS_LenientParsingSkipsInvalidLines(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan","garbage","10.0.0.2 printer.lan"]}}}`), nil
+		case "/api/config/dns/cnameRecords":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.lan,nas.lan","broken"]}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	strict, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+	_, err = strict.LocalDNS.Get(context.Background(), "printer.lan")
+	assert.Error(t, err)
+
+	lenient, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient, LenientParsing: true})
+	require.NoError(t, err)
+
+	record, err := lenient.LocalDNS.Get(context.Background(), "printer.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", record.IP)
+
+	cnames, err := lenient.LocalCNAME.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, cnames, 1)
+}
+
+// benchmarkHosts returns n hosts lines in the mix seen on large installs: plain
+// lines, lines with a TTL, aliases and a comment shared by many records.
+func benchmarkHosts(n int) []string {
+	hosts := make([]string, n)
+	for i := range hosts {
+		ip := fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff)
+		switch i % 4 {
+		case 0:
+			hosts[i] = fmt.Sprintf("%s host%d.lan", ip, i)
+		case 1:
+			hosts[i] = fmt.Sprintf("%s host%d.lan 300", ip, i)
+		case 2:
+			hosts[i] = fmt.Sprintf("%s host%d.lan alias%d.lan # managed by sync", ip, i, i)
+		default:
+			hosts[i] = fmt.Sprintf("  %s\thost%d.lan 60 a%d.lan b%d.lan # rack %d", ip, i, i, i, i%8)
+		}
+	}
+
+	return hosts
+}
+
+func BenchmarkDNSRecordListResponse_toDNSRecordList(b *testing.B) {
+	resp := dnsRecordListResponse{Config: dnsRecordConfigListResponse{DNS: dnsRecordDNSListResponse{Hosts: benchmarkHosts(50000)}}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := resp.toDNSRecordList(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLocalDNS_Replace(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"10.0.0.1 nas.lan # rack 2", "fd00::1 nas.lan"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &entries, &ops)
+
+	records, err := client.LocalDNS.List(context.Background())
+	require.NoError(t, err)
+
+	record, err := client.LocalDNS.Replace(context.Background(), records[0], &DNSRecord{Domain: "nas.lan", IP: "10.0.0.2", Comment: "rack 3"})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", record.IP)
+	assert.Equal(t, []string{"PUT 10.0.0.2 nas.lan # rack 3", "DELETE 10.0.0.1 nas.lan # rack 2"}, ops)
+	assert.Equal(t, []string{"fd00::1 nas.lan", "10.0.0.2 nas.lan # rack 3"}, entries)
+
+	ops = nil
+	_, err = client.LocalDNS.Replace(context.Background(), *record, &DNSRecord{Domain: "NAS.lan.", IP: "10.0.0.2", Comment: "rack 3"})
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
@@ -0,0 +1,163 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+)
+
+type LocalRecords interface {
+	// List fetches the local DNS and CNAME records concurrently.
+	List(ctx context.Context) (*LocalRecordSet, error)
+
+	// Get returns every host and CNAME record for name.
+	Get(ctx context.Context, name string) (LocalRecordList, error)
+}
+
+var (
+	ErrorLocalRecordNotFound = newNotFoundError("local record not found")
+)
+
+type localRecords struct {
+	client *Client
+}
+
+// LocalRecordSet holds both kinds of local records as stored in Pi-hole's config.
+type LocalRecordSet struct {
+	DNS   DNSRecordList
+	CNAME CNAMERecordList
+}
+
+// LocalRecordKind is the DNS record type of a local record.
+type LocalRecordKind string
+
+const (
+	LocalRecordA     LocalRecordKind = "A"
+	LocalRecordAAAA  LocalRecordKind = "AAAA"
+	LocalRecordCNAME LocalRecordKind = "CNAME"
+)
+
+// LocalRecord is a single local record regardless of which of Pi-hole's config
+// arrays it is stored in. Value is the IP address of A and AAAA records and the
+// target of CNAME records. Comment is only kept by host records.
+type LocalRecord struct {
+	Kind  LocalRecordKind
+	Name  string
+	Value string
+
+	// TTL and HasTTL are serialized as a single optional ttl field.
+	TTL     int
+	HasTTL  bool
+	Comment string
+}
+
+type LocalRecordList []LocalRecord
+
+// LocalRecord returns the record as a LocalRecord of kind A or AAAA.
+func (r DNSRecord) LocalRecord() LocalRecord {
+	kind := LocalRecordA
+	if addr, err := netip.ParseAddr(r.IP); err == nil && !addr.Unmap().Is4() {
+		kind = LocalRecordAAAA
+	}
+
+	return LocalRecord{Kind: kind, Name: r.Domain, Value: r.IP, TTL: r.TTL, HasTTL: r.HasTTL, Comment: r.Comment}
+}
+
+// LocalRecord returns the record as a LocalRecord of kind CNAME.
+func (r CNAMERecord) LocalRecord() LocalRecord {
+	return LocalRecord{Kind: LocalRecordCNAME, Name: r.Domain, Value: r.Target, TTL: r.TTL, HasTTL: r.HasTTL}
+}
+
+// DNSRecord converts an A or AAAA record back to a host record.
+func (r LocalRecord) DNSRecord() (*DNSRecord, error) {
+	if r.Kind != LocalRecordA && r.Kind != LocalRecordAAAA {
+		return nil, fmt.Errorf("cannot convert %s record %s to a DNS host record", r.Kind, r.Name)
+	}
+
+	return &DNSRecord{IP: r.Value, Domain: r.Name, TTL: r.TTL, HasTTL: r.HasTTL, Comment: r.Comment}, nil
+}
+
+// CNAMERecord converts a CNAME record back to Pi-hole's CNAME form.
+func (r LocalRecord) CNAMERecord() (*CNAMERecord, error) {
+	if r.Kind != LocalRecordCNAME {
+		return nil, fmt.Errorf("cannot convert %s record %s to a CNAME record", r.Kind, r.Name)
+	}
+
+	return &CNAMERecord{Domain: r.Name, Target: r.Value, TTL: r.TTL, HasTTL: r.HasTTL}, nil
+}
+
+// Records returns the host records followed by the CNAME records as LocalRecords.
+// Each alias of a host record is returned as a record of its own.
+func (s LocalRecordSet) Records() LocalRecordList {
+	list := make(LocalRecordList, 0, len(s.DNS)+len(s.CNAME))
+	for _, record := range s.DNS {
+		local := record.LocalRecord()
+		list = append(list, local)
+		for _, alias := range record.Aliases {
+			local.Name = alias
+			list = append(list, local)
+		}
+	}
+	for _, record := range s.CNAME {
+		list = append(list, record.LocalRecord())
+	}
+
+	return list
+}
+
+// List returns the DNS and CNAME records, fetching both lists in parallel
+func (r localRecords) List(ctx context.Context) (*LocalRecordSet, error) {
+	var (
+		set      LocalRecordSet
+		dnsErr   error
+		cnameErr error
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		set.DNS, dnsErr = r.client.LocalDNS.List(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		set.CNAME, cnameErr = r.client.LocalCNAME.List(ctx)
+	}()
+	wg.Wait()
+
+	if dnsErr != nil {
+		dnsErr = fmt.Errorf("failed to fetch custom DNS records: %w", dnsErr)
+	}
+	if cnameErr != nil {
+		cnameErr = fmt.Errorf("failed to fetch custom CNAME records: %w", cnameErr)
+	}
+	if err := errors.Join(dnsErr, cnameErr); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// Get returns the host and CNAME records for a name
+func (r localRecords) Get(ctx context.Context, name string) (LocalRecordList, error) {
+	set, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(LocalRecordList, 0)
+	for _, record := range set.Records() {
+		if sameDomain(record.Name, name) {
+			found = append(found, record)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrorLocalRecordNotFound, name)
+	}
+	found.Sort()
+
+	return found, nil
+}
@@ -0,0 +1,112 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRecords_ListFetchesConcurrently(t *testing.T) {
+	isUnit(t)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// Each request waits for the other to start, so a sequential fetch times out.
+		wg.Done()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("request %s was not sent concurrently", req.URL.Path)
+		}
+
+		switch req.URL.Path {
+		case "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan"]}}}`), nil
+		case "/api/config/dns/cnameRecords":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.lan,nas.lan"]}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	set, err := client.LocalRecords.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, set.DNS, 1)
+	require.Len(t, set.CNAME, 1)
+	assert.Equal(t, "nas.lan", set.DNS[0].Domain)
+	assert.Equal(t, "nas.lan", set.CNAME[0].Target)
+}
+
+func TestLocalRecordConversions(t *testing.T) {
+	a := DNSRecord{IP: "10.0.0.1", Domain: "nas.lan", TTL: 300, HasTTL: true, Comment: "storage"}.LocalRecord()
+	assert.Equal(t, LocalRecord{Kind: LocalRecordA, Name: "nas.lan", Value: "10.0.0.1", TTL: 300, HasTTL: true, Comment: "storage"}, a)
+
+	aaaa := DNSRecord{IP: "fd00::1", Domain: "nas.lan"}.LocalRecord()
+	assert.Equal(t, LocalRecordAAAA, aaaa.Kind)
+
+	cname := CNAMERecord{Domain: "www.lan", Target: "nas.lan"}.LocalRecord()
+	assert.Equal(t, LocalRecordCNAME, cname.Kind)
+	assert.Equal(t, "nas.lan", cname.Value)
+
+	dns, err := a.DNSRecord()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", dns.IP)
+	assert.Equal(t, "storage", dns.Comment)
+
+	_, err = a.CNAMERecord()
+	assert.Error(t, err)
+
+	back, err := cname.CNAMERecord()
+	require.NoError(t, err)
+	assert.Equal(t, "www.lan", back.Domain)
+
+	_, err = cname.DNSRecord()
+	assert.Error(t, err)
+}
+
+func TestLocalRecords_GetSearchesBothSets(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan","fd00::1 nas.lan"]}}}`), nil
+		case "/api/config/dns/cnameRecords":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.lan,nas.lan"]}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	records, err := client.LocalRecords.Get(context.Background(), "NAS.lan")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, LocalRecordA, records[0].Kind)
+	assert.Equal(t, LocalRecordAAAA, records[1].Kind)
+
+	records, err = client.LocalRecords.Get(context.Background(), "www.lan")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, LocalRecordCNAME, records[0].Kind)
+
+	_, err = client.LocalRecords.Get(context.Background(), "missing.lan")
+	assert.ErrorIs(t, err, ErrorLocalRecordNotFound)
+}
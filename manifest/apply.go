@@ -0,0 +1,469 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+)
+
+// defaultGroup is the group Pi-hole creates itself, which always has ID 0.
+const defaultGroup = "Default"
+
+// Action is what Apply did with a manifest entry.
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionDeleted   Action = "deleted"
+	ActionFailed    Action = "failed"
+)
+
+// Change is the outcome of applying one manifest entry.
+type Change struct {
+	// Kind is the manifest section of the entry: config, group, dns, cname or domain.
+	Kind   string
+	Name   string
+	Action Action
+	Err    error
+}
+
+// Result lists the outcome of every entry in the order they were applied.
+type Result struct {
+	Changes []Change
+}
+
+// Failed returns the entries that could not be applied.
+func (r *Result) Failed() []Change {
+	failed := make([]Change, 0)
+	for _, change := range r.Changes {
+		if change.Action == ActionFailed {
+			failed = append(failed, change)
+		}
+	}
+
+	return failed
+}
+
+// ApplyOptions controls how Apply reconciles an instance.
+type ApplyOptions struct {
+	// Prune makes the manifest authoritative: the groups, records and domains it
+	// does not list are deleted from the instance, except the Default group.
+	// Settings are never pruned.
+	Prune bool
+}
+
+func (r *Result) add(kind string, name string, action Action, err error) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Name: name, Action: action, Err: err})
+}
+
+// Apply patches the settings listed in the manifest's config section that differ on
+// the instance, then creates the groups, records and domains of the manifest that
+// are missing and updates those that differ. Groups are matched by name, DNS records
+// by domain and IP, CNAME records by domain and allow/deny domains by domain, type
+// and kind. Entries the manifest does not list are left alone unless opts.Prune is
+// set; nil opts apply the defaults. Apply continues past individual failures and
+// returns them joined in the error, alongside the full result.
+func Apply(ctx context.Context, client *pihole.Client, m *Manifest, opts *ApplyOptions) (*Result, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+
+	result := &Result{Changes: make([]Change, 0)}
+
+	if err := applyConfig(ctx, client, m.Config, result); err != nil {
+		return result, err
+	}
+
+	groupIDs, err := applyGroups(ctx, client, m.Groups, result)
+	if err != nil {
+		return result, err
+	}
+
+	if err := applyDNSRecords(ctx, client, m.DNSRecords, result); err != nil {
+		return result, err
+	}
+
+	if err := applyCNAMERecords(ctx, client, m.CNAMERecords, result); err != nil {
+		return result, err
+	}
+
+	if err := applyDomains(ctx, client, m.Domains, groupIDs, result); err != nil {
+		return result, err
+	}
+
+	if opts.Prune {
+		if err := prune(ctx, client, m, result); err != nil {
+			return result, err
+		}
+	}
+
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return result, nil
+	}
+
+	errs := make([]error, 0, len(failed))
+	for _, change := range failed {
+		errs = append(errs, fmt.Errorf("%s %s: %w", change.Kind, change.Name, change.Err))
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// applyConfig patches the settings that differ from config. Settings the manifest
+// does not list are left alone.
+func applyConfig(ctx context.Context, client *pihole.Client, config map[string]any, result *Result) error {
+	if len(config) == 0 {
+		return nil
+	}
+
+	// Decode the settings the way the API returns them, so that numbers loaded from
+	// YAML compare equal to the instance's.
+	b, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	var desired map[string]any
+	if err := json.Unmarshal(b, &desired); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	changes, err := client.Config.Restore(ctx, &pihole.ConfigSnapshot{Config: desired})
+	if err != nil {
+		return fmt.Errorf("failed to apply config: %w", err)
+	}
+
+	for _, change := range changes {
+		result.add("config", change.Path, ActionUpdated, nil)
+	}
+
+	return nil
+}
+
+func applyGroups(ctx context.Context, client *pihole.Client, groups []Group, result *Result) (map[string]int, error) {
+	existing, err := client.Groups.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	ids := map[string]int{defaultGroup: 0}
+	current := make(map[string]pihole.Group)
+	for _, group := range existing {
+		ids[group.Name] = int(group.ID)
+		current[group.Name] = group
+	}
+
+	for _, group := range groups {
+		entry := pihole.GroupEntry{Name: group.Name, Comment: group.Comment, Disabled: group.Disabled}
+		existing, ok := current[group.Name]
+
+		switch {
+		case !ok && group.Name == defaultGroup, ok && existing.Comment == group.Comment && existing.Enabled == !group.Disabled:
+			result.add("group", group.Name, ActionUnchanged, nil)
+		case ok:
+			if _, err := client.Groups.Update(ctx, group.Name, entry); err != nil {
+				result.add("group", group.Name, ActionFailed, err)
+				continue
+			}
+			result.add("group", group.Name, ActionUpdated, nil)
+		default:
+			created, err := client.Groups.Create(ctx, entry)
+			if err != nil {
+				result.add("group", group.Name, ActionFailed, err)
+				continue
+			}
+			ids[group.Name] = int(created.ID)
+			result.add("group", group.Name, ActionCreated, nil)
+		}
+	}
+
+	return ids, nil
+}
+
+// applyDNSRecords pairs the records of each domain in the manifest with those on the
+// instance: first by IP, then, for a record whose IP changed, with a record of the
+// domain that no other manifest entry claims, which is replaced.
+func applyDNSRecords(ctx context.Context, client *pihole.Client, records []pihole.DNSRecord, result *Result) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	existing, err := client.LocalDNS.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch DNS records: %w", err)
+	}
+
+	claimed := make([]bool, len(existing))
+	matches := make([]int, len(records))
+	for i, record := range records {
+		matches[i] = slices.IndexFunc(existing, func(candidate pihole.DNSRecord) bool {
+			return sameHost(candidate, record) && normalizeIP(candidate.IP) == normalizeIP(record.IP)
+		})
+		if matches[i] >= 0 {
+			claimed[matches[i]] = true
+		}
+	}
+	for i, record := range records {
+		if matches[i] >= 0 {
+			continue
+		}
+		for j, candidate := range existing {
+			if !claimed[j] && sameHost(candidate, record) {
+				matches[i], claimed[j] = j, true
+				break
+			}
+		}
+	}
+
+	for i, record := range records {
+		name := record.Domain + " " + record.IP
+
+		switch {
+		case matches[i] < 0:
+			if _, err := client.LocalDNS.CreateRecord(ctx, &record); err != nil {
+				result.add("dns", name, ActionFailed, err)
+				continue
+			}
+			result.add("dns", name, ActionCreated, nil)
+		case existing[matches[i]].Equal(record):
+			result.add("dns", name, ActionUnchanged, nil)
+		default:
+			if _, err := client.LocalDNS.Replace(ctx, existing[matches[i]], &record); err != nil {
+				result.add("dns", name, ActionFailed, err)
+				continue
+			}
+			result.add("dns", name, ActionUpdated, nil)
+		}
+	}
+
+	return nil
+}
+
+func applyCNAMERecords(ctx context.Context, client *pihole.Client, records []pihole.CNAMERecord, result *Result) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	existing, err := client.LocalCNAME.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CNAME records: %w", err)
+	}
+
+	for _, record := range records {
+		current := cnameRecordsFor(existing, record)
+
+		switch {
+		case len(current) == 1 && current[0].Equal(record):
+			result.add("cname", record.Domain, ActionUnchanged, nil)
+		case len(current) > 0:
+			if _, err := client.LocalCNAME.Update(ctx, &record); err != nil {
+				result.add("cname", record.Domain, ActionFailed, err)
+				continue
+			}
+			result.add("cname", record.Domain, ActionUpdated, nil)
+		default:
+			if _, err := client.LocalCNAME.CreateRecord(ctx, &record); err != nil {
+				result.add("cname", record.Domain, ActionFailed, err)
+				continue
+			}
+			result.add("cname", record.Domain, ActionCreated, nil)
+		}
+	}
+
+	return nil
+}
+
+func applyDomains(ctx context.Context, client *pihole.Client, domains []Domain, groupIDs map[string]int, result *Result) error {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	list, err := client.Domains.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch domains: %w", err)
+	}
+
+	existing := make(map[string]pihole.Domain, len(list))
+	for _, domain := range list {
+		existing[domainKey(Domain{Domain: domain.Domain, Type: domain.Type, Kind: domain.Kind})] = domain
+	}
+
+	for _, kind := range []pihole.DomainKind{pihole.DomainKindExact, pihole.DomainKindRegex} {
+		entries := make([]pihole.DomainEntry, 0)
+		for _, domain := range domains {
+			domainKind := domain.Kind
+			if domainKind == "" {
+				domainKind = pihole.DomainKindExact
+			}
+			if domainKind != kind {
+				continue
+			}
+
+			entry := pihole.DomainEntry{Domain: domain.Domain, Type: domain.Type, Comment: domain.Comment, Disabled: domain.Disabled}
+			for _, name := range domain.Groups {
+				if id, ok := groupIDs[name]; ok {
+					entry.Groups = append(entry.Groups, id)
+				}
+			}
+
+			current, ok := existing[domainKey(domain)]
+			if !ok {
+				entries = append(entries, entry)
+				continue
+			}
+
+			// Pi-hole assigns entries added without groups to the Default group.
+			if len(entry.Groups) == 0 {
+				entry.Groups = []int{0}
+			}
+			if sameDomainEntry(current, entry) {
+				result.add("domain", domain.Domain, ActionUnchanged, nil)
+				continue
+			}
+
+			if _, err := client.Domains.Update(ctx, current.ID, entry); err != nil {
+				result.add("domain", domain.Domain, ActionFailed, err)
+			} else {
+				result.add("domain", domain.Domain, ActionUpdated, nil)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		// Failed entries are reported through their results; only a cancelled
+		// context stops the apply.
+		results, _ := client.Domains.AddBatch(ctx, kind, entries)
+
+		for _, res := range results {
+			switch res.Status {
+			case pihole.DomainBatchCreated:
+				result.add("domain", res.Entry.Domain, ActionCreated, nil)
+			case pihole.DomainBatchDuplicate:
+				result.add("domain", res.Entry.Domain, ActionUnchanged, nil)
+			default:
+				result.add("domain", res.Entry.Domain, ActionFailed, res.Err)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameHost reports whether both records are for the same domain.
+func sameHost(a pihole.DNSRecord, b pihole.DNSRecord) bool {
+	return a.Normalize().Domain == b.Normalize().Domain
+}
+
+// normalizeIP returns the canonical form of an address, as DNSRecord.Normalize does.
+func normalizeIP(ip string) string {
+	return pihole.DNSRecord{IP: ip}.Normalize().IP
+}
+
+// cnameRecordsFor returns the records of list for the domain of record.
+func cnameRecordsFor(list pihole.CNAMERecordList, record pihole.CNAMERecord) []pihole.CNAMERecord {
+	key := record.Normalize().Domain
+	found := make([]pihole.CNAMERecord, 0)
+	for _, existing := range list {
+		if existing.Normalize().Domain == key {
+			found = append(found, existing)
+		}
+	}
+
+	return found
+}
+
+// sameDomainEntry reports whether domain already has the comment, groups and
+// enabled state of entry.
+func sameDomainEntry(domain pihole.Domain, entry pihole.DomainEntry) bool {
+	current := slices.Clone(domain.Groups)
+	desired := slices.Clone(entry.Groups)
+	slices.Sort(current)
+	slices.Sort(desired)
+
+	return domain.Comment == entry.Comment && domain.Enabled == !entry.Disabled && slices.Equal(slices.Compact(current), slices.Compact(desired))
+}
+
+// prune deletes the records, domains and groups of the instance that m does not
+// list. Domains go before groups, so that no entry is left pointing at a deleted
+// group.
+func prune(ctx context.Context, client *pihole.Client, m *Manifest, result *Result) error {
+	deleted := func(kind string, name string, err error) {
+		if err != nil {
+			result.add(kind, name, ActionFailed, err)
+			return
+		}
+		result.add(kind, name, ActionDeleted, nil)
+	}
+
+	hosts, err := client.LocalDNS.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch DNS records: %w", err)
+	}
+	for _, record := range hosts {
+		if slices.ContainsFunc(m.DNSRecords, func(desired pihole.DNSRecord) bool {
+			return sameHost(desired, record) && normalizeIP(desired.IP) == normalizeIP(record.IP)
+		}) {
+			continue
+		}
+		deleted("dns", record.Domain+" "+record.IP, client.LocalDNS.DeleteByID(ctx, record.ID()))
+	}
+
+	cnames, err := client.LocalCNAME.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CNAME records: %w", err)
+	}
+	for _, record := range cnames {
+		if slices.ContainsFunc(m.CNAMERecords, func(desired pihole.CNAMERecord) bool {
+			return desired.Normalize().Domain == record.Normalize().Domain
+		}) {
+			continue
+		}
+		deleted("cname", record.Domain, client.LocalCNAME.DeleteByDomainTarget(ctx, record.Domain, record.Target))
+	}
+
+	domains, err := client.Domains.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch domains: %w", err)
+	}
+	keys := make(map[string]bool, len(m.Domains))
+	for _, domain := range m.Domains {
+		keys[domainKey(domain)] = true
+	}
+	for _, domain := range domains {
+		if keys[domainKey(Domain{Domain: domain.Domain, Type: domain.Type, Kind: domain.Kind})] {
+			continue
+		}
+		deleted("domain", domain.Domain, client.Domains.Delete(ctx, domain.ID))
+	}
+
+	groups, err := client.Groups.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	for _, group := range groups {
+		if group.ID == 0 || slices.ContainsFunc(m.Groups, func(desired Group) bool { return desired.Name == group.Name }) {
+			continue
+		}
+		deleted("group", group.Name, client.Groups.Delete(ctx, group.Name))
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,284 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/awaybreaktoday/lib-pihole-go/piholetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCreatesMissingEntries(t *testing.T) {
+	var (
+		hosts       = []string{"10.0.0.1 nas.lan storage.lan # rack 2"}
+		cnames      []string
+		groupsJSON  = `{"groups":[{"id":0,"name":"Default","enabled":true}]}`
+		domainPosts []map[string]interface{}
+	)
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		path, _ := url.PathUnescape(req.URL.EscapedPath())
+		switch {
+		case req.Method == http.MethodGet && path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, groupsJSON), nil
+		case req.Method == http.MethodPost && path == "/api/groups":
+			groupsJSON = `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids","enabled":true}]}`
+			return piholetest.JSONResponse(http.StatusCreated, `{"groups":[],"processed":{"success":[{"item":"kids"}],"errors":[]}}`), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/hosts":
+			return piholetest.JSONResponse(http.StatusOK, map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"hosts": hosts}}}), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/cnameRecords":
+			return piholetest.JSONResponse(http.StatusOK, map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"cnameRecords": cnames}}}), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(path, "/api/config/dns/cnameRecords/"):
+			cnames = append(cnames, strings.TrimPrefix(path, "/api/config/dns/cnameRecords/"))
+			return piholetest.JSONResponse(http.StatusCreated, `{}`), nil
+		case req.Method == http.MethodGet && path == "/api/domains":
+			return piholetest.JSONResponse(http.StatusOK, `{"domains":[]}`), nil
+		case req.Method == http.MethodPost && strings.HasPrefix(path, "/api/domains/"):
+			b, _ := io.ReadAll(req.Body)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(b, &body))
+			domainPosts = append(domainPosts, body)
+			return piholetest.JSONResponse(http.StatusCreated, `{"domains":[],"processed":{"success":[{"item":"x"}],"errors":[]}}`), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	m, err := Load(strings.NewReader(exampleYAML))
+	require.NoError(t, err)
+
+	result, err := Apply(context.Background(), client, m, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed())
+
+	actions := make(map[string]Action)
+	for _, change := range result.Changes {
+		actions[change.Kind+" "+change.Name] = change.Action
+	}
+	assert.Equal(t, map[string]Action{
+		"group kids":                     ActionCreated,
+		"dns nas.lan 10.0.0.1":           ActionUnchanged,
+		"cname www.lan":                  ActionCreated,
+		"domain ads.example":             ActionCreated,
+		`domain (\.|^)tracker\.example$`: ActionCreated,
+	}, actions)
+
+	assert.Equal(t, []string{"www.lan,nas.lan,300"}, cnames)
+	require.Len(t, domainPosts, 2)
+	assert.Equal(t, []interface{}{float64(4), float64(0)}, domainPosts[0]["groups"])
+}
+
+func TestApplyPatchesConfig(t *testing.T) {
+	config := map[string]interface{}{"dns": map[string]interface{}{"port": 53, "upstreams": []interface{}{"1.1.1.1"}}}
+	var patches []map[string]interface{}
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config":
+			return piholetest.JSONResponse(http.StatusOK, map[string]interface{}{"config": config}), nil
+		case req.Method == http.MethodPatch && req.URL.Path == "/api/config":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			patches = append(patches, body)
+			return piholetest.JSONResponse(http.StatusOK, `{}`), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	m, err := Load(strings.NewReader(`
+version: 1
+config:
+  dns:
+    port: 53
+    upstreams: [9.9.9.9]
+`))
+	require.NoError(t, err)
+
+	result, err := Apply(context.Background(), client, m, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Change{{Kind: "config", Name: "dns.upstreams", Action: ActionUpdated}}, result.Changes)
+	assert.Equal(t, []map[string]interface{}{
+		{"config": map[string]interface{}{"dns": map[string]interface{}{"upstreams": []interface{}{"9.9.9.9"}}}},
+	}, patches)
+}
+
+func TestApplyUpdatesChangedEntries(t *testing.T) {
+	var (
+		hosts   = []string{"10.0.0.1 nas.lan # rack 2", "fd00::1 nas.lan", "10.0.0.9 printer.lan"}
+		cnames  = []string{"www.lan,old.lan"}
+		ops     []string
+		updates = make(map[string]map[string]interface{})
+	)
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		path, _ := url.PathUnescape(req.URL.EscapedPath())
+		switch {
+		case req.Method == http.MethodGet && path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids","comment":"Kids","enabled":true}]}`), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/hosts":
+			return piholetest.JSONResponse(http.StatusOK, map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"hosts": hosts}}}), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/cnameRecords":
+			return piholetest.JSONResponse(http.StatusOK, map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"cnameRecords": cnames}}}), nil
+		case req.Method == http.MethodGet && path == "/api/domains":
+			return piholetest.JSONResponse(http.StatusOK, `{"domains":[{"id":7,"domain":"ads.example","type":"deny","kind":"exact","groups":[0],"enabled":true}]}`), nil
+		case strings.HasPrefix(path, "/api/config/dns/hosts/"):
+			entry := strings.TrimPrefix(path, "/api/config/dns/hosts/")
+			ops = append(ops, req.Method+" "+entry)
+			if req.Method == http.MethodPut {
+				hosts = append(hosts, entry)
+				return piholetest.JSONResponse(http.StatusCreated, `{}`), nil
+			}
+			hosts = slices.DeleteFunc(hosts, func(e string) bool { return e == entry })
+			return piholetest.Response(http.StatusNoContent, ``), nil
+		case strings.HasPrefix(path, "/api/config/dns/cnameRecords/"):
+			entry := strings.TrimPrefix(path, "/api/config/dns/cnameRecords/")
+			ops = append(ops, req.Method+" "+entry)
+			if req.Method == http.MethodPut {
+				cnames = append(cnames, entry)
+				return piholetest.JSONResponse(http.StatusCreated, `{}`), nil
+			}
+			cnames = slices.DeleteFunc(cnames, func(e string) bool { return e == entry })
+			return piholetest.Response(http.StatusNoContent, ``), nil
+		case req.Method == http.MethodPut:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			updates[path] = body
+			return piholetest.JSONResponse(http.StatusOK, `{}`), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	m, err := Load(strings.NewReader(`
+version: 1
+groups:
+  - name: kids
+    comment: Kids devices
+dnsRecords:
+  - {domain: nas.lan, ip: 10.0.0.2, comment: rack 2}
+  - {domain: nas.lan, ip: "fd00::1", comment: v6}
+  - {domain: printer.lan, ip: 10.0.0.9}
+cnameRecords:
+  - {domain: www.lan, target: nas.lan}
+domains:
+  - {domain: ads.example, type: deny, disabled: true}
+`))
+	require.NoError(t, err)
+
+	result, err := Apply(context.Background(), client, m, nil)
+	require.NoError(t, err)
+
+	actions := make(map[string]Action)
+	for _, change := range result.Changes {
+		actions[change.Kind+" "+change.Name] = change.Action
+	}
+	assert.Equal(t, map[string]Action{
+		"group kids":               ActionUpdated,
+		"dns nas.lan 10.0.0.2":     ActionUpdated,
+		"dns nas.lan fd00::1":      ActionUpdated,
+		"dns printer.lan 10.0.0.9": ActionUnchanged,
+		"cname www.lan":            ActionUpdated,
+		"domain ads.example":       ActionUpdated,
+	}, actions)
+
+	assert.ElementsMatch(t, []string{"10.0.0.2 nas.lan # rack 2", "fd00::1 nas.lan # v6", "10.0.0.9 printer.lan"}, hosts)
+	assert.Equal(t, []string{"www.lan,nas.lan"}, cnames)
+	assert.Equal(t, []string{"DELETE www.lan,old.lan", "PUT www.lan,nas.lan"}, ops[len(ops)-2:])
+	assert.Equal(t, map[string]interface{}{"name": "kids", "comment": "Kids devices", "enabled": true}, updates["/api/groups/kids"])
+	assert.Equal(t, false, updates["/api/domains/deny/exact/ads.example"]["enabled"])
+	assert.Equal(t, []interface{}{float64(0)}, updates["/api/domains/deny/exact/ads.example"]["groups"])
+
+	// A second apply finds the records converged. The stub does not keep group and
+	// domain updates.
+	ops = nil
+	result, err = Apply(context.Background(), client, m, nil)
+	require.NoError(t, err)
+	for _, change := range result.Changes {
+		if change.Kind == "dns" || change.Kind == "cname" {
+			assert.Equal(t, ActionUnchanged, change.Action, change.Kind+" "+change.Name)
+		}
+	}
+	assert.Empty(t, ops)
+}
+
+func TestApplyPrunesUnlistedEntries(t *testing.T) {
+	var deletes []string
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		path, _ := url.PathUnescape(req.URL.EscapedPath())
+		switch {
+		case req.Method == http.MethodGet && path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true},{"id":4,"name":"kids","enabled":true},{"id":5,"name":"guests","enabled":true}]}`), nil
+		case req.Method == http.MethodGet && path == "/api/domains":
+			return piholetest.JSONResponse(http.StatusOK, `{"domains":[{"id":7,"domain":"ads.example","type":"deny","kind":"exact","groups":[4],"enabled":true},{"id":8,"domain":"ads.example","type":"allow","kind":"exact","groups":[0],"enabled":true}]}`), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/hosts":
+			return piholetest.JSONResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan"]}}}`), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/cnameRecords":
+			return piholetest.JSONResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.lan,nas.lan"]}}}`), nil
+		case req.Method == http.MethodDelete:
+			deletes = append(deletes, path)
+			return piholetest.Response(http.StatusNoContent, ``), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	m, err := Load(strings.NewReader(`
+version: 1
+groups:
+  - name: kids
+dnsRecords:
+  - {domain: nas.lan, ip: 10.0.0.1}
+domains:
+  - {domain: ads.example, type: deny, groups: [kids]}
+`))
+	require.NoError(t, err)
+
+	_, err = Apply(context.Background(), client, m, nil)
+	require.NoError(t, err)
+	assert.Empty(t, deletes)
+
+	result, err := Apply(context.Background(), client, m, &ApplyOptions{Prune: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/api/config/dns/cnameRecords/www.lan,nas.lan",
+		"/api/domains/allow/exact/ads.example",
+		"/api/groups/guests",
+	}, deletes)
+
+	deleted := make([]string, 0)
+	for _, change := range result.Changes {
+		if change.Action == ActionDeleted {
+			deleted = append(deleted, change.Kind+" "+change.Name)
+		}
+	}
+	assert.Equal(t, []string{"cname www.lan", "domain ads.example", "group guests"}, deleted)
+}
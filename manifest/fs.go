@@ -0,0 +1,282 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+)
+
+// ApplyFS loads the manifests matching pattern in fsys with LoadFS, checks that
+// every CNAME target is defined in them or already on the instance, and applies the
+// result with Apply and opts. Nothing is applied if any manifest is invalid. It is
+// the entry point for managing an instance from a directory of manifests in CI; set
+// opts.Prune to remove what a file no longer lists.
+func ApplyFS(ctx context.Context, client *pihole.Client, fsys fs.FS, pattern string, opts *ApplyOptions) (*Result, error) {
+	m, err := LoadFS(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCNAMETargets(ctx, client, m); err != nil {
+		return nil, err
+	}
+
+	return Apply(ctx, client, m, opts)
+}
+
+// LoadFS loads every manifest in fsys matching pattern and merges them into one,
+// which is then validated as a whole, so that a domain may use a group defined in
+// another file. A pattern without a slash, such as "*.yaml", is matched against the
+// file names of the whole tree; one with a slash against paths from the root, as
+// fs.Glob does.
+//
+// Files are merged in lexical order of their paths. Entries repeated across files
+// are kept once, but a group, CNAME domain, allow/deny domain or setting defined
+// differently in two files is an error.
+func LoadFS(fsys fs.FS, pattern string) (*Manifest, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	paths := make([]string, 0)
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			paths = append(paths, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manifests: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no files match %q", ErrInvalidManifest, pattern)
+	}
+	sort.Strings(paths)
+
+	merged := newMerger()
+	for _, name := range paths {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open manifest: %w", err)
+		}
+
+		m, err := decode(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		merged.add(name, m)
+	}
+
+	if len(merged.errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, errors.Join(merged.errs...))
+	}
+
+	if err := merged.manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return merged.manifest, nil
+}
+
+// merger combines manifests, remembering which file defined each entry so that
+// conflicts name both files.
+type merger struct {
+	manifest *Manifest
+	groups   map[string]string
+	cnames   map[string]string
+	domains  map[string]string
+	settings map[string]string
+	errs     []error
+}
+
+func newMerger() *merger {
+	return &merger{
+		manifest: &Manifest{Version: Version},
+		groups:   make(map[string]string),
+		cnames:   make(map[string]string),
+		domains:  make(map[string]string),
+		settings: make(map[string]string),
+	}
+}
+
+func (mg *merger) conflict(name string, what string, other string) {
+	mg.errs = append(mg.errs, fmt.Errorf("%s: %s is defined differently in %s", name, what, other))
+}
+
+func (mg *merger) add(name string, m *Manifest) {
+	if m.Version != Version {
+		mg.errs = append(mg.errs, fmt.Errorf("%s: unsupported version %d, expected %d", name, m.Version, Version))
+	}
+
+	for _, group := range m.Groups {
+		if other, ok := mg.groups[group.Name]; ok {
+			if i := slices.IndexFunc(mg.manifest.Groups, func(g Group) bool { return g.Name == group.Name }); mg.manifest.Groups[i] != group {
+				mg.conflict(name, fmt.Sprintf("group %q", group.Name), other)
+			}
+			continue
+		}
+		mg.groups[group.Name] = name
+		mg.manifest.Groups = append(mg.manifest.Groups, group)
+	}
+
+	for _, record := range m.DNSRecords {
+		if slices.IndexFunc(mg.manifest.DNSRecords, record.Equal) < 0 {
+			mg.manifest.DNSRecords = append(mg.manifest.DNSRecords, record)
+		}
+	}
+
+	for _, record := range m.CNAMERecords {
+		key := record.Normalize().Domain
+		if other, ok := mg.cnames[key]; ok {
+			if slices.IndexFunc(mg.manifest.CNAMERecords, record.Equal) < 0 {
+				mg.conflict(name, fmt.Sprintf("CNAME %q", record.Domain), other)
+			}
+			continue
+		}
+		mg.cnames[key] = name
+		mg.manifest.CNAMERecords = append(mg.manifest.CNAMERecords, record)
+	}
+
+	for _, domain := range m.Domains {
+		key := domainKey(domain)
+		if other, ok := mg.domains[key]; ok {
+			if i := slices.IndexFunc(mg.manifest.Domains, func(d Domain) bool { return domainKey(d) == key }); !reflect.DeepEqual(mg.manifest.Domains[i], domain) {
+				mg.conflict(name, fmt.Sprintf("%s domain %q", domain.Type, domain.Domain), other)
+			}
+			continue
+		}
+		mg.domains[key] = name
+		mg.manifest.Domains = append(mg.manifest.Domains, domain)
+	}
+
+	if len(m.Config) > 0 && mg.manifest.Config == nil {
+		mg.manifest.Config = make(map[string]any)
+	}
+	mg.mergeConfig(name, "", mg.manifest.Config, m.Config)
+}
+
+// mergeConfig copies the settings of src into dst, reporting settings that dst
+// already holds with another value.
+func (mg *merger) mergeConfig(name string, prefix string, dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		settingPath := key
+		if prefix != "" {
+			settingPath = prefix + "." + key
+		}
+
+		child, isTree := value.(map[string]any)
+		existing, ok := dst[key]
+		if !ok {
+			if isTree {
+				existing = make(map[string]any)
+				dst[key] = existing
+			} else {
+				dst[key] = value
+				mg.settings[settingPath] = name
+				continue
+			}
+		}
+
+		if existingTree, ok := existing.(map[string]any); ok && isTree {
+			mg.mergeConfig(name, settingPath, existingTree, child)
+			continue
+		}
+
+		if !reflect.DeepEqual(existing, value) {
+			other := mg.settings[settingPath]
+			if other == "" {
+				other = "another file"
+			}
+			mg.conflict(name, fmt.Sprintf("setting %s", settingPath), other)
+		}
+	}
+}
+
+// domainKey identifies an allow or deny entry; Pi-hole keeps one entry per domain,
+// type and kind.
+func domainKey(domain Domain) string {
+	kind := domain.Kind
+	if kind == "" {
+		kind = pihole.DomainKindExact
+	}
+
+	return fmt.Sprintf("%s/%s/%s", domain.Type, kind, strings.ToLower(domain.Domain))
+}
+
+// checkCNAMETargets reports CNAME records whose target is neither defined in the
+// manifest nor a local record of the instance, which Pi-hole would not resolve.
+func checkCNAMETargets(ctx context.Context, client *pihole.Client, m *Manifest) error {
+	names := make(map[string]bool)
+	addDNS := func(records pihole.DNSRecordList) {
+		for _, record := range records {
+			for _, name := range record.Normalize().Names() {
+				names[name] = true
+			}
+		}
+	}
+	addCNAME := func(records pihole.CNAMERecordList) {
+		for _, record := range records {
+			names[record.Normalize().Domain] = true
+		}
+	}
+
+	addDNS(m.DNSRecords)
+	addCNAME(m.CNAMERecords)
+
+	missing := func() []pihole.CNAMERecord {
+		records := make([]pihole.CNAMERecord, 0)
+		for _, record := range m.CNAMERecords {
+			if !names[record.Normalize().Target] {
+				records = append(records, record)
+			}
+		}
+		return records
+	}
+
+	if len(missing()) == 0 {
+		return nil
+	}
+
+	dns, err := client.LocalDNS.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch DNS records: %w", err)
+	}
+	cnames, err := client.LocalCNAME.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CNAME records: %w", err)
+	}
+	addDNS(dns)
+	addCNAME(cnames)
+
+	errs := make([]error, 0)
+	for _, record := range missing() {
+		errs = append(errs, fmt.Errorf("cnameRecords: target %q of %s is not defined", record.Target, record.Domain))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidManifest, errors.Join(errs...))
+	}
+
+	return nil
+}
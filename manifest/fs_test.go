@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/awaybreaktoday/lib-pihole-go/piholetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exampleTree = fstest.MapFS{
+	"groups.yaml": {Data: []byte(`
+version: 1
+groups:
+  - name: kids
+config:
+  dns:
+    queryLogging: true
+`)},
+	"lan/records.yaml": {Data: []byte(`
+version: 1
+dnsRecords:
+  - domain: nas.lan
+    ip: 10.0.0.1
+cnameRecords:
+  - domain: www.lan
+    target: nas.lan
+config:
+  dns:
+    domainNeeded: true
+`)},
+	"lan/domains.yml": {Data: []byte(`
+version: 1
+dnsRecords:
+  - domain: nas.lan
+    ip: 10.0.0.1
+domains:
+  - domain: ads.example
+    type: deny
+    groups: [kids]
+`)},
+	"README.md": {Data: []byte(`not a manifest`)},
+}
+
+func TestLoadFSMergesManifests(t *testing.T) {
+	m, err := LoadFS(exampleTree, "*.y*ml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Group{{Name: "kids"}}, m.Groups)
+	assert.Len(t, m.DNSRecords, 1)
+	assert.Len(t, m.CNAMERecords, 1)
+	assert.Len(t, m.Domains, 1)
+	assert.Equal(t, map[string]any{"dns": map[string]any{"queryLogging": true, "domainNeeded": true}}, m.Config)
+
+	m, err = LoadFS(exampleTree, "lan/records.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, m.Domains)
+
+	// Without groups.yaml the kids group is unknown.
+	_, err = LoadFS(exampleTree, "lan/*")
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, `unknown group "kids"`)
+
+	_, err = LoadFS(exampleTree, "*.toml")
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+}
+
+func TestLoadFSRejectsConflicts(t *testing.T) {
+	tree := fstest.MapFS{
+		"a.yaml": {Data: []byte("version: 1\ncnameRecords:\n  - {domain: www.lan, target: nas.lan}\nconfig: {dns: {queryLogging: true}}\n")},
+		"b.yaml": {Data: []byte("version: 1\ncnameRecords:\n  - {domain: WWW.lan, target: web.lan}\nconfig: {dns: {queryLogging: false}}\n")},
+	}
+
+	_, err := LoadFS(tree, "*.yaml")
+	require.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, `b.yaml: CNAME "WWW.lan" is defined differently in a.yaml`)
+	assert.ErrorContains(t, err, `b.yaml: setting dns.queryLogging is defined differently in a.yaml`)
+}
+
+func TestApplyFSChecksCNAMETargets(t *testing.T) {
+	var (
+		hosts  = []string{"10.0.0.9 media.lan"}
+		cnames = []string{}
+		puts   []string
+	)
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		path, _ := url.PathUnescape(req.URL.EscapedPath())
+		switch {
+		case req.Method == http.MethodGet && path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/hosts":
+			return piholetest.JSONResponse(http.StatusOK, map[string]any{"config": map[string]any{"dns": map[string]any{"hosts": hosts}}}), nil
+		case req.Method == http.MethodGet && path == "/api/config/dns/cnameRecords":
+			return piholetest.JSONResponse(http.StatusOK, map[string]any{"config": map[string]any{"dns": map[string]any{"cnameRecords": cnames}}}), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(path, "/api/config/dns/hosts/"):
+			puts = append(puts, path)
+			hosts = append(hosts, strings.TrimPrefix(path, "/api/config/dns/hosts/"))
+			return piholetest.JSONResponse(http.StatusCreated, `{}`), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(path, "/api/config/dns/cnameRecords/"):
+			puts = append(puts, path)
+			cnames = append(cnames, strings.TrimPrefix(path, "/api/config/dns/cnameRecords/"))
+			return piholetest.JSONResponse(http.StatusCreated, `{}`), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	tree := fstest.MapFS{
+		"cnames.yaml": {Data: []byte("version: 1\ncnameRecords:\n  - {domain: tv.lan, target: media.lan}\n  - {domain: www.lan, target: web.lan}\n")},
+	}
+
+	_, err = ApplyFS(context.Background(), client, tree, "*.yaml", nil)
+	require.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, `target "web.lan" of www.lan is not defined`)
+	assert.NotContains(t, err.Error(), "tv.lan")
+	assert.Empty(t, puts)
+
+	tree["hosts.yaml"] = &fstest.MapFile{Data: []byte("version: 1\ndnsRecords:\n  - {domain: web.lan, ip: 10.0.0.2}\n")}
+
+	result, err := ApplyFS(context.Background(), client, tree, "*.yaml", nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed())
+	assert.Equal(t, []string{
+		"/api/config/dns/hosts/10.0.0.2 web.lan",
+		"/api/config/dns/cnameRecords/tv.lan,media.lan",
+		"/api/config/dns/cnameRecords/www.lan,web.lan",
+	}, puts)
+}
+
+func TestApplyFSConvergesAcrossRuns(t *testing.T) {
+	var (
+		hosts  []string
+		cnames []string
+	)
+
+	// configArray serves and edits one of the record arrays.
+	configArray := func(req *http.Request, path string, prefix string, key string, entries *[]string) *http.Response {
+		if req.Method == http.MethodGet {
+			return piholetest.JSONResponse(http.StatusOK, map[string]any{"config": map[string]any{"dns": map[string]any{key: *entries}}})
+		}
+
+		entry := strings.TrimPrefix(path, prefix)
+		if req.Method == http.MethodPut {
+			*entries = append(*entries, entry)
+			return piholetest.JSONResponse(http.StatusCreated, `{}`)
+		}
+		*entries = slices.DeleteFunc(*entries, func(e string) bool { return e == entry })
+		return piholetest.Response(http.StatusNoContent, ``)
+	}
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		path, _ := url.PathUnescape(req.URL.EscapedPath())
+		switch {
+		case req.Method == http.MethodGet && path == "/api/groups":
+			return piholetest.JSONResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default","enabled":true}]}`), nil
+		case req.Method == http.MethodGet && path == "/api/domains":
+			return piholetest.JSONResponse(http.StatusOK, `{"domains":[]}`), nil
+		case strings.HasPrefix(path, "/api/config/dns/hosts"):
+			return configArray(req, path, "/api/config/dns/hosts/", "hosts", &hosts), nil
+		case strings.HasPrefix(path, "/api/config/dns/cnameRecords"):
+			return configArray(req, path, "/api/config/dns/cnameRecords/", "cnameRecords", &cnames), nil
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, path)
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	tree := fstest.MapFS{
+		"hosts.yaml":  {Data: []byte("version: 1\ndnsRecords:\n  - {domain: nas.lan, ip: 10.0.0.1}\n  - {domain: web.lan, ip: 10.0.0.2}\n  - {domain: old.lan, ip: 10.0.0.3}\n")},
+		"cnames.yaml": {Data: []byte("version: 1\ncnameRecords:\n  - {domain: www.lan, target: web.lan}\n")},
+	}
+
+	_, err = ApplyFS(context.Background(), client, tree, "*.yaml", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"10.0.0.1 nas.lan", "10.0.0.2 web.lan", "10.0.0.3 old.lan"}, hosts)
+	assert.Equal(t, []string{"www.lan,web.lan"}, cnames)
+
+	// The next commit moves the NAS, points www at it and drops old.lan.
+	tree["hosts.yaml"].Data = []byte("version: 1\ndnsRecords:\n  - {domain: nas.lan, ip: 10.0.0.5}\n  - {domain: web.lan, ip: 10.0.0.2}\n")
+	tree["cnames.yaml"].Data = []byte("version: 1\ncnameRecords:\n  - {domain: www.lan, target: nas.lan}\n")
+
+	result, err := ApplyFS(context.Background(), client, tree, "*.yaml", &ApplyOptions{Prune: true})
+	require.NoError(t, err)
+
+	actions := make(map[string]Action)
+	for _, change := range result.Changes {
+		actions[change.Kind+" "+change.Name] = change.Action
+	}
+	assert.Equal(t, map[string]Action{
+		"dns nas.lan 10.0.0.5": ActionUpdated,
+		"dns web.lan 10.0.0.2": ActionUnchanged,
+		"dns old.lan 10.0.0.3": ActionDeleted,
+		"cname www.lan":        ActionUpdated,
+	}, actions)
+	assert.ElementsMatch(t, []string{"10.0.0.5 nas.lan", "10.0.0.2 web.lan"}, hosts)
+	assert.Equal(t, []string{"www.lan,nas.lan"}, cnames)
+}
@@ -0,0 +1,160 @@
+// Package manifest defines a versioned document describing Pi-hole state (local DNS
+// and CNAME records, allow/deny domains and groups) and applies it to an instance.
+// It is the building block for GitOps-managed Pi-hole configuration.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the manifest schema version understood by this package.
+const Version = 1
+
+var (
+	ErrInvalidManifest = errors.New("invalid manifest")
+)
+
+// Manifest is the desired state of a Pi-hole instance.
+type Manifest struct {
+	Version      int                  `json:"version"`
+	Config       map[string]any       `json:"config,omitempty" yaml:"config,omitempty"`
+	Groups       []Group              `json:"groups,omitempty" yaml:"groups,omitempty"`
+	DNSRecords   []pihole.DNSRecord   `json:"dnsRecords,omitempty" yaml:"dnsRecords,omitempty"`
+	CNAMERecords []pihole.CNAMERecord `json:"cnameRecords,omitempty" yaml:"cnameRecords,omitempty"`
+	Domains      []Domain             `json:"domains,omitempty" yaml:"domains,omitempty"`
+}
+
+// Group is a Pi-hole group that domains can be assigned to by name.
+type Group struct {
+	Name     string `json:"name"`
+	Comment  string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Disabled bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Domain is an allow or deny list entry. Kind defaults to exact and Groups lists
+// group names, defaulting to Pi-hole's Default group.
+type Domain struct {
+	Domain   string            `json:"domain"`
+	Type     pihole.DomainType `json:"type"`
+	Kind     pihole.DomainKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Comment  string            `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Groups   []string          `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Disabled bool              `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Load reads a YAML or JSON manifest and validates it.
+func Load(r io.Reader) (*Manifest, error) {
+	m, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// decode reads a YAML or JSON manifest without validating it.
+func decode(r io.Reader) (*Manifest, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	// JSON documents are valid YAML, so a single decoder handles both formats.
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty document", ErrInvalidManifest)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidManifest, err)
+	}
+
+	return &m, nil
+}
+
+// LoadFile reads and validates the manifest at path.
+func LoadFile(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Validate checks the manifest version and that every entry is complete and
+// references known groups.
+func (m *Manifest) Validate() error {
+	var errs []error
+
+	if m.Version != Version {
+		errs = append(errs, fmt.Errorf("unsupported version %d, expected %d", m.Version, Version))
+	}
+
+	groups := map[string]bool{defaultGroup: true}
+	for i, group := range m.Groups {
+		if group.Name == "" {
+			errs = append(errs, fmt.Errorf("groups[%d]: name is required", i))
+		}
+		groups[group.Name] = true
+	}
+
+	hosts := make(map[string]int)
+	for i, record := range m.DNSRecords {
+		if record.Domain == "" || record.IP == "" {
+			errs = append(errs, fmt.Errorf("dnsRecords[%d]: domain and ip are required", i))
+		}
+
+		// Apply matches records by domain and IP, so each pair may appear once.
+		normalized := record.Normalize()
+		key := normalized.Domain + " " + normalized.IP
+		if first, ok := hosts[key]; ok {
+			errs = append(errs, fmt.Errorf("dnsRecords[%d]: %s %s is already defined by dnsRecords[%d]", i, record.Domain, record.IP, first))
+			continue
+		}
+		hosts[key] = i
+	}
+
+	for i, record := range m.CNAMERecords {
+		if record.Domain == "" || record.Target == "" {
+			errs = append(errs, fmt.Errorf("cnameRecords[%d]: domain and target are required", i))
+		}
+	}
+
+	for i, domain := range m.Domains {
+		if domain.Domain == "" {
+			errs = append(errs, fmt.Errorf("domains[%d]: domain is required", i))
+		}
+		if domain.Type != pihole.DomainTypeAllow && domain.Type != pihole.DomainTypeDeny {
+			errs = append(errs, fmt.Errorf("domains[%d]: invalid type %q", i, domain.Type))
+		}
+		if domain.Kind != "" && domain.Kind != pihole.DomainKindExact && domain.Kind != pihole.DomainKindRegex {
+			errs = append(errs, fmt.Errorf("domains[%d]: invalid kind %q", i, domain.Kind))
+		}
+		for _, name := range domain.Groups {
+			if !groups[name] {
+				errs = append(errs, fmt.Errorf("domains[%d]: unknown group %q", i, name))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidManifest, errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleYAML = `
+version: 1
+groups:
+  - name: kids
+    comment: Kids devices
+dnsRecords:
+  - domain: nas.lan
+    ip: 10.0.0.1
+    aliases: [storage.lan]
+    comment: rack 2
+cnameRecords:
+  - domain: www.lan
+    target: nas.lan
+    ttl: 300
+domains:
+  - domain: ads.example
+    type: deny
+    groups: [kids, Default]
+  - domain: (\.|^)tracker\.example$
+    type: deny
+    kind: regex
+`
+
+func TestLoadYAML(t *testing.T) {
+	m, err := Load(strings.NewReader(exampleYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, m.Version)
+	require.Len(t, m.DNSRecords, 1)
+	assert.Equal(t, []string{"storage.lan"}, m.DNSRecords[0].Aliases)
+	require.Len(t, m.CNAMERecords, 1)
+	assert.True(t, m.CNAMERecords[0].HasTTL)
+	assert.Equal(t, 300, m.CNAMERecords[0].TTL)
+	require.Len(t, m.Domains, 2)
+	assert.Equal(t, pihole.DomainKindRegex, m.Domains[1].Kind)
+}
+
+func TestLoadJSON(t *testing.T) {
+	m, err := Load(strings.NewReader(`{"version":1,"dnsRecords":[{"domain":"nas.lan","ip":"10.0.0.1"}]}`))
+	require.NoError(t, err)
+	require.Len(t, m.DNSRecords, 1)
+	assert.Equal(t, "10.0.0.1", m.DNSRecords[0].IP)
+}
+
+func TestLoadRejectsInvalidManifests(t *testing.T) {
+	tcs := map[string]string{
+		"empty":          ``,
+		"version":        `version: 2`,
+		"unknown field":  "version: 1\nhosts: []",
+		"missing ip":     "version: 1\ndnsRecords:\n  - domain: nas.lan",
+		"duplicate host": "version: 1\ndnsRecords:\n  - {domain: nas.lan, ip: 10.0.0.1}\n  - {domain: NAS.lan, ip: 10.0.0.1, comment: rack 2}",
+		"bad type":       "version: 1\ndomains:\n  - domain: ads.example\n    type: block",
+		"unknown group":  "version: 1\ndomains:\n  - domain: ads.example\n    type: deny\n    groups: [guests]",
+	}
+
+	for name, doc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(doc))
+			assert.ErrorIs(t, err, ErrInvalidManifest)
+		})
+	}
+}
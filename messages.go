@@ -0,0 +1,138 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Messages interface {
+	// List all diagnostic messages.
+	List(ctx context.Context) (MessageList, error)
+
+	// Count returns the number of diagnostic messages without fetching them.
+	Count(ctx context.Context) (int, error)
+
+	// Watch polls the diagnostic messages and emits each message that appeared since
+	// the previous poll. The channel is closed when ctx is done.
+	Watch(ctx context.Context, interval time.Duration) (<-chan MessageEvent, error)
+}
+
+type messages struct {
+	client *Client
+}
+
+// Message is a diagnostic message from FTL, such as a rate-limited client or a
+// failed adlist download.
+type Message struct {
+	ID        int64
+	Timestamp time.Time
+	Type      string
+	Plain     string
+	HTML      string
+}
+
+type MessageList []Message
+
+// MessageEvent carries a new diagnostic message, or Err when a poll failed.
+type MessageEvent struct {
+	Message *Message
+	Err     error
+}
+
+type messageResponse struct {
+	ID        int64   `json:"id"`
+	Timestamp float64 `json:"timestamp"`
+	Type      string  `json:"type"`
+	Plain     string  `json:"plain"`
+	HTML      string  `json:"html"`
+}
+
+type messageListResponse struct {
+	Messages []messageResponse `json:"messages"`
+}
+
+type messageCountResponse struct {
+	Count int `json:"count"`
+}
+
+func (res messageListResponse) toMessageList() MessageList {
+	list := make(MessageList, 0, len(res.Messages))
+	for _, entry := range res.Messages {
+		list = append(list, Message{
+			ID:        entry.ID,
+			Timestamp: unixFloat(entry.Timestamp),
+			Type:      entry.Type,
+			Plain:     entry.Plain,
+			HTML:      entry.HTML,
+		})
+	}
+
+	return list
+}
+
+// List returns all diagnostic messages
+func (m messages) List(ctx context.Context) (MessageList, error) {
+	var resList messageListResponse
+	if err := m.get(ctx, "/api/info/messages", &resList); err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+
+	return resList.toMessageList(), nil
+}
+
+// Count returns the number of diagnostic messages
+func (m messages) Count(ctx context.Context) (int, error) {
+	var resCount messageCountResponse
+	if err := m.get(ctx, "/api/info/messages/count", &resCount); err != nil {
+		return 0, fmt.Errorf("failed to fetch message count: %w", err)
+	}
+
+	return resCount.Count, nil
+}
+
+// Watch polls the messages every interval and emits those with IDs not seen in the
+// previous poll
+func (m messages) Watch(ctx context.Context, interval time.Duration) (<-chan MessageEvent, error) {
+	return watch(ctx, interval, m.List, diffMessages, func(err error) MessageEvent {
+		return MessageEvent{Err: err}
+	})
+}
+
+func diffMessages(before MessageList, after MessageList) []MessageEvent {
+	seen := make(map[int64]bool, len(before))
+	for _, message := range before {
+		seen[message.ID] = true
+	}
+
+	events := make([]MessageEvent, 0)
+	for _, message := range after {
+		if !seen[message.ID] {
+			events = append(events, MessageEvent{Message: &message})
+		}
+	}
+
+	return events
+}
+
+func (m messages) get(ctx context.Context, path string, v interface{}) error {
+	res, err := m.client.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return newInfoAPIError(res, b)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse messages body: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package pihole
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	isUnit(t)
+
+	client := newInfoTestClient(t, map[string]string{
+		"/api/info/messages/count": `{"count":2,"took":0.001}`,
+		"/api/info/messages":       `{"messages":[{"id":1,"timestamp":1700000000.5,"type":"RATE_LIMIT","plain":"Client 10.0.0.2 has been rate-limited","html":"Client <code>10.0.0.2</code> has been rate-limited"}]}`,
+	})
+
+	count, err := client.Messages.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	list, err := client.Messages.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "RATE_LIMIT", list[0].Type)
+	assert.Equal(t, int64(1700000000500), list[0].Timestamp.UnixMilli())
+}
+
+func TestDiffMessages(t *testing.T) {
+	before := MessageList{{ID: 1, Type: "LOAD"}}
+	after := MessageList{{ID: 1, Type: "LOAD"}, {ID: 2, Type: "RATE_LIMIT"}}
+
+	events := diffMessages(before, after)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(2), events[0].Message.ID)
+	assert.Empty(t, diffMessages(after, before))
+}
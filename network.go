@@ -0,0 +1,284 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type Network interface {
+	// Devices lists the devices in FTL's network table, with the addresses each one
+	// used.
+	Devices(ctx context.Context) (DeviceList, error)
+
+	// DeleteDevice removes a device and its addresses from the network table.
+	DeleteDevice(ctx context.Context, id int) error
+
+	// PruneDevices removes the devices last seen before lastSeenBefore and returns
+	// them. It carries on past failed deletions; the error joins an *OperationError
+	// for each.
+	PruneDevices(ctx context.Context, lastSeenBefore time.Time) (DeviceList, error)
+
+	// ResolveClient returns a unified view of an IP or MAC address: its hostname, MAC
+	// address, vendor, group memberships and query count, joined from the network
+	// table, the DHCP leases and the managed clients.
+	ResolveClient(ctx context.Context, ipOrMAC string) (*ResolvedClient, error)
+
+	// Vendor returns the manufacturer of a MAC address from Pi-hole's vendor
+	// database. Pi-hole only looks vendors up for devices in its network table, so
+	// the address, or another with the same OUI, must have been seen on the network.
+	Vendor(ctx context.Context, mac string) (string, error)
+}
+
+var (
+	ErrorDeviceNotFound = newNotFoundError("network device not found")
+	ErrorVendorNotFound = newNotFoundError("MAC vendor not found")
+)
+
+type network struct {
+	client *Client
+}
+
+// Device is an entry in the network table: a hardware address, or an IP address for
+// clients FTL never saw on the local link, with the addresses it used.
+type Device struct {
+	ID        int
+	MAC       string
+	Interface string
+	// Vendor is the manufacturer Pi-hole found for MAC in its vendor database
+	// (macvendor.db), if any.
+	Vendor    string
+	FirstSeen time.Time
+	LastQuery time.Time
+	Queries   int
+	Addresses []DeviceAddress
+}
+
+// DeviceAddress is an IP address a device used, with the host name it resolved to.
+type DeviceAddress struct {
+	IP          string
+	Name        string
+	LastSeen    time.Time
+	NameUpdated time.Time
+}
+
+type DeviceList []Device
+
+type deviceAddressResponse struct {
+	IP          string `json:"ip"`
+	Name        string `json:"name"`
+	LastSeen    int64  `json:"lastSeen"`
+	NameUpdated int64  `json:"nameUpdated"`
+}
+
+type deviceResponse struct {
+	ID         int                     `json:"id"`
+	HWAddr     string                  `json:"hwaddr"`
+	Interface  string                  `json:"interface"`
+	MACVendor  string                  `json:"macVendor"`
+	FirstSeen  int64                   `json:"firstSeen"`
+	LastQuery  int64                   `json:"lastQuery"`
+	NumQueries int                     `json:"numQueries"`
+	IPs        []deviceAddressResponse `json:"ips"`
+}
+
+type deviceListResponse struct {
+	Devices []deviceResponse `json:"devices"`
+}
+
+func (res deviceListResponse) toDeviceList() DeviceList {
+	list := make(DeviceList, 0, len(res.Devices))
+	for _, entry := range res.Devices {
+		device := Device{
+			ID:        entry.ID,
+			MAC:       entry.HWAddr,
+			Interface: entry.Interface,
+			Vendor:    entry.MACVendor,
+			FirstSeen: unixTime(entry.FirstSeen),
+			LastQuery: unixTime(entry.LastQuery),
+			Queries:   entry.NumQueries,
+			Addresses: make([]DeviceAddress, 0, len(entry.IPs)),
+		}
+
+		if mac, err := NormalizeMAC(entry.HWAddr); err == nil {
+			device.MAC = mac
+		}
+
+		for _, ip := range entry.IPs {
+			device.Addresses = append(device.Addresses, DeviceAddress{
+				IP:          ip.IP,
+				Name:        ip.Name,
+				LastSeen:    unixTime(ip.LastSeen),
+				NameUpdated: unixTime(ip.NameUpdated),
+			})
+		}
+
+		list = append(list, device)
+	}
+
+	return list
+}
+
+// unixTime converts a timestamp in seconds, leaving 0 as the zero time.
+func unixTime(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0)
+}
+
+// LastSeen returns when the device was last active: the latest of its last query and
+// the times its addresses were last seen, or FirstSeen if it has neither.
+func (d Device) LastSeen() time.Time {
+	seen := d.LastQuery
+	for _, address := range d.Addresses {
+		if address.LastSeen.After(seen) {
+			seen = address.LastSeen
+		}
+	}
+
+	if seen.IsZero() {
+		return d.FirstSeen
+	}
+
+	return seen
+}
+
+// Devices returns the network table
+func (n network) Devices(ctx context.Context) (DeviceList, error) {
+	res, err := n.client.Get(ctx, "/api/network/devices")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newNetworkAPIError(res, b)
+	}
+
+	var resList deviceListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse network device list body: %w", err)
+	}
+
+	return resList.toDeviceList(), nil
+}
+
+// DeleteDevice deletes a device from the network table
+func (n network) DeleteDevice(ctx context.Context, id int) (err error) {
+	m := Mutation{Operation: AuditDeviceDelete, Target: strconv.Itoa(id)}
+	defer func() {
+		n.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := n.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	res, done, err := n.client.mutate(ctx, http.MethodDelete, fmt.Sprintf("/api/network/devices/%d", id), nil, func(ctx context.Context) (bool, error) {
+		devices, err := n.Devices(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		return !slices.ContainsFunc(devices, func(device Device) bool { return device.ID == id }), nil
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %d", ErrorDeviceNotFound, id)
+	}
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newNetworkAPIError(res, b)
+	}
+
+	return nil
+}
+
+// PruneDevices deletes the devices not seen since lastSeenBefore
+func (n network) PruneDevices(ctx context.Context, lastSeenBefore time.Time) (DeviceList, error) {
+	devices, err := n.Devices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch network devices: %w", err)
+	}
+
+	pruned := make(DeviceList, 0)
+	var errs []error
+
+	for _, device := range devices {
+		if !device.LastSeen().Before(lastSeenBefore) {
+			continue
+		}
+
+		if err := n.DeleteDevice(ctx, device.ID); err != nil {
+			errs = append(errs, &OperationError{Operation: AuditDeviceDelete, Target: strconv.Itoa(device.ID), Err: err})
+			continue
+		}
+		pruned = append(pruned, device)
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// Vendor returns the vendor Pi-hole stored for the MAC address, or for another
+// device with the same OUI
+func (n network) Vendor(ctx context.Context, mac string) (string, error) {
+	normalized, err := NormalizeMAC(mac)
+	if err != nil {
+		return "", err
+	}
+
+	devices, err := n.Devices(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch network devices: %w", err)
+	}
+
+	vendor := devices.vendor(normalized)
+	if vendor == "" {
+		return "", fmt.Errorf("%w: %s", ErrorVendorNotFound, normalized)
+	}
+
+	return vendor, nil
+}
+
+// vendor returns the vendor of the device with the normalized MAC address, or else
+// of the first device sharing its OUI.
+func (l DeviceList) vendor(mac string) string {
+	vendor := ""
+	for _, device := range l {
+		if device.Vendor == "" || !isHardwareAddress(device.MAC) {
+			continue
+		}
+		if device.MAC == mac {
+			return device.Vendor
+		}
+		if vendor == "" && sameOUI(device.MAC, mac) {
+			vendor = device.Vendor
+		}
+	}
+
+	return vendor
+}
+
+// sameOUI reports whether two normalized MAC addresses share the first three bytes,
+// which identify the vendor.
+func sameOUI(a string, b string) bool {
+	return len(a) >= 8 && len(b) >= 8 && a[:8] == b[:8]
+}
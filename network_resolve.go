@@ -0,0 +1,238 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ResolvedClient is what Pi-hole knows about one address, joined from the network
+// table, the DHCP leases and the managed clients. Device and Lease are nil when the
+// address is not in the network table or holds no lease.
+type ResolvedClient struct {
+	MAC      string
+	IPs      []string
+	Hostname string
+	Vendor   string
+	Queries  int
+	LastSeen time.Time
+
+	Device *Device
+	Lease  *Lease
+
+	// Clients are the managed client entries matching the address, by IP address,
+	// MAC address, hostname, subnet or interface, in the order Pi-hole prefers them.
+	Clients ManagedClientList
+	// Groups are the groups of the preferred client entry, or the Default group if
+	// no entry matches.
+	Groups []int
+}
+
+// defaultGroupID is the group clients without an entry belong to.
+const defaultGroupID = 0
+
+// ResolveClient looks the address up in the network table, the DHCP leases and the
+// managed clients and joins the results
+func (n network) ResolveClient(ctx context.Context, ipOrMAC string) (*ResolvedClient, error) {
+	address := strings.TrimSpace(ipOrMAC)
+	ip := net.ParseIP(address)
+	mac := ""
+	if ip == nil {
+		normalized, err := NormalizeMAC(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or MAC address %q", ipOrMAC)
+		}
+		mac = normalized
+	}
+
+	devices, err := n.Devices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch network devices: %w", err)
+	}
+
+	leases, err := n.client.DHCP.Leases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DHCP leases: %w", err)
+	}
+
+	managed, err := n.client.Clients.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clients: %w", err)
+	}
+
+	resolved := &ResolvedClient{MAC: mac, IPs: make([]string, 0)}
+	if ip != nil {
+		resolved.IPs = append(resolved.IPs, ip.String())
+	}
+
+	resolved.Device = devices.find(ip, mac)
+	if device := resolved.Device; device != nil {
+		if resolved.MAC == "" {
+			resolved.MAC = device.MAC
+		}
+		for _, address := range device.Addresses {
+			resolved.addIP(address.IP)
+		}
+		resolved.Vendor = device.Vendor
+		resolved.Queries = device.Queries
+		resolved.LastSeen = device.LastSeen()
+		resolved.Hostname = device.hostname(ip)
+	}
+
+	query := LeaseQuery{MAC: resolved.MAC}
+	if query.MAC == "" || !isHardwareAddress(query.MAC) {
+		query = LeaseQuery{IP: address}
+	}
+	if matches, err := leases.Find(query); err == nil && len(matches) > 0 {
+		lease := matches[0]
+		resolved.Lease = &lease
+		resolved.addIP(lease.IP)
+		if isHardwareAddress(lease.MAC) {
+			resolved.MAC = lease.MAC
+		}
+		if lease.Name != "" && lease.Name != "*" {
+			resolved.Hostname = lease.Name
+		}
+	}
+
+	if resolved.Vendor == "" && isHardwareAddress(resolved.MAC) {
+		resolved.Vendor = devices.vendor(resolved.MAC)
+	}
+
+	var iface string
+	if resolved.Device != nil {
+		iface = resolved.Device.Interface
+	}
+	resolved.Clients = managed.matching(resolved, iface)
+
+	if resolved.Device == nil && resolved.Lease == nil && len(resolved.Clients) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrorDeviceNotFound, ipOrMAC)
+	}
+
+	resolved.Groups = []int{defaultGroupID}
+	if len(resolved.Clients) > 0 {
+		resolved.Groups = resolved.Clients[0].Groups
+	}
+
+	return resolved, nil
+}
+
+func (r *ResolvedClient) addIP(ip string) {
+	for _, known := range r.IPs {
+		if net.ParseIP(known).Equal(net.ParseIP(ip)) {
+			return
+		}
+	}
+
+	r.IPs = append(r.IPs, ip)
+}
+
+// isHardwareAddress reports whether the MAC of a device is a real hardware address.
+// FTL stores clients it never saw on the local link as ip-<address>.
+func isHardwareAddress(mac string) bool {
+	_, err := net.ParseMAC(mac)
+	return err == nil
+}
+
+// find returns the device with the MAC address, or the one that used the IP address
+// most recently.
+func (l DeviceList) find(ip net.IP, mac string) *Device {
+	var found *Device
+	var seen time.Time
+
+	for i, device := range l {
+		if mac != "" {
+			if device.MAC == mac {
+				return &l[i]
+			}
+			continue
+		}
+
+		for _, address := range device.Addresses {
+			if ip.Equal(net.ParseIP(address.IP)) && (found == nil || address.LastSeen.After(seen)) {
+				found, seen = &l[i], address.LastSeen
+			}
+		}
+	}
+
+	return found
+}
+
+// hostname returns the name of the address matching ip, or else the first name the
+// device resolved to.
+func (d Device) hostname(ip net.IP) string {
+	for _, address := range d.Addresses {
+		if ip != nil && ip.Equal(net.ParseIP(address.IP)) && address.Name != "" {
+			return address.Name
+		}
+	}
+
+	for _, address := range d.Addresses {
+		if address.Name != "" {
+			return address.Name
+		}
+	}
+
+	return ""
+}
+
+// matching returns the entries that apply to the resolved client, ordered the way
+// Pi-hole prefers them: IP address, MAC address, hostname, subnet, then interface.
+func (l ManagedClientList) matching(resolved *ResolvedClient, iface string) ManagedClientList {
+	const (
+		byIP = iota
+		byMAC
+		byHostname
+		bySubnet
+		byInterface
+		kinds
+	)
+
+	buckets := make([]ManagedClientList, kinds)
+	for _, entry := range l {
+		kind := -1
+		client := strings.TrimSpace(entry.Client)
+
+		switch {
+		case strings.HasPrefix(client, ":"):
+			if iface != "" && client[1:] == iface {
+				kind = byInterface
+			}
+		case net.ParseIP(client) != nil:
+			for _, ip := range resolved.IPs {
+				if net.ParseIP(client).Equal(net.ParseIP(ip)) {
+					kind = byIP
+				}
+			}
+		case strings.Contains(client, "/"):
+			if _, subnet, err := net.ParseCIDR(client); err == nil {
+				for _, ip := range resolved.IPs {
+					if subnet.Contains(net.ParseIP(ip)) {
+						kind = bySubnet
+					}
+				}
+			}
+		default:
+			if mac, err := NormalizeMAC(client); err == nil {
+				if mac == resolved.MAC {
+					kind = byMAC
+				}
+			} else if resolved.Hostname != "" && strings.EqualFold(strings.TrimSuffix(client, "."), resolved.Hostname) {
+				kind = byHostname
+			}
+		}
+
+		if kind >= 0 {
+			buckets[kind] = append(buckets[kind], entry)
+		}
+	}
+
+	matches := make(ManagedClientList, 0)
+	for _, bucket := range buckets {
+		matches = append(matches, bucket...)
+	}
+
+	return matches
+}
@@ -0,0 +1,172 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDevicesBody = `{"devices":[
+	{"id":1,"hwaddr":"AA:BB:CC:00:11:22","interface":"eth0","macVendor":"Apple, Inc.","firstSeen":1700000000,"lastQuery":1700090000,"numQueries":42,
+		"ips":[{"ip":"192.168.1.20","name":"laptop.lan","lastSeen":1700095000,"nameUpdated":1700000100}]},
+	{"id":2,"hwaddr":"aa:bb:cc:00:11:33","interface":"eth0","firstSeen":1700000000,"lastQuery":0,"numQueries":0,
+		"ips":[{"ip":"192.168.1.30","name":"printer.lan","lastSeen":1700001000,"nameUpdated":0}]},
+	{"id":3,"hwaddr":"ip-192.168.1.40","interface":"eth0","firstSeen":1700000500,"lastQuery":0,"numQueries":0,"ips":[]}
+]}`
+
+const testNetworkClientsBody = `{"clients":[
+	{"id":1,"client":"192.168.1.0/24","groups":[2]},
+	{"id":2,"client":"aa:bb:cc:00:11:22","groups":[1,3]},
+	{"id":3,"client":":eth0","groups":[4]},
+	{"id":4,"client":"192.168.1.99","groups":[5]}
+]}`
+
+func newNetworkTestClient(t *testing.T, deleted *[]string, failID string) *Client {
+	t.Helper()
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/network/devices":
+			return newHTTPResponse(http.StatusOK, testDevicesBody), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/dhcp/leases":
+			return newHTTPResponse(http.StatusOK, testLeasesBody), nil
+		case req.Method == http.MethodGet && req.URL.Path == "/api/clients":
+			return newHTTPResponse(http.StatusOK, testNetworkClientsBody), nil
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/network/devices/"):
+			id := strings.TrimPrefix(req.URL.Path, "/api/network/devices/")
+			if id == failID {
+				return newHTTPResponse(http.StatusInternalServerError, `{"error":{"key":"database_error","message":"locked"}}`), nil
+			}
+			if id == "9" {
+				return newHTTPResponse(http.StatusNotFound, ``), nil
+			}
+			*deleted = append(*deleted, id)
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestNetwork_Devices(t *testing.T) {
+	isUnit(t)
+
+	client := newNetworkTestClient(t, new([]string), "")
+
+	devices, err := client.Network.Devices(context.Background())
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+
+	assert.Equal(t, "aa:bb:cc:00:11:22", devices[0].MAC)
+	assert.Equal(t, 42, devices[0].Queries)
+	assert.Equal(t, "laptop.lan", devices[0].Addresses[0].Name)
+	assert.Equal(t, int64(1700095000), devices[0].LastSeen().Unix())
+	assert.Equal(t, int64(1700001000), devices[1].LastSeen().Unix())
+	assert.Equal(t, "ip-192.168.1.40", devices[2].MAC)
+	assert.Equal(t, int64(1700000500), devices[2].LastSeen().Unix())
+}
+
+func TestNetwork_DeleteDevice(t *testing.T) {
+	isUnit(t)
+
+	var deleted []string
+	client := newNetworkTestClient(t, &deleted, "")
+
+	require.NoError(t, client.Network.DeleteDevice(context.Background(), 2))
+	assert.Equal(t, []string{"2"}, deleted)
+
+	err := client.Network.DeleteDevice(context.Background(), 9)
+	assert.ErrorIs(t, err, ErrorDeviceNotFound)
+}
+
+func TestNetwork_PruneDevices(t *testing.T) {
+	isUnit(t)
+
+	var deleted []string
+	client := newNetworkTestClient(t, &deleted, "3")
+
+	pruned, err := client.Network.PruneDevices(context.Background(), time.Unix(1700050000, 0))
+	assert.Equal(t, []string{"2"}, deleted)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, 2, pruned[0].ID)
+
+	opErrs := OperationErrors(err)
+	require.Len(t, opErrs, 1)
+	assert.Equal(t, AuditDeviceDelete, opErrs[0].Operation)
+	assert.Equal(t, "3", opErrs[0].Target)
+}
+
+func TestNetwork_ResolveClient(t *testing.T) {
+	isUnit(t)
+
+	client := newNetworkTestClient(t, new([]string), "")
+	ctx := context.Background()
+
+	resolved, err := client.Network.ResolveClient(ctx, "192.168.1.20")
+	require.NoError(t, err)
+	assert.Equal(t, "aa:bb:cc:00:11:22", resolved.MAC)
+	assert.Equal(t, "laptop", resolved.Hostname, "the lease name wins over the reverse lookup")
+	assert.Equal(t, "Apple, Inc.", resolved.Vendor)
+	assert.Equal(t, 42, resolved.Queries)
+	require.NotNil(t, resolved.Device)
+	require.NotNil(t, resolved.Lease)
+	assert.Equal(t, []string{"aa:bb:cc:00:11:22", "192.168.1.0/24", ":eth0"}, clientIdentifiers(resolved.Clients))
+	assert.Equal(t, []int{1, 3}, resolved.Groups)
+
+	resolved, err = client.Network.ResolveClient(ctx, "AA-BB-CC-00-11-33")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.30"}, resolved.IPs)
+	assert.Equal(t, "printer", resolved.Hostname)
+	assert.Equal(t, []int{2}, resolved.Groups)
+
+	resolved, err = client.Network.ResolveClient(ctx, "192.168.1.99")
+	require.NoError(t, err)
+	assert.Nil(t, resolved.Device)
+	assert.Equal(t, []int{5}, resolved.Groups)
+
+	_, err = client.Network.ResolveClient(ctx, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrorDeviceNotFound)
+
+	_, err = client.Network.ResolveClient(ctx, "laptop")
+	assert.Error(t, err)
+}
+
+func clientIdentifiers(list ManagedClientList) []string {
+	identifiers := make([]string, 0, len(list))
+	for _, entry := range list {
+		identifiers = append(identifiers, entry.Client)
+	}
+
+	return identifiers
+}
+
+func TestNetwork_Vendor(t *testing.T) {
+	isUnit(t)
+
+	client := newNetworkTestClient(t, new([]string), "")
+	ctx := context.Background()
+
+	vendor, err := client.Network.Vendor(ctx, "AA-BB-CC-00-11-22")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple, Inc.", vendor)
+
+	vendor, err = client.Network.Vendor(ctx, "aa:bb:cc:99:99:99")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple, Inc.", vendor, "devices with the same OUI share the vendor")
+
+	_, err = client.Network.Vendor(ctx, "00:11:22:33:44:55")
+	assert.ErrorIs(t, err, ErrorVendorNotFound)
+
+	_, err = client.Network.Vendor(ctx, "not-a-mac")
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+}
@@ -0,0 +1,260 @@
+// Package notify watches a Pi-hole instance and posts notable events to webhooks:
+// blocking state changes, new diagnostic messages, adlists failing to download
+// during gravity runs and, optionally, query rate anomalies. Payloads are formatted
+// for Slack, Discord or as generic JSON, or built by a custom function.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+)
+
+// EventType names the kind of an event.
+type EventType string
+
+const (
+	EventBlockingChanged EventType = "blocking_changed"
+	EventMessage         EventType = "message"
+	EventGravityFailed   EventType = "gravity_failed"
+	EventAnomaly         EventType = "anomaly"
+)
+
+const defaultInterval = time.Minute
+
+var ErrInvalidConfig = errors.New("invalid notify configuration")
+
+// Event is something worth notifying about. Data holds the value it was derived
+// from: a pihole.BlockingEvent, pihole.Message, pihole.Adlist or pihole.Anomaly.
+type Event struct {
+	Type     EventType `json:"type"`
+	Instance string    `json:"instance,omitempty"`
+	Time     time.Time `json:"time"`
+	Text     string    `json:"text"`
+	Data     any       `json:"data,omitempty"`
+}
+
+type Config struct {
+	// Instance labels events, e.g. with the Pi-hole's hostname, for webhooks shared
+	// by several instances.
+	Instance string
+
+	// Interval is how often the instance is polled, one minute by default.
+	Interval time.Duration
+
+	Webhooks []Webhook
+
+	// Events shares an event bus with other consumers. The caller runs it, and its
+	// interval applies instead of Interval. By default Run starts its own bus.
+	Events *pihole.EventBus
+
+	// Anomalies enables anomaly detection on every poll with these options.
+	Anomalies *pihole.AnomalyOptions
+
+	// HTTPClient posts to the webhooks, http.DefaultClient by default.
+	HTTPClient *http.Client
+
+	// OnError receives failed polls and deliveries. By default they are dropped, and
+	// the notifier keeps running either way.
+	OnError func(error)
+}
+
+// Notifier polls a Pi-hole instance and delivers events to webhooks.
+type Notifier struct {
+	client *pihole.Client
+	config Config
+	http   *http.Client
+}
+
+// New validates the webhooks and returns a notifier for client.
+func New(client *pihole.Client, config Config) (*Notifier, error) {
+	if config.Interval < 0 {
+		return nil, fmt.Errorf("%w: negative interval", ErrInvalidConfig)
+	}
+	if config.Interval == 0 {
+		config.Interval = defaultInterval
+	}
+
+	for i, webhook := range config.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: webhooks[%d]: invalid URL %q", ErrInvalidConfig, i, webhook.URL)
+		}
+
+		switch webhook.Format {
+		case "", FormatGeneric, FormatSlack, FormatDiscord:
+		default:
+			return nil, fmt.Errorf("%w: webhooks[%d]: unknown format %q", ErrInvalidConfig, i, webhook.Format)
+		}
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Notifier{client: client, config: config, http: httpClient}, nil
+}
+
+// Run watches the instance until ctx is done. It fails only if the watchers cannot
+// fetch their initial state.
+func (n *Notifier) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	bus := n.config.Events
+	runErr := make(chan error, 1)
+	if bus == nil {
+		bus = n.client.Events(n.config.Interval)
+	}
+
+	events := bus.Subscribe(ctx, pihole.EventBlockingChanged, pihole.EventMessageAdded, pihole.EventAdlistChanged, pihole.EventPollFailed)
+
+	if n.config.Events == nil {
+		go func() {
+			runErr <- bus.Run(ctx)
+		}()
+	}
+
+	var anomalies <-chan time.Time
+	if n.config.Anomalies != nil {
+		ticker := time.NewTicker(n.config.Interval)
+		defer ticker.Stop()
+		anomalies = ticker.C
+	}
+	reported := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-runErr:
+			return err
+		case event, ok := <-events:
+			if !ok {
+				// The bus stopped; report why if it is ours.
+				if n.config.Events == nil {
+					return <-runErr
+				}
+				return nil
+			}
+			n.handle(ctx, event)
+		case <-anomalies:
+			n.detectAnomalies(ctx, reported)
+		}
+	}
+}
+
+func (n *Notifier) handle(ctx context.Context, event pihole.Event) {
+	switch payload := event.Payload.(type) {
+	case error:
+		n.error(fmt.Errorf("failed to poll %s: %w", event.Subsystem, payload))
+	case pihole.BlockingEvent:
+		n.notify(ctx, n.blockingEvent(payload))
+	case pihole.MessageEvent:
+		n.notify(ctx, n.messageEvent(*payload.Message))
+	case pihole.AdlistEvent:
+		if payload.New.Status.Failed() && !payload.Old.Status.Failed() {
+			n.notify(ctx, n.gravityEvent(*payload.New))
+		}
+	}
+}
+
+// detectAnomalies notifies about anomalies not already reported by the previous
+// poll, so that a sustained spike is reported once.
+func (n *Notifier) detectAnomalies(ctx context.Context, reported map[string]bool) {
+	found, err := n.client.Stats.DetectAnomalies(ctx, *n.config.Anomalies)
+	if err != nil {
+		n.error(fmt.Errorf("failed to detect anomalies: %w", err))
+		return
+	}
+
+	current := make(map[string]bool, len(found))
+	for _, anomaly := range found {
+		key := string(anomaly.Kind) + " " + anomaly.Name
+		current[key] = true
+		if !reported[key] {
+			n.notify(ctx, n.anomalyEvent(anomaly))
+		}
+	}
+
+	clear(reported)
+	for key := range current {
+		reported[key] = true
+	}
+}
+
+// Notify delivers event to every webhook subscribed to its type, returning the
+// failed deliveries joined.
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	if event.Instance == "" {
+		event.Instance = n.config.Instance
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	var errs []error
+	for _, webhook := range n.config.Webhooks {
+		if !webhook.subscribed(event.Type) {
+			continue
+		}
+
+		if err := webhook.deliver(ctx, n.http, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) notify(ctx context.Context, event Event) {
+	if err := n.Notify(ctx, event); err != nil {
+		n.error(err)
+	}
+}
+
+func (n *Notifier) error(err error) {
+	if n.config.OnError != nil {
+		n.config.OnError(err)
+	}
+}
+
+func (n *Notifier) blockingEvent(event pihole.BlockingEvent) Event {
+	text := fmt.Sprintf("Blocking %s (was %s)", event.New.State, event.Old.State)
+	if event.New.Timer > 0 {
+		text += fmt.Sprintf(" for %s", event.New.Timer.Round(time.Second))
+	}
+
+	return Event{Type: EventBlockingChanged, Text: text, Data: event}
+}
+
+func (n *Notifier) messageEvent(message pihole.Message) Event {
+	return Event{Type: EventMessage, Text: fmt.Sprintf("%s: %s", message.Type, message.Plain), Data: message}
+}
+
+func (n *Notifier) gravityEvent(list pihole.Adlist) Event {
+	return Event{Type: EventGravityFailed, Text: fmt.Sprintf("Adlist %s failed to download: %s", list.Address, list.Status), Data: list}
+}
+
+func (n *Notifier) anomalyEvent(anomaly pihole.Anomaly) Event {
+	name := anomaly.Name
+	if anomaly.Hostname != "" {
+		name += " (" + anomaly.Hostname + ")"
+	}
+
+	change := "with no baseline queries"
+	if !math.IsInf(anomaly.Factor, 1) {
+		change = fmt.Sprintf("%.1fx its baseline rate", anomaly.Factor)
+	}
+
+	text := fmt.Sprintf("Unusual query rate for %s %s: %.0f queries per hour, %s", anomaly.Kind, name, anomaly.Rate, change)
+
+	return Event{Type: EventAnomaly, Text: text, Data: anomaly}
+}
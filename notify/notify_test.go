@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	pihole "github.com/awaybreaktoday/lib-pihole-go"
+	"github.com/awaybreaktoday/lib-pihole-go/piholetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebhookServer records the JSON bodies posted to it.
+func newWebhookServer(t *testing.T) (*httptest.Server, func() []map[string]any) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(b, &body))
+
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]any(nil), bodies...)
+	}
+}
+
+func TestNotifierRun(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		state    = "enabled"
+		messages = `{"messages":[]}`
+		status   = 1
+	)
+
+	httpClient := piholetest.HTTPClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch req.URL.Path {
+		case "/api/dns/blocking":
+			return piholetest.JSONResponse(http.StatusOK, map[string]any{"blocking": state, "timer": nil}), nil
+		case "/api/info/messages":
+			return piholetest.JSONResponse(http.StatusOK, messages), nil
+		case "/api/lists":
+			return piholetest.JSONResponse(http.StatusOK, map[string]any{"lists": []map[string]any{
+				{"id": 1, "address": "https://lists.example/hosts", "type": "block", "enabled": true, "status": status},
+			}}), nil
+		default:
+			return piholetest.Response(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := pihole.New(pihole.Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	server, bodies := newWebhookServer(t)
+	notifier, err := New(client, Config{
+		Instance: "pi-1",
+		Interval: 10 * time.Millisecond,
+		Webhooks: []Webhook{{URL: server.URL, Format: FormatSlack}},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Run(ctx)
+	}()
+
+	// Let the watchers take their initial snapshots before changing anything.
+	time.Sleep(30 * time.Millisecond)
+	mu.Lock()
+	state = "disabled"
+	messages = `{"messages":[{"id":4,"timestamp":1700000000,"type":"RATE_LIMIT","plain":"Client 10.0.0.5 has been rate-limited"}]}`
+	status = 4
+	mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		return len(bodies()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	texts := make([]any, 0)
+	for _, body := range bodies() {
+		texts = append(texts, body["text"])
+	}
+	assert.ElementsMatch(t, []any{
+		"[pi-1] Blocking disabled (was enabled)",
+		"[pi-1] RATE_LIMIT: Client 10.0.0.5 has been rate-limited",
+		"[pi-1] Adlist https://lists.example/hosts failed to download: unavailable, no local copy",
+	}, texts)
+}
+
+func TestNotifyFormats(t *testing.T) {
+	generic, genericBodies := newWebhookServer(t)
+	discord, discordBodies := newWebhookServer(t)
+	custom, customBodies := newWebhookServer(t)
+
+	notifier, err := New(nil, Config{
+		Instance: "pi-1",
+		Webhooks: []Webhook{
+			{URL: generic.URL},
+			{URL: discord.URL, Format: FormatDiscord, Events: []EventType{EventAnomaly}},
+			{URL: custom.URL, Payload: func(event Event) (any, error) {
+				return map[string]string{"summary": string(event.Type)}, nil
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	when := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, notifier.Notify(context.Background(), Event{Type: EventMessage, Time: when, Text: "hello"}))
+
+	assert.Equal(t, []map[string]any{{"type": "message", "instance": "pi-1", "time": "2024-05-01T12:00:00Z", "text": "hello"}}, genericBodies())
+	assert.Empty(t, discordBodies())
+	assert.Equal(t, []map[string]any{{"summary": "message"}}, customBodies())
+
+	require.NoError(t, notifier.Notify(context.Background(), Event{Type: EventAnomaly, Text: "spike"}))
+	assert.Equal(t, []map[string]any{{"content": "[pi-1] spike"}}, discordBodies())
+}
+
+func TestNewRejectsInvalidWebhooks(t *testing.T) {
+	_, err := New(nil, Config{Webhooks: []Webhook{{URL: "hooks.example/abc"}}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = New(nil, Config{Webhooks: []Webhook{{URL: "https://hooks.example/abc", Format: "teams"}}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
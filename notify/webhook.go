@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// Format selects the payload layout of a webhook.
+type Format string
+
+const (
+	// FormatGeneric posts the Event itself as JSON.
+	FormatGeneric Format = "generic"
+
+	// FormatSlack posts a Slack incoming webhook message.
+	FormatSlack Format = "slack"
+
+	// FormatDiscord posts a Discord webhook message.
+	FormatDiscord Format = "discord"
+)
+
+// Webhook is an endpoint events are posted to.
+type Webhook struct {
+	URL string
+
+	// Format defaults to FormatGeneric.
+	Format Format
+
+	// Events limits the webhook to the given event types. Empty means all.
+	Events []EventType
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers http.Header
+
+	// Payload builds the JSON body in place of Format when set.
+	Payload func(Event) (any, error)
+}
+
+func (w Webhook) subscribed(eventType EventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+func (w Webhook) payload(event Event) (any, error) {
+	if w.Payload != nil {
+		return w.Payload(event)
+	}
+
+	text := event.Text
+	if event.Instance != "" {
+		text = fmt.Sprintf("[%s] %s", event.Instance, text)
+	}
+
+	switch w.Format {
+	case FormatSlack:
+		return map[string]string{"text": text}, nil
+	case FormatDiscord:
+		return map[string]string{"content": text}, nil
+	default:
+		return event, nil
+	}
+}
+
+func (w Webhook) deliver(ctx context.Context, client *http.Client, event Event) error {
+	payload, err := w.payload(event)
+	if err != nil {
+		return fmt.Errorf("failed to build payload for %s: %w", w.URL, err)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload for %s: %w", w.URL, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range w.Headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", w.URL, err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed to post to %s: unexpected status %d", w.URL, res.StatusCode)
+	}
+
+	return nil
+}
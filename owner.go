@@ -0,0 +1,186 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ownerTagKey is the comment tag recording which automation manages a record.
+const ownerTagKey = "managed-by"
+
+// Owner identifies the automation managing a local DNS record. It is stored in the
+// record's comment as a managed-by=<owner> tag, so several automations can share one
+// Pi-hole without touching each other's records. Pi-hole's CNAME entries have no
+// comment, so only host records can be owned.
+type Owner string
+
+var ErrNotOwned = errors.New("record not owned")
+
+func (o Owner) validate() error {
+	if o == "" || strings.ContainsAny(string(o), " \t\r\n#") {
+		return fmt.Errorf("%w: invalid owner %q", ErrClientValidation, o)
+	}
+
+	return nil
+}
+
+// Owns reports whether record is tagged with o.
+func (o Owner) Owns(record DNSRecord) bool {
+	owner, ok := OwnerOf(record)
+	return ok && owner == o
+}
+
+// Tag returns a copy of record whose comment is tagged with o, replacing any other
+// owner.
+func (o Owner) Tag(record DNSRecord) DNSRecord {
+	record.Comment = setCommentTag(record.Comment, ownerTagKey, string(o))
+	record.raw = ""
+
+	return record
+}
+
+// OwnerOf returns the owner record is tagged with, if any.
+func OwnerOf(record DNSRecord) (Owner, bool) {
+	owner, ok := commentTag(record.Comment, ownerTagKey)
+	return Owner(owner), ok && owner != ""
+}
+
+// OwnedDNS manages the local DNS records tagged with one Owner and leaves every other
+// record alone.
+type OwnedDNS struct {
+	client *Client
+	owner  Owner
+}
+
+// OwnedApplyResult reports the changes Apply made.
+type OwnedApplyResult struct {
+	Created   DNSRecordList
+	Deleted   DNSRecordList
+	Unchanged DNSRecordList
+}
+
+// Owned returns a view of the local DNS records owned by owner.
+func (c *Client) Owned(owner Owner) *OwnedDNS {
+	return &OwnedDNS{client: c, owner: owner}
+}
+
+// List returns the records tagged with the owner.
+func (o *OwnedDNS) List(ctx context.Context) (DNSRecordList, error) {
+	if err := o.owner.validate(); err != nil {
+		return nil, err
+	}
+
+	records, err := o.client.LocalDNS.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(DNSRecordList, 0)
+	for _, record := range records {
+		if o.owner.Owns(record) {
+			owned = append(owned, record)
+		}
+	}
+
+	return owned, nil
+}
+
+// Create creates record tagged with the owner.
+func (o *OwnedDNS) Create(ctx context.Context, record *DNSRecord) (*DNSRecord, error) {
+	if err := o.owner.validate(); err != nil {
+		return nil, err
+	}
+
+	tagged := o.owner.Tag(*record)
+	return o.client.LocalDNS.CreateRecord(ctx, &tagged)
+}
+
+// Delete removes the record for domain if it is tagged with the owner, and returns
+// ErrNotOwned if it belongs to someone else. Missing records are not an error.
+func (o *OwnedDNS) Delete(ctx context.Context, domain string) error {
+	if err := o.owner.validate(); err != nil {
+		return err
+	}
+
+	record, err := o.client.LocalDNS.Get(ctx, domain)
+	if err != nil {
+		if errors.Is(err, ErrorLocalDNSNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed looking up custom DNS record %s for deletion: %w", domain, err)
+	}
+
+	if !o.owner.Owns(*record) {
+		return fmt.Errorf("%w by %s: %s", ErrNotOwned, o.owner, domain)
+	}
+
+	return localDNS{client: o.client}.delete(ctx, record)
+}
+
+// Apply makes the owner's records match desired: missing records are created with
+// the owner's tag, owned records that differ are replaced and owned records that are
+// not desired are deleted. Records of other owners are never touched. Apply carries
+// on past failed records and returns an *OperationError for each, joined.
+func (o *OwnedDNS) Apply(ctx context.Context, desired DNSRecordList) (*OwnedApplyResult, error) {
+	owned, err := o.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]DNSRecord, len(owned))
+	for _, record := range owned {
+		existing[record.ID()] = record
+	}
+
+	result := &OwnedApplyResult{
+		Created:   make(DNSRecordList, 0),
+		Deleted:   make(DNSRecordList, 0),
+		Unchanged: make(DNSRecordList, 0),
+	}
+
+	dns := localDNS{client: o.client}
+	wanted := make(map[string]bool, len(desired))
+	var errs []error
+
+	for _, record := range desired {
+		tagged := o.owner.Tag(record)
+		wanted[tagged.ID()] = true
+
+		if current, ok := existing[tagged.ID()]; ok {
+			if current.Equal(tagged) {
+				result.Unchanged = append(result.Unchanged, current)
+				continue
+			}
+
+			if err := dns.delete(ctx, &current); err != nil {
+				errs = append(errs, &OperationError{Operation: AuditLocalDNSDelete, Target: current.Domain, Err: fmt.Errorf("failed to replace record: %w", err)})
+				continue
+			}
+			result.Deleted = append(result.Deleted, current)
+		}
+
+		created, err := dns.CreateRecord(ctx, &tagged)
+		if err != nil {
+			errs = append(errs, &OperationError{Operation: AuditLocalDNSCreate, Target: tagged.Domain, Err: err})
+			continue
+		}
+		result.Created = append(result.Created, *created)
+	}
+
+	for _, record := range owned {
+		if wanted[record.ID()] {
+			continue
+		}
+
+		if err := dns.delete(ctx, &record); err != nil {
+			errs = append(errs, &OperationError{Operation: AuditLocalDNSDelete, Target: record.Domain, Err: err})
+			continue
+		}
+		result.Deleted = append(result.Deleted, record)
+	}
+
+	return result, errors.Join(errs...)
+}
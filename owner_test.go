@@ -0,0 +1,135 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHostsTestClient stubs the hosts config array backed by hosts, which PUT and
+// DELETE requests modify.
+func newHostsTestClient(t *testing.T, hosts *[]string) *Client {
+	t.Helper()
+
+	const prefix = "/api/config/dns/hosts/"
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			b, err := json.Marshal(map[string]any{"config": map[string]any{"dns": map[string]any{"hosts": *hosts}}})
+			require.NoError(t, err)
+			return newHTTPResponse(http.StatusOK, string(b)), nil
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, prefix):
+			line, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), prefix))
+			require.NoError(t, err)
+			*hosts = append(*hosts, line)
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, prefix):
+			line, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), prefix))
+			require.NoError(t, err)
+			*hosts = slices.DeleteFunc(*hosts, func(host string) bool { return host == line })
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestOwnerTags(t *testing.T) {
+	record := Owner("myapp").Tag(DNSRecord{Domain: "nas.lan", IP: "10.0.0.1", Comment: "rack 2 managed-by=other"})
+	assert.Equal(t, "rack 2 managed-by=myapp", record.Comment)
+	assert.True(t, Owner("myapp").Owns(record))
+	assert.False(t, Owner("other").Owns(record))
+
+	owner, ok := OwnerOf(DNSRecord{Comment: "web"})
+	assert.False(t, ok)
+	assert.Empty(t, owner)
+
+	_, err := (&OwnedDNS{owner: "my app"}).List(context.Background())
+	assert.ErrorIs(t, err, ErrClientValidation)
+}
+
+func TestOwnedDNS_ApplyOnlyTouchesOwnedRecords(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{
+		"10.0.0.1 nas.lan # managed-by=other",
+		"10.0.0.2 printer.lan # managed-by=myapp",
+		"10.0.0.3 old.lan # managed-by=myapp",
+		"10.0.0.4 manual.lan",
+	}
+	client := newHostsTestClient(t, &hosts)
+	owned := client.Owned("myapp")
+	ctx := context.Background()
+
+	result, err := owned.Apply(ctx, DNSRecordList{
+		{Domain: "printer.lan", IP: "10.0.0.2"},
+		{Domain: "vm1.lan", IP: "10.0.0.5"},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Unchanged, 1)
+	require.Len(t, result.Created, 1)
+	assert.Equal(t, "vm1.lan", result.Created[0].Domain)
+	require.Len(t, result.Deleted, 1)
+	assert.Equal(t, "old.lan", result.Deleted[0].Domain)
+
+	assert.ElementsMatch(t, []string{
+		"10.0.0.1 nas.lan # managed-by=other",
+		"10.0.0.2 printer.lan # managed-by=myapp",
+		"10.0.0.4 manual.lan",
+		"10.0.0.5 vm1.lan # managed-by=myapp",
+	}, hosts)
+
+	assert.ErrorIs(t, owned.Delete(ctx, "nas.lan"), ErrNotOwned)
+	assert.ErrorIs(t, owned.Delete(ctx, "manual.lan"), ErrNotOwned)
+	require.NoError(t, owned.Delete(ctx, "vm1.lan"))
+
+	list, err := owned.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "printer.lan", list[0].Domain)
+}
+
+func TestOwnedDNS_ApplyContinuesPastFailures(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.3 old.lan # managed-by=myapp"}
+	client := newHostsTestClient(t, &hosts)
+	vetoed := errors.New("frozen")
+	client.OnBeforeMutation(func(ctx context.Context, m Mutation) error {
+		if m.Target == "db.lan" || m.Target == "old.lan" {
+			return vetoed
+		}
+		return nil
+	})
+
+	result, err := client.Owned("myapp").Apply(context.Background(), DNSRecordList{
+		{Domain: "db.lan", IP: "10.0.0.6"},
+		{Domain: "vm1.lan", IP: "10.0.0.5"},
+	})
+	require.ErrorIs(t, err, vetoed)
+
+	failed := OperationErrors(err)
+	require.Len(t, failed, 2)
+	assert.Equal(t, AuditLocalDNSCreate, failed[0].Operation)
+	assert.Equal(t, "db.lan", failed[0].Target)
+	assert.Equal(t, AuditLocalDNSDelete, failed[1].Operation)
+	assert.Equal(t, "old.lan", failed[1].Target)
+
+	require.Len(t, result.Created, 1)
+	assert.Equal(t, "vm1.lan", result.Created[0].Domain)
+}
@@ -0,0 +1,39 @@
+package pihole
+
+import "fmt"
+
+// SkippedEntry is a config entry that could not be parsed and was left out of a list.
+type SkippedEntry struct {
+	Raw string
+	Err error
+}
+
+func (e SkippedEntry) String() string {
+	return fmt.Sprintf("%q: %s", e.Raw, e.Err)
+}
+
+// ParseReport describes how the entries of a config array were parsed, so operators
+// can audit junk that has accumulated without losing access to the valid records.
+type ParseReport struct {
+	// Parsed is the number of entries returned as records.
+	Parsed int
+
+	// Skipped lists the entries that could not be parsed and why.
+	Skipped []SkippedEntry
+
+	// Raw holds every entry exactly as stored in Pi-hole's config.
+	Raw []string
+}
+
+func newParseReport(raw []string, parsed int, skipped []SkippedEntry) *ParseReport {
+	if skipped == nil {
+		skipped = make([]SkippedEntry, 0)
+	}
+
+	return &ParseReport{Parsed: parsed, Skipped: skipped, Raw: append([]string(nil), raw...)}
+}
+
+// Clean reports whether every entry was parsed.
+func (r *ParseReport) Clean() bool {
+	return len(r.Skipped) == 0
+}
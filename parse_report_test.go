@@ -0,0 +1,44 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListWithReport(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan","garbage"]}}}`), nil
+		case "/api/config/dns/cnameRecords":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"cnameRecords":["www.lan,nas.lan"]}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	records, report, err := client.LocalDNS.ListWithReport(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.False(t, report.Clean())
+	assert.Equal(t, 1, report.Parsed)
+	assert.Equal(t, []string{"10.0.0.1 nas.lan", "garbage"}, report.Raw)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, "garbage", report.Skipped[0].Raw)
+	assert.Error(t, report.Skipped[0].Err)
+
+	cnames, cnameReport, err := client.LocalCNAME.ListWithReport(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, cnames, 1)
+	assert.True(t, cnameReport.Clean())
+	assert.Empty(t, cnameReport.Skipped)
+}
@@ -0,0 +1,67 @@
+// Package piholetest provides helpers for stubbing the Pi-hole API in tests of code
+// that uses the pihole client.
+package piholetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RoundTripFunc is an http.RoundTripper backed by a function. Pass it as the
+// transport of the http.Client given to pihole.Config.HttpClient.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// HTTPClient returns an http.Client that answers every request with fn.
+func HTTPClient(fn RoundTripFunc) *http.Client {
+	return &http.Client{Transport: fn}
+}
+
+// Response returns a response with the given status code and raw body.
+func Response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// JSONResponse returns a response with the given status code and v encoded as JSON.
+// Strings and byte slices are sent as is, so literal JSON can be passed directly.
+func JSONResponse(status int, v interface{}) *http.Response {
+	var body string
+	switch v := v.(type) {
+	case string:
+		body = v
+	case []byte:
+		body = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			panic("piholetest: failed to encode response body: " + err.Error())
+		}
+		body = string(b)
+	}
+
+	res := Response(status, body)
+	res.Header.Set("Content-Type", "application/json")
+
+	return res
+}
+
+// ErrorResponse returns a response carrying a Pi-hole API error with the given
+// status code, key and message.
+func ErrorResponse(status int, key string, message string) *http.Response {
+	return JSONResponse(status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"key":     key,
+			"message": message,
+			"hint":    nil,
+		},
+	})
+}
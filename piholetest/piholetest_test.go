@@ -0,0 +1,49 @@
+package piholetest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONResponse(t *testing.T) {
+	tcs := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "literal", v: `{"took":0.1}`, want: `{"took":0.1}`},
+		{name: "bytes", v: []byte(`{"took":0.1}`), want: `{"took":0.1}`},
+		{name: "value", v: map[string]int{"count": 2}, want: `{"count":2}`},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := JSONResponse(http.StatusOK, tc.v)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(b))
+		})
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	client := HTTPClient(func(req *http.Request) (*http.Response, error) {
+		return ErrorResponse(http.StatusNotFound, "not_found", req.URL.Path), nil
+	})
+
+	res, err := client.Get("http://pi.test/api/lists")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.JSONEq(t, `{"error":{"key":"not_found","message":"/api/lists","hint":null}}`, string(b))
+}
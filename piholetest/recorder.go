@@ -0,0 +1,272 @@
+package piholetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to a live Pi-hole or replays a fixture.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the fixture file without network access.
+	ModeReplay Mode = iota
+
+	// ModeRecord forwards requests to the real transport and saves the exchanges to
+	// the fixture file when the recorder is stopped.
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when acceptance tests are enabled with TEST_ACC=1
+// and ModeReplay otherwise.
+func ModeFromEnv() Mode {
+	if os.Getenv("TEST_ACC") == "1" {
+		return ModeRecord
+	}
+
+	return ModeReplay
+}
+
+var (
+	ErrNoFixture          = errors.New("no recorded interaction matches request")
+	ErrFixtureUnavailable = errors.New("fixture file is unavailable")
+)
+
+// redactedHeaders are never written to fixture files.
+var redactedHeaders = []string{"X-Ftl-Sid", "X-Ftl-Csrf", "X-Ftl-Apikey", "Authorization", "Cookie", "Set-Cookie"}
+
+// redacted replaces the strings of authentication responses in fixture files.
+const redacted = "REDACTED"
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records exchanges with a live Pi-hole to a
+// golden file and replays them later, so tests can run without an instance.
+//
+// Requests are matched on method, path with query and body, in recorded order.
+// Session, CSRF and API key headers are never recorded. The request bodies of the
+// authentication endpoints are dropped and every string in their responses is
+// redacted, so that passwords, session IDs and app passwords do not end up in
+// fixtures.
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a recorder for the fixture at path. In ModeRecord, requests
+// are sent through transport, or http.DefaultTransport when it is nil. In
+// ModeReplay, the fixture is loaded immediately.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, transport: transport}
+
+	if mode == ModeRecord {
+		if r.transport == nil {
+			r.transport = http.DefaultTransport
+		}
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFixtureUnavailable, err)
+	}
+
+	if err := json.Unmarshal(b, &r.interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+
+	return r, nil
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// HTTPClient returns an http.Client using the recorder as its transport.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := res.Header.Clone()
+	for _, name := range redactedHeaders {
+		header.Del(name)
+	}
+
+	recordedBody := string(body)
+	if isAuthPath(req.URL.Path) {
+		recordedBody = redactBody(body)
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request:  recorded,
+		Response: RecordedResponse{StatusCode: res.StatusCode, Header: header, Body: recordedBody},
+	})
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request != recorded {
+			continue
+		}
+		r.used[i] = true
+
+		res := Response(interaction.Response.StatusCode, interaction.Response.Body)
+		for name, values := range interaction.Response.Header {
+			res.Header[name] = append([]string(nil), values...)
+		}
+		res.Request = req
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, recorded.Method, recorded.URL)
+}
+
+// Stop writes the recorded interactions to the fixture file. It does nothing in
+// ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	if err := os.WriteFile(r.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", r.path, err)
+	}
+
+	return nil
+}
+
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{Method: req.Method, URL: req.URL.RequestURI()}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return RecordedRequest{}, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Login and app password requests carry secrets.
+	if isAuthPath(req.URL.Path) {
+		return recorded, nil
+	}
+
+	recorded.Body = string(body)
+
+	return recorded, nil
+}
+
+// isAuthPath reports whether path is an authentication endpoint, whatever prefix
+// the API is served under.
+func isAuthPath(path string) bool {
+	return strings.HasSuffix(path, "/auth") || strings.Contains(path, "/auth/")
+}
+
+// redactBody replaces every string in a JSON body, keeping its structure so that
+// replayed logins still decode. Other bodies are dropped.
+func redactBody(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return ""
+	}
+
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return redacted
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = redactValue(v[key])
+		}
+	}
+
+	return v
+}
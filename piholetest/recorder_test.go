@@ -0,0 +1,106 @@
+package piholetest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderRecordsAndReplays(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixtures", "lists.json")
+
+	live := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		res := JSONResponse(http.StatusOK, `{"lists":[]}`)
+		res.Header.Set("Set-Cookie", "sid=secret")
+		return res, nil
+	})
+
+	recorder, err := NewRecorder(fixture, ModeRecord, live)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://pi.test/api/auth", strings.NewReader(`{"password":"secret"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-FTL-SID", "secret")
+	_, err = recorder.HTTPClient().Do(req)
+	require.NoError(t, err)
+
+	_, err = recorder.HTTPClient().Get("http://pi.test/api/lists?type=block")
+	require.NoError(t, err)
+	require.NoError(t, recorder.Stop())
+
+	replayer, err := NewRecorder(fixture, ModeReplay, nil)
+	require.NoError(t, err)
+
+	res, err := replayer.HTTPClient().Get("http://pi.test/api/lists?type=block")
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"lists":[]}`, string(b))
+	assert.Empty(t, res.Header.Get("Set-Cookie"))
+
+	_, err = replayer.HTTPClient().Get("http://pi.test/api/lists?type=block")
+	assert.ErrorIs(t, err, ErrNoFixture)
+
+	for _, interaction := range replayer.interactions {
+		assert.NotContains(t, interaction.Request.Body, "secret")
+	}
+}
+
+func TestRecorderRedactsAuthentication(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "auth.json")
+
+	live := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var res *http.Response
+		switch req.URL.Path {
+		case "/admin/api/auth":
+			res = JSONResponse(http.StatusOK, `{"session":{"valid":true,"sid":"secret-sid","csrf":"secret-csrf","validity":300}}`)
+		case "/admin/api/auth/app":
+			res = JSONResponse(http.StatusOK, `{"app":{"password":"secret-password","hash":"secret-hash"}}`)
+		default:
+			res = JSONResponse(http.StatusOK, `{}`)
+		}
+		res.Header.Set("X-FTL-CSRF", "secret-csrf")
+		return res, nil
+	})
+
+	recorder, err := NewRecorder(fixture, ModeRecord, live)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://pi.test/admin/api/auth", strings.NewReader(`{"password":"secret-login"}`))
+	require.NoError(t, err)
+	_, err = recorder.HTTPClient().Do(req)
+	require.NoError(t, err)
+
+	_, err = recorder.HTTPClient().Get("http://pi.test/admin/api/auth/app")
+	require.NoError(t, err)
+
+	req, err = http.NewRequest(http.MethodPatch, "http://pi.test/admin/api/auth/app", strings.NewReader(`{"password":"secret-app"}`))
+	require.NoError(t, err)
+	_, err = recorder.HTTPClient().Do(req)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Stop())
+
+	b, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+
+	// Replayed logins still decode.
+	replayer, err := NewRecorder(fixture, ModeReplay, nil)
+	require.NoError(t, err)
+	res, err := replayer.HTTPClient().Post("http://pi.test/admin/api/auth", "application/json", strings.NewReader(`{"password":"other"}`))
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"session":{"valid":true,"sid":"REDACTED","csrf":"REDACTED","validity":300}}`, string(body))
+}
+
+func TestRecorderMissingFixture(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+	assert.ErrorIs(t, err, ErrFixtureUnavailable)
+}
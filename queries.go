@@ -0,0 +1,259 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+type Queries interface {
+	// List returns one page of the query log matching the filter.
+	List(ctx context.Context, filter *QueryFilter) (*QueryPage, error)
+
+	// ListPages calls fn with consecutive pages of the query log matching the filter,
+	// pinned to the cursor of the first page, until the log is exhausted. Return
+	// ErrStopPaging from fn to stop early; any other error is returned as is.
+	ListPages(ctx context.Context, filter *QueryFilter, fn func(*QueryPage) error) error
+}
+
+type queries struct {
+	client *Client
+}
+
+const defaultQueryPageSize = 100
+
+// QueryStatus is the status FTL assigned to a query, e.g. GRAVITY or FORWARDED.
+type QueryStatus string
+
+const (
+	QueryStatusUnknown             QueryStatus = "UNKNOWN"
+	QueryStatusGravity             QueryStatus = "GRAVITY"
+	QueryStatusForwarded           QueryStatus = "FORWARDED"
+	QueryStatusCache               QueryStatus = "CACHE"
+	QueryStatusRegex               QueryStatus = "REGEX"
+	QueryStatusDenylist            QueryStatus = "DENYLIST"
+	QueryStatusExternalBlockedIP   QueryStatus = "EXTERNAL_BLOCKED_IP"
+	QueryStatusExternalBlockedNull QueryStatus = "EXTERNAL_BLOCKED_NULL"
+	QueryStatusExternalBlockedNXRA QueryStatus = "EXTERNAL_BLOCKED_NXRA"
+	QueryStatusGravityCNAME        QueryStatus = "GRAVITY_CNAME"
+	QueryStatusRegexCNAME          QueryStatus = "REGEX_CNAME"
+	QueryStatusDenylistCNAME       QueryStatus = "DENYLIST_CNAME"
+	QueryStatusRetried             QueryStatus = "RETRIED"
+	QueryStatusRetriedDNSSEC       QueryStatus = "RETRIED_DNSSEC"
+	QueryStatusInProgress          QueryStatus = "IN_PROGRESS"
+	QueryStatusDBBusy              QueryStatus = "DBBUSY"
+	QueryStatusSpecialDomain       QueryStatus = "SPECIAL_DOMAIN"
+	QueryStatusCacheStale          QueryStatus = "CACHE_STALE"
+	QueryStatusExternalBlockedEDE  QueryStatus = "EXTERNAL_BLOCKED_EDE15"
+
+	// QueryStatusBlocked matches every blocked status in a QueryFilter. FTL never
+	// reports it for a query.
+	QueryStatusBlocked QueryStatus = "BLOCKED"
+)
+
+// Blocked reports whether the status means the query was blocked.
+func (s QueryStatus) Blocked() bool {
+	switch s {
+	case QueryStatusBlocked,
+		QueryStatusGravity,
+		QueryStatusRegex,
+		QueryStatusDenylist,
+		QueryStatusExternalBlockedIP,
+		QueryStatusExternalBlockedNull,
+		QueryStatusExternalBlockedNXRA,
+		QueryStatusGravityCNAME,
+		QueryStatusRegexCNAME,
+		QueryStatusDenylistCNAME,
+		QueryStatusDBBusy,
+		QueryStatusSpecialDomain,
+		QueryStatusExternalBlockedEDE:
+		return true
+	default:
+		return false
+	}
+}
+
+type Query struct {
+	ID       int64
+	Time     time.Time
+	Type     string
+	Status   QueryStatus
+	DNSSEC   string
+	Domain   string
+	Upstream string
+	CNAME    string
+	Reply    QueryReply
+	Client   QueryClient
+}
+
+type QueryReply struct {
+	Type string
+	Time time.Duration
+}
+
+type QueryClient struct {
+	IP   string
+	Name string
+}
+
+type QueryList []Query
+
+// QueryPage is one page of the query log. Cursor should be passed to the next
+// request through QueryFilter.Cursor so that pagination is stable while new
+// queries arrive.
+type QueryPage struct {
+	Queries         QueryList
+	Cursor          int64
+	RecordsTotal    int
+	RecordsFiltered int
+}
+
+type queryResponse struct {
+	ID       int64               `json:"id"`
+	Time     float64             `json:"time"`
+	Type     string              `json:"type"`
+	Status   string              `json:"status"`
+	DNSSEC   string              `json:"dnssec"`
+	Domain   string              `json:"domain"`
+	Upstream string              `json:"upstream"`
+	CNAME    string              `json:"cname"`
+	Reply    queryReplyResponse  `json:"reply"`
+	Client   queryClientResponse `json:"client"`
+}
+
+type queryReplyResponse struct {
+	Type string  `json:"type"`
+	Time float64 `json:"time"`
+}
+
+type queryClientResponse struct {
+	IP   string `json:"ip"`
+	Name string `json:"name"`
+}
+
+type queryListResponse struct {
+	Queries         []queryResponse `json:"queries"`
+	Cursor          int64           `json:"cursor"`
+	RecordsTotal    int             `json:"recordsTotal"`
+	RecordsFiltered int             `json:"recordsFiltered"`
+}
+
+func (res queryResponse) toQuery() Query {
+	query := Query{
+		ID:       res.ID,
+		Time:     unixFloat(res.Time),
+		Type:     res.Type,
+		Status:   QueryStatus(res.Status),
+		DNSSEC:   res.DNSSEC,
+		Domain:   res.Domain,
+		Upstream: res.Upstream,
+		CNAME:    res.CNAME,
+		Reply:    QueryReply{Type: res.Reply.Type},
+		Client:   QueryClient{IP: res.Client.IP, Name: res.Client.Name},
+	}
+
+	// FTL reports the reply time in milliseconds and uses a negative value when unknown.
+	if res.Reply.Time > 0 {
+		query.Reply.Time = time.Duration(res.Reply.Time * float64(time.Millisecond))
+	}
+
+	return query
+}
+
+func (res queryListResponse) toQueryList() QueryList {
+	list := make(QueryList, 0, len(res.Queries))
+	for _, entry := range res.Queries {
+		list = append(list, entry.toQuery())
+	}
+
+	return list
+}
+
+// List returns a page of the query log matching the filter. A nil filter returns
+// the most recent queries
+func (q queries) List(ctx context.Context, filter *QueryFilter) (*QueryPage, error) {
+	if filter == nil {
+		filter = NewQueryFilter()
+	}
+
+	vals, err := filter.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/api/queries"
+	if len(vals) > 0 {
+		path += "?" + vals.Encode()
+	}
+
+	res, err := q.client.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newQueryAPIError(res, b)
+	}
+
+	var resList queryListResponse
+	if err := json.NewDecoder(res.Body).Decode(&resList); err != nil {
+		return nil, fmt.Errorf("failed to parse query list body: %w", err)
+	}
+
+	return &QueryPage{
+		Queries:         resList.toQueryList(),
+		Cursor:          resList.Cursor,
+		RecordsTotal:    resList.RecordsTotal,
+		RecordsFiltered: resList.RecordsFiltered,
+	}, nil
+}
+
+// ListPages pages through the query log with the filter's length, 100 by default.
+// Only one page is held at a time, so the caller decides how much of the log to
+// keep.
+func (q queries) ListPages(ctx context.Context, filter *QueryFilter, fn func(*QueryPage) error) error {
+	pageFilter := QueryFilter{}
+	if filter != nil {
+		pageFilter = *filter
+	}
+	if pageFilter.length == 0 {
+		pageFilter.length = defaultQueryPageSize
+	}
+
+	for {
+		page, err := q.List(ctx, &pageFilter)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(page); err != nil {
+			if errors.Is(err, ErrStopPaging) {
+				return nil
+			}
+			return err
+		}
+
+		if len(page.Queries) < pageFilter.length {
+			return nil
+		}
+
+		if pageFilter.cursor == 0 {
+			pageFilter.cursor = page.Cursor
+		}
+		pageFilter.start += pageFilter.length
+	}
+}
+
+// unixFloat converts FTL's fractional Unix timestamps without losing precision to
+// float rounding of the whole value.
+func unixFloat(ts float64) time.Time {
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e6))*int64(time.Microsecond))
+}
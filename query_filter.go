@@ -0,0 +1,248 @@
+package pihole
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidQueryFilter = errors.New("invalid query filter")
+)
+
+const blocklistUpstream = "blocklist"
+
+// QueryFilter builds the query parameters understood by /api/queries. Methods can be
+// chained; the first invalid value is reported by Encode.
+//
+//	filter := pihole.NewQueryFilter().
+//		Client("10.0.0.2").
+//		Status(pihole.QueryStatusGravity).
+//		Between(from, until)
+type QueryFilter struct {
+	clientIP   string
+	clientName string
+	domain     string
+	upstream   string
+	queryType  string
+	reply      string
+	dnssec     string
+	status     QueryStatus
+	from       time.Time
+	until      time.Time
+	length     int
+	start      int
+	cursor     int64
+	disk       bool
+	errs       []error
+}
+
+// NewQueryFilter returns an empty filter matching every query.
+func NewQueryFilter() *QueryFilter {
+	return &QueryFilter{}
+}
+
+// Client filters by client. IP addresses match client_ip, anything else matches
+// the client's hostname.
+func (f *QueryFilter) Client(client string) *QueryFilter {
+	client = strings.TrimSpace(client)
+	if client == "" {
+		return f.invalid("client must not be empty")
+	}
+
+	if net.ParseIP(client) != nil {
+		f.clientIP = client
+	} else {
+		f.clientName = client
+	}
+
+	return f
+}
+
+// Domain filters by queried domain. FTL accepts * as a wildcard.
+func (f *QueryFilter) Domain(domain string) *QueryFilter {
+	domain = strings.TrimSpace(domain)
+	if domain == "" {
+		return f.invalid("domain must not be empty")
+	}
+
+	f.domain = domain
+	return f
+}
+
+// Upstream filters by the upstream server a query was forwarded to, e.g. 8.8.8.8#53,
+// or by the pseudo upstreams "blocklist", "cache" and "permitted".
+func (f *QueryFilter) Upstream(upstream string) *QueryFilter {
+	upstream = strings.TrimSpace(upstream)
+	if upstream == "" {
+		return f.invalid("upstream must not be empty")
+	}
+
+	f.upstream = upstream
+	return f
+}
+
+// Type filters by query type, e.g. A or AAAA.
+func (f *QueryFilter) Type(queryType string) *QueryFilter {
+	queryType = strings.ToUpper(strings.TrimSpace(queryType))
+	if queryType == "" {
+		return f.invalid("query type must not be empty")
+	}
+
+	f.queryType = queryType
+	return f
+}
+
+// Reply filters by reply type, e.g. NXDOMAIN or IP.
+func (f *QueryFilter) Reply(reply string) *QueryFilter {
+	reply = strings.ToUpper(strings.TrimSpace(reply))
+	if reply == "" {
+		return f.invalid("reply type must not be empty")
+	}
+
+	f.reply = reply
+	return f
+}
+
+// DNSSEC filters by DNSSEC status, e.g. SECURE or BOGUS.
+func (f *QueryFilter) DNSSEC(status string) *QueryFilter {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status == "" {
+		return f.invalid("DNSSEC status must not be empty")
+	}
+
+	f.dnssec = status
+	return f
+}
+
+// Status filters by query status. QueryStatusBlocked matches every blocked status.
+func (f *QueryFilter) Status(status QueryStatus) *QueryFilter {
+	if status == "" {
+		return f.invalid("status must not be empty")
+	}
+
+	f.status = status
+	return f
+}
+
+// Between limits results to queries made in the window. A zero until means now.
+func (f *QueryFilter) Between(from time.Time, until time.Time) *QueryFilter {
+	if from.IsZero() {
+		return f.invalid("from must not be zero")
+	}
+
+	if !until.IsZero() && from.After(until) {
+		return f.invalid(fmt.Sprintf("from %s is after until %s", from, until))
+	}
+
+	f.from = from
+	f.until = until
+	return f
+}
+
+// Range limits results to queries made in the time range.
+func (f *QueryFilter) Range(r TimeRange) *QueryFilter {
+	return f.Between(r.From, r.Until)
+}
+
+// Length sets the page size.
+func (f *QueryFilter) Length(length int) *QueryFilter {
+	if length <= 0 {
+		return f.invalid(fmt.Sprintf("length must be positive, got %d", length))
+	}
+
+	f.length = length
+	return f
+}
+
+// Start sets the offset of the first result in the page.
+func (f *QueryFilter) Start(start int) *QueryFilter {
+	if start < 0 {
+		return f.invalid(fmt.Sprintf("start must not be negative, got %d", start))
+	}
+
+	f.start = start
+	return f
+}
+
+// Cursor pins pagination to the cursor returned by a previous page.
+func (f *QueryFilter) Cursor(cursor int64) *QueryFilter {
+	f.cursor = cursor
+	return f
+}
+
+// Disk reads queries from the long-term database instead of memory.
+func (f *QueryFilter) Disk() *QueryFilter {
+	f.disk = true
+	return f
+}
+
+func (f *QueryFilter) invalid(msg string) *QueryFilter {
+	f.errs = append(f.errs, fmt.Errorf("%w: %s", ErrInvalidQueryFilter, msg))
+	return f
+}
+
+// Encode validates the filter and returns its query parameters.
+func (f *QueryFilter) Encode() (url.Values, error) {
+	if len(f.errs) > 0 {
+		return nil, errors.Join(f.errs...)
+	}
+
+	if f.clientIP != "" && f.clientName != "" {
+		return nil, fmt.Errorf("%w: client IP and client name are mutually exclusive", ErrInvalidQueryFilter)
+	}
+
+	if f.start > 0 && f.length == 0 {
+		return nil, fmt.Errorf("%w: start requires a length", ErrInvalidQueryFilter)
+	}
+
+	// FTL filters blocked queries through the blocklist pseudo upstream.
+	upstream, status := f.upstream, f.status
+	if status == QueryStatusBlocked {
+		if upstream != "" && upstream != blocklistUpstream {
+			return nil, fmt.Errorf("%w: blocked status and upstream %s are mutually exclusive", ErrInvalidQueryFilter, upstream)
+		}
+		upstream, status = blocklistUpstream, ""
+	}
+
+	vals := url.Values{}
+	setIfNotEmpty := func(key string, value string) {
+		if value != "" {
+			vals.Set(key, value)
+		}
+	}
+
+	setIfNotEmpty("client_ip", f.clientIP)
+	setIfNotEmpty("client_name", f.clientName)
+	setIfNotEmpty("domain", f.domain)
+	setIfNotEmpty("upstream", upstream)
+	setIfNotEmpty("type", f.queryType)
+	setIfNotEmpty("reply", f.reply)
+	setIfNotEmpty("dnssec", f.dnssec)
+	setIfNotEmpty("status", string(status))
+
+	if !f.from.IsZero() {
+		vals.Set("from", strconv.FormatInt(f.from.Unix(), 10))
+	}
+	if !f.until.IsZero() {
+		vals.Set("until", strconv.FormatInt(f.until.Unix(), 10))
+	}
+	if f.length > 0 {
+		vals.Set("length", strconv.Itoa(f.length))
+	}
+	if f.start > 0 {
+		vals.Set("start", strconv.Itoa(f.start))
+	}
+	if f.cursor != 0 {
+		vals.Set("cursor", strconv.FormatInt(f.cursor, 10))
+	}
+	if f.disk {
+		vals.Set("disk", "true")
+	}
+
+	return vals, nil
+}
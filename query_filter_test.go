@@ -0,0 +1,144 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFilter_Encode(t *testing.T) {
+	t.Run("encodes all parameters", func(t *testing.T) {
+		vals, err := NewQueryFilter().
+			Client("10.0.0.2").
+			Domain("*.example.com").
+			Status(QueryStatusGravity).
+			Type("aaaa").
+			Upstream("blocklist").
+			Between(time.Unix(1700000000, 0), time.Unix(1700003600, 0)).
+			Length(50).
+			Start(100).
+			Cursor(991).
+			Disk().
+			Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, "10.0.0.2", vals.Get("client_ip"))
+		assert.Empty(t, vals.Get("client_name"))
+		assert.Equal(t, "*.example.com", vals.Get("domain"))
+		assert.Equal(t, "GRAVITY", vals.Get("status"))
+		assert.Equal(t, "AAAA", vals.Get("type"))
+		assert.Equal(t, "blocklist", vals.Get("upstream"))
+		assert.Equal(t, "1700000000", vals.Get("from"))
+		assert.Equal(t, "1700003600", vals.Get("until"))
+		assert.Equal(t, "50", vals.Get("length"))
+		assert.Equal(t, "100", vals.Get("start"))
+		assert.Equal(t, "991", vals.Get("cursor"))
+		assert.Equal(t, "true", vals.Get("disk"))
+	})
+
+	t.Run("filters blocked queries by the blocklist upstream", func(t *testing.T) {
+		vals, err := NewQueryFilter().Status(QueryStatusBlocked).Encode()
+		require.NoError(t, err)
+		assert.Equal(t, "blocklist", vals.Get("upstream"))
+		assert.Empty(t, vals.Get("status"))
+	})
+
+	t.Run("uses client name for hostnames", func(t *testing.T) {
+		vals, err := NewQueryFilter().Client("laptop.lan").Encode()
+		require.NoError(t, err)
+		assert.Equal(t, "laptop.lan", vals.Get("client_name"))
+		assert.Empty(t, vals.Get("client_ip"))
+	})
+
+	invalid := map[string]*QueryFilter{
+		"empty domain":     NewQueryFilter().Domain(" "),
+		"inverted window":  NewQueryFilter().Between(time.Unix(20, 0), time.Unix(10, 0)),
+		"negative start":   NewQueryFilter().Length(10).Start(-1),
+		"zero length":      NewQueryFilter().Length(0),
+		"start no length":  NewQueryFilter().Start(10),
+		"ip and hostname":  NewQueryFilter().Client("10.0.0.2").Client("laptop.lan"),
+		"empty status":     NewQueryFilter().Status(""),
+		"zero from":        NewQueryFilter().Between(time.Time{}, time.Now()),
+		"empty upstream":   NewQueryFilter().Upstream(""),
+		"empty query type": NewQueryFilter().Type(""),
+		"blocked upstream": NewQueryFilter().Status(QueryStatusBlocked).Upstream("8.8.8.8#53"),
+	}
+
+	for name, filter := range invalid {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := filter.Encode()
+			assert.ErrorIs(t, err, ErrInvalidQueryFilter)
+		})
+	}
+}
+
+func TestQueries_ListEncodesFilter(t *testing.T) {
+	isUnit(t)
+
+	var rawQuery string
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		rawQuery = req.URL.RawQuery
+		return newHTTPResponse(http.StatusOK, `{"queries":[],"cursor":12,"recordsTotal":40,"recordsFiltered":3}`), nil
+	})
+	require.NoError(t, err)
+
+	page, err := client.Queries.List(context.Background(), NewQueryFilter().Domain("ads.example").Length(10))
+	require.NoError(t, err)
+	assert.Equal(t, "domain=ads.example&length=10", rawQuery)
+	assert.Equal(t, int64(12), page.Cursor)
+	assert.Equal(t, 3, page.RecordsFiltered)
+
+	_, err = client.Queries.List(context.Background(), NewQueryFilter().Length(-1))
+	assert.ErrorIs(t, err, ErrInvalidQueryFilter)
+}
+
+func TestQueries_ListReturnsQueryAPIError(t *testing.T) {
+	isUnit(t)
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"Invalid request","hint":"cursor"}}`), nil
+	})
+	require.NoError(t, err)
+
+	_, err = client.Queries.List(context.Background(), nil)
+	var apiErr *QueryAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "bad_request", apiErr.Key)
+	assert.Contains(t, err.Error(), "query")
+}
+
+func TestQueries_ListPages(t *testing.T) {
+	isUnit(t)
+
+	var requests []string
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.RawQuery)
+		return newHTTPResponse(http.StatusOK, `{"queries":[{"id":1,"status":"GRAVITY"},{"id":2,"status":"CACHE"}],"cursor":77}`), nil
+	})
+	require.NoError(t, err)
+
+	filter := NewQueryFilter().Domain("ads.example").Length(2)
+	pages := 0
+	err = client.Queries.ListPages(context.Background(), filter, func(page *QueryPage) error {
+		pages++
+		if pages == 3 {
+			return ErrStopPaging
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"domain=ads.example&length=2",
+		"cursor=77&domain=ads.example&length=2&start=2",
+		"cursor=77&domain=ads.example&length=2&start=4",
+	}, requests)
+
+	// The caller's filter is left as it was.
+	vals, err := filter.Encode()
+	require.NoError(t, err)
+	assert.Empty(t, vals.Get("cursor"))
+}
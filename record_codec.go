@@ -0,0 +1,138 @@
+package pihole
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RecordCodec converts between local records and the entries Pi-hole stores for
+// them: dns.hosts lines and dns.cnameRecords tuples. Set Config.RecordCodec to
+// support a Pi-hole release or fork whose entries use another syntax.
+//
+// Records read through a codec keep the entry they were parsed from and are written
+// back exactly as read, so Encode is only called for records built locally.
+type RecordCodec interface {
+	// ParseHost parses a dns.hosts line.
+	ParseHost(raw string) (DNSRecord, error)
+
+	// EncodeHost returns the dns.hosts line for a record.
+	EncodeHost(record DNSRecord) string
+
+	// ParseCNAME parses a dns.cnameRecords entry.
+	ParseCNAME(raw string) (CNAMERecord, error)
+
+	// EncodeCNAME returns the dns.cnameRecords entry for a record.
+	EncodeCNAME(record CNAMERecord) string
+}
+
+// DefaultRecordCodec reads and writes the entries of Pi-hole v6: hosts lines of the
+// form "ip domain [ttl] [aliases...] [# comment]" and CNAME tuples of the form
+// "domain,target[,ttl]".
+var DefaultRecordCodec RecordCodec = defaultRecordCodec{}
+
+type defaultRecordCodec struct{}
+
+func (defaultRecordCodec) ParseHost(raw string) (DNSRecord, error) {
+	return parseDNSRecord(raw)
+}
+
+func (defaultRecordCodec) EncodeHost(record DNSRecord) string {
+	parts := []string{strings.TrimSpace(record.IP), strings.TrimSpace(record.Domain)}
+	if record.HasTTL {
+		parts = append(parts, strconv.Itoa(record.TTL))
+	}
+	parts = append(parts, record.Aliases...)
+
+	if comment := strings.TrimSpace(record.Comment); comment != "" {
+		parts = append(parts, "#", comment)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (defaultRecordCodec) ParseCNAME(raw string) (CNAMERecord, error) {
+	return parseCNAMERecord(raw)
+}
+
+func (defaultRecordCodec) EncodeCNAME(record CNAMERecord) string {
+	parts := []string{strings.TrimSpace(record.Domain), strings.TrimSpace(record.Target)}
+	if record.HasTTL {
+		parts = append(parts, strconv.Itoa(record.TTL))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// recordCodec returns the codec set in the config, or the default.
+func (c *Client) recordCodec() RecordCodec {
+	if c.codec == nil {
+		return DefaultRecordCodec
+	}
+
+	return c.codec
+}
+
+// decodeHost parses a hosts line with codec, keeping the line so that the record is
+// written back unchanged.
+func decodeHost(codec RecordCodec, raw string) (DNSRecord, error) {
+	record, err := codec.ParseHost(raw)
+	if err != nil {
+		return record, err
+	}
+	if record.raw == "" {
+		record.raw = raw
+	}
+
+	return record, nil
+}
+
+// encodeHost returns the hosts line of record, as read if it came from the server
+// and still describes the record, and encoded with codec otherwise.
+func encodeHost(codec RecordCodec, record *DNSRecord) string {
+	if record.raw != "" {
+		if parsed, err := codec.ParseHost(record.raw); err == nil && sameHost(parsed, *record) {
+			return record.raw
+		}
+	}
+
+	return codec.EncodeHost(*record)
+}
+
+// sameHost reports whether a and b have identical fields, so that a line read from
+// the server is not written back for a record the caller has since edited.
+func sameHost(a DNSRecord, b DNSRecord) bool {
+	return a.IP == b.IP &&
+		a.Domain == b.Domain &&
+		slices.Equal(a.Aliases, b.Aliases) &&
+		a.TTL == b.TTL &&
+		a.HasTTL == b.HasTTL &&
+		a.Comment == b.Comment
+}
+
+// decodeCNAME parses a CNAME entry with codec, keeping the entry so that the record
+// is written back unchanged.
+func decodeCNAME(codec RecordCodec, raw string) (CNAMERecord, error) {
+	record, err := codec.ParseCNAME(raw)
+	if err != nil {
+		return record, err
+	}
+	if record.raw == "" {
+		record.raw = strings.TrimSpace(raw)
+	}
+
+	return record, nil
+}
+
+// encodeCNAME returns the CNAME entry of record, as read if it came from the server
+// and still describes the record, and encoded with codec otherwise.
+func encodeCNAME(codec RecordCodec, record *CNAMERecord) string {
+	if record.raw != "" {
+		if parsed, err := codec.ParseCNAME(record.raw); err == nil && parsed.Domain == record.Domain &&
+			parsed.Target == record.Target && parsed.TTL == record.TTL && parsed.HasTTL == record.HasTTL {
+			return record.raw
+		}
+	}
+
+	return codec.EncodeCNAME(*record)
+}
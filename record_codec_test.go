@@ -0,0 +1,84 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeCodec stores hosts as "domain|ip" and CNAMEs as "domain|target".
+type pipeCodec struct{}
+
+func (pipeCodec) ParseHost(raw string) (DNSRecord, error) {
+	domain, ip, ok := strings.Cut(raw, "|")
+	if !ok {
+		return DNSRecord{}, fmt.Errorf("invalid host %q", raw)
+	}
+	return DNSRecord{Domain: domain, IP: ip}, nil
+}
+
+func (pipeCodec) EncodeHost(record DNSRecord) string {
+	return record.Domain + "|" + record.IP
+}
+
+func (pipeCodec) ParseCNAME(raw string) (CNAMERecord, error) {
+	domain, target, ok := strings.Cut(raw, "|")
+	if !ok {
+		return CNAMERecord{}, fmt.Errorf("invalid CNAME %q", raw)
+	}
+	return CNAMERecord{Domain: domain, Target: target}, nil
+}
+
+func (pipeCodec) EncodeCNAME(record CNAMERecord) string {
+	return record.Domain + "|" + record.Target
+}
+
+func TestRecordCodec(t *testing.T) {
+	isUnit(t)
+
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", RecordCodec: pipeCodec{}})
+	require.NoError(t, err)
+	assert.Equal(t, pipeCodec{}, client.recordCodec())
+	clone, err := client.With()
+	require.NoError(t, err)
+	assert.Equal(t, pipeCodec{}, clone.recordCodec())
+
+	hosts := []string{"nas.lan|10.0.0.5"}
+	var ops []string
+	client = newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &hosts, &ops)
+	client.codec = pipeCodec{}
+
+	records, err := client.LocalDNS.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "10.0.0.5", records[0].IP)
+
+	_, err = client.LocalDNS.Create(context.Background(), "printer.lan", "10.0.0.6")
+	require.NoError(t, err)
+	require.NoError(t, client.LocalDNS.Delete(context.Background(), "nas.lan"))
+	assert.Equal(t, []string{"PUT printer.lan|10.0.0.6", "DELETE nas.lan|10.0.0.5"}, ops)
+
+	cnames := []string{"www.lan|nas.lan"}
+	ops = nil
+	client = newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &cnames, &ops)
+	client.codec = pipeCodec{}
+
+	_, err = client.LocalCNAME.Create(context.Background(), "media.lan", "nas.lan")
+	require.NoError(t, err)
+	require.NoError(t, client.LocalCNAME.Delete(context.Background(), "www.lan"))
+	assert.Equal(t, []string{"PUT media.lan|nas.lan", "DELETE www.lan|nas.lan"}, ops)
+}
+
+func TestDefaultRecordCodec(t *testing.T) {
+	record, err := DefaultRecordCodec.ParseHost("10.0.0.1 nas.lan 60 storage.lan # rack")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1 nas.lan 60 storage.lan # rack", DefaultRecordCodec.EncodeHost(record))
+
+	cname, err := DefaultRecordCodec.ParseCNAME("www.lan, nas.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "www.lan,nas.lan", DefaultRecordCodec.EncodeCNAME(cname))
+}
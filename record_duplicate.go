@@ -0,0 +1,45 @@
+package pihole
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDuplicateRecord = errors.New("record already exists")
+)
+
+// DuplicateRecordError is returned when creating a host record whose domain and IP
+// are already present, or a CNAME record for a domain that already has one. Existing
+// is the record found on the server, so callers can update it instead.
+type DuplicateRecordError struct {
+	Existing LocalRecord
+}
+
+func (e *DuplicateRecordError) Error() string {
+	return fmt.Sprintf("%s: %s %s %s", ErrDuplicateRecord, e.Existing.Kind, e.Existing.Name, e.Existing.Value)
+}
+
+func (e *DuplicateRecordError) Is(target error) bool {
+	return target == ErrDuplicateRecord
+}
+
+var (
+	ErrAmbiguousRecord = errors.New("record is ambiguous")
+)
+
+// AmbiguousRecordError is returned when a lookup that expects one record finds
+// several, such as stale duplicate CNAME entries for one domain. Records holds all of
+// them, so callers can remove the ones they do not want.
+type AmbiguousRecordError struct {
+	Name    string
+	Records []LocalRecord
+}
+
+func (e *AmbiguousRecordError) Error() string {
+	return fmt.Sprintf("%s: %d records for %s", ErrAmbiguousRecord, len(e.Records), e.Name)
+}
+
+func (e *AmbiguousRecordError) Is(target error) bool {
+	return target == ErrAmbiguousRecord
+}
@@ -0,0 +1,85 @@
+package pihole
+
+import (
+	"strings"
+)
+
+// TTLPresence selects records by whether they carry an explicit TTL.
+type TTLPresence int
+
+const (
+	TTLAny TTLPresence = iota
+	TTLSet
+	TTLUnset
+)
+
+// FilterOptions selects records from a DNSRecordList or CNAMERecordList. Empty fields
+// match everything.
+type FilterOptions struct {
+	// DomainSuffix keeps records in a subtree. "lab.internal" matches lab.internal
+	// and its subdomains, "*.lab.internal" only the subdomains. Host records match
+	// if any of their names does.
+	DomainSuffix string
+
+	// Target keeps CNAME records pointing at this domain, or host records with this
+	// IP address.
+	Target string
+
+	TTL TTLPresence
+}
+
+// Filter returns the records matching opts, in their current order.
+func (l DNSRecordList) Filter(opts FilterOptions) DNSRecordList {
+	filtered := make(DNSRecordList, 0, len(l))
+	for _, record := range l {
+		if opts.matchesNames(record.Names()) && opts.matchesTTL(record.HasTTL) &&
+			(opts.Target == "" || normalizeIP(record.IP) == normalizeIP(opts.Target)) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return filtered
+}
+
+// Filter returns the records matching opts, in their current order.
+func (l CNAMERecordList) Filter(opts FilterOptions) CNAMERecordList {
+	filtered := make(CNAMERecordList, 0, len(l))
+	for _, record := range l {
+		if opts.matchesNames([]string{record.Domain}) && opts.matchesTTL(record.HasTTL) &&
+			(opts.Target == "" || sameDomain(record.Target, opts.Target)) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return filtered
+}
+
+func (opts FilterOptions) matchesNames(names []string) bool {
+	if opts.DomainSuffix == "" {
+		return true
+	}
+
+	suffix := normalizeDomain(opts.DomainSuffix)
+	subdomainsOnly := strings.HasPrefix(suffix, "*.")
+	suffix = strings.TrimPrefix(strings.TrimPrefix(suffix, "*"), ".")
+
+	for _, name := range names {
+		name = normalizeDomain(name)
+		if strings.HasSuffix(name, "."+suffix) || (!subdomainsOnly && name == suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (opts FilterOptions) matchesTTL(hasTTL bool) bool {
+	switch opts.TTL {
+	case TTLSet:
+		return hasTTL
+	case TTLUnset:
+		return !hasTTL
+	default:
+		return true
+	}
+}
@@ -0,0 +1,49 @@
+package pihole
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCNAMERecordList_Filter(t *testing.T) {
+	list := CNAMERecordList{
+		{Domain: "lab.internal", Target: "gw.lan"},
+		{Domain: "git.lab.internal", Target: "web.lan", TTL: 300, HasTTL: true},
+		{Domain: "CI.Lab.Internal.", Target: "web.lan"},
+		{Domain: "collab.internal", Target: "web.lan"},
+	}
+
+	domains := func(l CNAMERecordList) []string {
+		names := make([]string, 0, len(l))
+		for _, record := range l {
+			names = append(names, record.Domain)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"lab.internal", "git.lab.internal", "CI.Lab.Internal."}, domains(list.Filter(FilterOptions{DomainSuffix: "lab.internal"})))
+	assert.Equal(t, []string{"git.lab.internal", "CI.Lab.Internal."}, domains(list.Filter(FilterOptions{DomainSuffix: "*.lab.internal"})))
+	assert.Equal(t, []string{"git.lab.internal", "CI.Lab.Internal.", "collab.internal"}, domains(list.Filter(FilterOptions{Target: "WEB.lan."})))
+	assert.Equal(t, []string{"git.lab.internal"}, domains(list.Filter(FilterOptions{DomainSuffix: "*.lab.internal", TTL: TTLSet})))
+	assert.Equal(t, []string{"CI.Lab.Internal."}, domains(list.Filter(FilterOptions{DomainSuffix: "*.lab.internal", TTL: TTLUnset})))
+	assert.Len(t, list.Filter(FilterOptions{}), len(list))
+}
+
+func TestDNSRecordList_Filter(t *testing.T) {
+	list := DNSRecordList{
+		{IP: "10.0.0.5", Domain: "nas.lan", Aliases: []string{"storage.lab.internal"}},
+		{IP: "fd00::1", Domain: "router.lab.internal", TTL: 60, HasTTL: true},
+		{IP: "10.0.0.9", Domain: "printer.lan"},
+	}
+
+	filtered := list.Filter(FilterOptions{DomainSuffix: "*.lab.internal"})
+	assert.Len(t, filtered, 2)
+
+	filtered = list.Filter(FilterOptions{Target: "FD00:0::1"})
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "router.lab.internal", filtered[0].Domain)
+	}
+
+	assert.Len(t, list.Filter(FilterOptions{TTL: TTLUnset}), 2)
+}
@@ -0,0 +1,102 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// lastSeenTagKey is the comment tag recording when a dynamically registered record
+// was last refreshed.
+const lastSeenTagKey = "last-seen"
+
+// LastSeen returns the last-seen stamp in the record's comment, if any.
+func LastSeen(record DNSRecord) (time.Time, bool) {
+	value, ok := commentTag(record.Comment, lastSeenTagKey)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seen, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return seen, true
+}
+
+// StampLastSeen returns a copy of record whose comment carries a last-seen=<t> tag.
+func StampLastSeen(record DNSRecord, t time.Time) DNSRecord {
+	record.Comment = setCommentTag(record.Comment, lastSeenTagKey, t.UTC().Format(time.RFC3339))
+	record.raw = ""
+
+	return record
+}
+
+// Touch registers record for the owner, or refreshes its last-seen stamp if the owner
+// already has a record for the same domain and IP. The refreshed line is created
+// before the previous one is removed, so the name keeps resolving throughout.
+func (o *OwnedDNS) Touch(ctx context.Context, record *DNSRecord) (*DNSRecord, error) {
+	owned, err := o.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stamped := StampLastSeen(o.owner.Tag(*record), time.Now())
+
+	// Touching twice within a second yields the same line, which already exists.
+	for _, previous := range owned {
+		if codec := o.client.recordCodec(); encodeHost(codec, &previous) == encodeHost(codec, &stamped) {
+			return &previous, nil
+		}
+	}
+
+	dns := localDNS{client: o.client}
+	created, err := dns.CreateRecord(ctx, &stamped)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, previous := range owned {
+		if previous.ID() != stamped.ID() {
+			continue
+		}
+
+		if err := dns.delete(ctx, &previous); err != nil {
+			return created, fmt.Errorf("failed to remove previous entry for %s: %w", previous.Domain, err)
+		}
+	}
+
+	return created, nil
+}
+
+// GC deletes the owner's records whose last-seen stamp is older than olderThan and
+// returns them. Records without a stamp are kept. Records that cannot be deleted are
+// skipped and reported as joined *OperationError values.
+func (o *OwnedDNS) GC(ctx context.Context, olderThan time.Duration) (DNSRecordList, error) {
+	owned, err := o.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	dns := localDNS{client: o.client}
+	deleted := make(DNSRecordList, 0)
+	var errs []error
+
+	for _, record := range owned {
+		seen, ok := LastSeen(record)
+		if !ok || !seen.Before(cutoff) {
+			continue
+		}
+
+		if err := dns.delete(ctx, &record); err != nil {
+			errs = append(errs, &OperationError{Operation: AuditLocalDNSDelete, Target: record.Domain, Err: fmt.Errorf("failed to delete stale record: %w", err)})
+			continue
+		}
+		deleted = append(deleted, record)
+	}
+
+	return deleted, errors.Join(errs...)
+}
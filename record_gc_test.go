@@ -0,0 +1,54 @@
+package pihole
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastSeenStamps(t *testing.T) {
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	record := StampLastSeen(DNSRecord{Domain: "vm1.lan", IP: "10.0.0.5", Comment: "managed-by=myapp"}, seen)
+	assert.Equal(t, "managed-by=myapp last-seen=2026-01-02T03:04:05Z", record.Comment)
+
+	got, ok := LastSeen(record)
+	require.True(t, ok)
+	assert.True(t, seen.Equal(got))
+
+	_, ok = LastSeen(DNSRecord{Comment: "last-seen=yesterday"})
+	assert.False(t, ok)
+}
+
+func TestOwnedDNS_TouchAndGC(t *testing.T) {
+	isUnit(t)
+
+	stale := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	hosts := []string{
+		"10.0.0.5 vm1.lan # managed-by=myapp last-seen=" + stale,
+		"10.0.0.6 vm2.lan # managed-by=myapp last-seen=" + stale,
+		"10.0.0.7 pinned.lan # managed-by=myapp",
+		"10.0.0.8 other.lan # managed-by=other last-seen=" + stale,
+	}
+	client := newHostsTestClient(t, &hosts)
+	owned := client.Owned("myapp")
+	ctx := context.Background()
+
+	_, err := owned.Touch(ctx, &DNSRecord{Domain: "vm1.lan", IP: "10.0.0.5"})
+	require.NoError(t, err)
+
+	deleted, err := owned.GC(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "vm2.lan", deleted[0].Domain)
+
+	require.Len(t, hosts, 3)
+	assert.True(t, strings.HasPrefix(hosts[2], "10.0.0.5 vm1.lan # managed-by=myapp last-seen="))
+	assert.NotContains(t, hosts[2], stale)
+	assert.Contains(t, hosts, "10.0.0.7 pinned.lan # managed-by=myapp")
+	assert.Contains(t, hosts, "10.0.0.8 other.lan # managed-by=other last-seen="+stale)
+}
@@ -0,0 +1,41 @@
+package pihole
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Record IDs are derived from the normalized identity of a record, so they are the
+// same across reads, instances and client versions. Metadata such as TTLs, comments
+// and aliases is not part of the identity, so editing it keeps the ID.
+
+const (
+	hostRecordIDPrefix  = "host-"
+	cnameRecordIDPrefix = "cname-"
+)
+
+// ID returns a stable identifier derived from the record's domain and IP address.
+func (r DNSRecord) ID() string {
+	return recordID(hostRecordIDPrefix, normalizeDomain(r.Domain), normalizeIP(r.IP))
+}
+
+// ID returns a stable identifier derived from the record's domain, which is unique
+// among CNAME records.
+func (r CNAMERecord) ID() string {
+	return recordID(cnameRecordIDPrefix, normalizeDomain(r.Domain))
+}
+
+// ID returns the ID of the host or CNAME record the local record was converted from.
+func (r LocalRecord) ID() string {
+	if r.Kind == LocalRecordCNAME {
+		return CNAMERecord{Domain: r.Name, Target: r.Value}.ID()
+	}
+
+	return DNSRecord{Domain: r.Name, IP: r.Value}.ID()
+}
+
+func recordID(prefix string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return prefix + hex.EncodeToString(sum[:8])
+}
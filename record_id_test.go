@@ -0,0 +1,60 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordIDs(t *testing.T) {
+	a := DNSRecord{IP: "FD00:0::1", Domain: "NAS.lan."}
+	b := DNSRecord{IP: "fd00::1", Domain: "nas.lan", Comment: "rack 2", Aliases: []string{"storage.lan"}}
+	c := DNSRecord{IP: "fd00::2", Domain: "nas.lan"}
+
+	assert.Equal(t, a.ID(), b.ID())
+	assert.NotEqual(t, a.ID(), c.ID())
+	assert.True(t, strings.HasPrefix(a.ID(), "host-"))
+	assert.Equal(t, a.ID(), a.LocalRecord().ID())
+
+	cname := CNAMERecord{Domain: "www.lan", Target: "nas.lan"}
+	assert.Equal(t, cname.ID(), CNAMERecord{Domain: "WWW.lan", Target: "web.lan", TTL: 60, HasTTL: true}.ID())
+	assert.True(t, strings.HasPrefix(cname.ID(), "cname-"))
+	assert.Equal(t, cname.ID(), cname.LocalRecord().ID())
+}
+
+func TestLocalDNS_DeleteByIDRemovesOnlyThatRecord(t *testing.T) {
+	isUnit(t)
+
+	var deleted []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["10.0.0.1 nas.lan","fd00::1 nas.lan"]}}}`), nil
+		case req.Method == http.MethodDelete:
+			deleted = append(deleted, req.URL.Path)
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	id := DNSRecord{IP: "fd00::1", Domain: "nas.lan"}.ID()
+
+	record, err := client.LocalDNS.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::1", record.IP)
+
+	require.NoError(t, client.LocalDNS.DeleteByID(context.Background(), id))
+	assert.Equal(t, []string{"/api/config/dns/hosts/fd00::1 nas.lan"}, deleted)
+
+	require.NoError(t, client.LocalDNS.DeleteByID(context.Background(), "host-unknown"))
+	_, err = client.LocalDNS.GetByID(context.Background(), "host-unknown")
+	assert.ErrorIs(t, err, ErrorLocalDNSNotFound)
+}
@@ -0,0 +1,83 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ListRaw returns the host lines as stored, without parsing them
+func (dns localDNS) ListRaw(ctx context.Context) ([]string, error) {
+	lines := make([]string, 0)
+	err := dns.client.streamConfigArray(ctx, "/api/config/dns/hosts", "hosts", newDNSAPIError, func(entry string) error {
+		lines = append(lines, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch custom DNS records: %w", err)
+	}
+
+	return lines, nil
+}
+
+// Parse parses a host line with the client's record codec
+func (dns localDNS) Parse(raw string) (DNSRecord, error) {
+	return decodeHost(dns.client.recordCodec(), raw)
+}
+
+// ListRaw returns the CNAME entries as stored, without parsing them
+func (cname localCNAME) ListRaw(ctx context.Context) ([]string, error) {
+	entries := make([]string, 0)
+	err := cname.client.streamConfigArray(ctx, "/api/config/dns/cnameRecords", "cnameRecords", newCNAMEAPIError, func(entry string) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch custom CNAME records: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Parse parses a CNAME entry with the client's record codec
+func (cname localCNAME) Parse(raw string) (CNAMERecord, error) {
+	return decodeCNAME(cname.client.recordCodec(), raw)
+}
+
+// mayName reports whether a stored entry can hold a record named name, so that
+// lookups parse only the entries that might match. The entries of custom codecs are
+// opaque and always may.
+func (c *Client) mayName(entry string, name string) bool {
+	if c.codec != nil && c.codec != DefaultRecordCodec {
+		return true
+	}
+
+	return containsFold(entry, normalizeDomain(name))
+}
+
+// containsFold reports whether substr is within s, ignoring ASCII case.
+func containsFold(s string, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validHostLine reports whether parseDNSRecord accepts a hosts line, without
+// building the record.
+func validHostLine(line string) bool {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+
+	_, rest, ok := nextField(line)
+	if !ok {
+		return false
+	}
+	_, _, ok = nextField(rest)
+
+	return ok
+}
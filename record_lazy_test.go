@@ -0,0 +1,77 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalDNS_ListRaw(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.2 printer.lan", "garbage", "10.0.0.1 NAS.lan Storage.lan # rack 2"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &hosts, &ops)
+
+	lines, err := client.LocalDNS.ListRaw(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, hosts, lines)
+
+	record, err := client.LocalDNS.Parse(lines[2])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Storage.lan"}, record.Aliases)
+	assert.Equal(t, lines[2], record.Raw())
+
+	_, err = client.LocalDNS.Parse(lines[1])
+	assert.Error(t, err)
+
+	// Get still rejects the malformed line unless parsing leniently.
+	_, err = client.LocalDNS.Get(context.Background(), "storage.lan.")
+	assert.Error(t, err)
+
+	lenient, err := New(Config{
+		BaseURL:        "http://pi.test",
+		SessionID:      "test",
+		LenientParsing: true,
+		HttpClient:     &http.Client{Transport: configArrayTransport(t, "/api/config/dns/hosts", "hosts", &hosts, &ops)},
+	})
+	require.NoError(t, err)
+
+	found, err := lenient.LocalDNS.Get(context.Background(), "storage.lan.")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", found.IP)
+}
+
+func TestLocalCNAME_ListRaw(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"www.lan,nas.lan", "media.lan,nas.lan,300", "WWW.lan,old.lan"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+
+	raw, err := client.LocalCNAME.ListRaw(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, entries, raw)
+
+	record, err := client.LocalCNAME.Parse(raw[1])
+	require.NoError(t, err)
+	assert.Equal(t, 300, record.TTL)
+
+	found, err := client.LocalCNAME.Get(context.Background(), "media.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan", found.Target)
+
+	var ambiguous *AmbiguousRecordError
+	_, err = client.LocalCNAME.Get(context.Background(), "www.lan")
+	require.ErrorAs(t, err, &ambiguous)
+}
+
+func TestContainsFold(t *testing.T) {
+	assert.True(t, containsFold("10.0.0.1 NAS.lan", "nas.lan"))
+	assert.True(t, containsFold("anything", ""))
+	assert.False(t, containsFold("10.0.0.1 nas.lan", "printer.lan"))
+	assert.False(t, containsFold("nas", "nas.lan"))
+}
@@ -0,0 +1,170 @@
+package pihole
+
+import "encoding/json"
+
+// The wire types below mirror the record types with TTL and HasTTL collapsed into
+// an optional ttl field, which is what manifests and API responses expect. The
+// json tags are needed because encoding/json keeps the Go field names, while yaml.v3
+// already lowercases them, so yaml tags are only given for omitempty. The record
+// types themselves are always marshalled through these and carry no tags.
+
+type dnsRecordWire struct {
+	IP      string   `json:"ip"`
+	Domain  string   `json:"domain"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	TTL     *int     `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Comment string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+type cnameRecordWire struct {
+	Domain string `json:"domain"`
+	Target string `json:"target"`
+	TTL    *int   `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+type localRecordWire struct {
+	Kind    LocalRecordKind `json:"kind"`
+	Name    string          `json:"name"`
+	Value   string          `json:"value"`
+	TTL     *int            `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Comment string          `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+func optionalTTL(ttl int, hasTTL bool) *int {
+	if !hasTTL {
+		return nil
+	}
+
+	return &ttl
+}
+
+func fromOptionalTTL(ttl *int) (int, bool) {
+	if ttl == nil {
+		return 0, false
+	}
+
+	return *ttl, true
+}
+
+func (r DNSRecord) wire() dnsRecordWire {
+	return dnsRecordWire{IP: r.IP, Domain: r.Domain, Aliases: r.Aliases, TTL: optionalTTL(r.TTL, r.HasTTL), Comment: r.Comment}
+}
+
+func (w dnsRecordWire) record() DNSRecord {
+	record := DNSRecord{IP: w.IP, Domain: w.Domain, Aliases: w.Aliases, Comment: w.Comment}
+	record.TTL, record.HasTTL = fromOptionalTTL(w.TTL)
+
+	return record
+}
+
+func (r DNSRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.wire())
+}
+
+func (r *DNSRecord) UnmarshalJSON(b []byte) error {
+	var w dnsRecordWire
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
+
+func (r DNSRecord) MarshalYAML() (interface{}, error) {
+	return r.wire(), nil
+}
+
+func (r *DNSRecord) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w dnsRecordWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
+
+func (r CNAMERecord) wire() cnameRecordWire {
+	return cnameRecordWire{Domain: r.Domain, Target: r.Target, TTL: optionalTTL(r.TTL, r.HasTTL)}
+}
+
+func (w cnameRecordWire) record() CNAMERecord {
+	record := CNAMERecord{Domain: w.Domain, Target: w.Target}
+	record.TTL, record.HasTTL = fromOptionalTTL(w.TTL)
+
+	return record
+}
+
+func (r CNAMERecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.wire())
+}
+
+func (r *CNAMERecord) UnmarshalJSON(b []byte) error {
+	var w cnameRecordWire
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
+
+func (r CNAMERecord) MarshalYAML() (interface{}, error) {
+	return r.wire(), nil
+}
+
+func (r *CNAMERecord) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w cnameRecordWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
+
+func (r LocalRecord) wire() localRecordWire {
+	return localRecordWire{Kind: r.Kind, Name: r.Name, Value: r.Value, TTL: optionalTTL(r.TTL, r.HasTTL), Comment: r.Comment}
+}
+
+func (w localRecordWire) record() LocalRecord {
+	record := LocalRecord{Kind: w.Kind, Name: w.Name, Value: w.Value, Comment: w.Comment}
+	record.TTL, record.HasTTL = fromOptionalTTL(w.TTL)
+
+	return record
+}
+
+func (r LocalRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.wire())
+}
+
+func (r *LocalRecord) UnmarshalJSON(b []byte) error {
+	var w localRecordWire
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
+
+func (r LocalRecord) MarshalYAML() (interface{}, error) {
+	return r.wire(), nil
+}
+
+func (r *LocalRecord) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w localRecordWire
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+
+	*r = w.record()
+
+	return nil
+}
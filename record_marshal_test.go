@@ -0,0 +1,59 @@
+package pihole
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDNSRecordMarshalling(t *testing.T) {
+	record := DNSRecord{IP: "10.0.0.1", Domain: "nas.lan", Aliases: []string{"storage.lan"}, TTL: 300, HasTTL: true, Comment: "rack 2"}
+
+	b, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"10.0.0.1","domain":"nas.lan","aliases":["storage.lan"],"ttl":300,"comment":"rack 2"}`, string(b))
+
+	var decoded DNSRecord
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, record, decoded)
+
+	b, err = json.Marshal(DNSRecord{IP: "10.0.0.1", Domain: "nas.lan"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"10.0.0.1","domain":"nas.lan"}`, string(b))
+
+	y, err := yaml.Marshal(record)
+	require.NoError(t, err)
+	assert.YAMLEq(t, "ip: 10.0.0.1\ndomain: nas.lan\naliases: [storage.lan]\nttl: 300\ncomment: rack 2\n", string(y))
+
+	var fromYAML DNSRecord
+	require.NoError(t, yaml.Unmarshal(y, &fromYAML))
+	assert.Equal(t, record, fromYAML)
+}
+
+func TestCNAMERecordMarshalling(t *testing.T) {
+	var records []CNAMERecord
+	require.NoError(t, yaml.Unmarshal([]byte("- domain: www.lan\n  target: nas.lan\n- domain: api.lan\n  target: nas.lan\n  ttl: 0\n"), &records))
+	require.Len(t, records, 2)
+	assert.False(t, records[0].HasTTL)
+	assert.True(t, records[1].HasTTL)
+	assert.Equal(t, 0, records[1].TTL)
+
+	b, err := json.Marshal(records)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"domain":"www.lan","target":"nas.lan"},{"domain":"api.lan","target":"nas.lan","ttl":0}]`, string(b))
+}
+
+func TestLocalRecordMarshalling(t *testing.T) {
+	record := LocalRecord{Kind: LocalRecordCNAME, Name: "www.lan", Value: "nas.lan", TTL: 60, HasTTL: true}
+
+	b, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"CNAME","name":"www.lan","value":"nas.lan","ttl":60}`, string(b))
+
+	var decoded LocalRecord
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, record, decoded)
+}
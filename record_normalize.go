@@ -0,0 +1,75 @@
+package pihole
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Normalize returns a copy of the record with lowercase domain and aliases without
+// trailing dots, the IP address in canonical form and a trimmed comment.
+func (r DNSRecord) Normalize() DNSRecord {
+	r.Domain = normalizeDomain(r.Domain)
+	if r.Aliases != nil {
+		aliases := make([]string, 0, len(r.Aliases))
+		for _, alias := range r.Aliases {
+			aliases = append(aliases, normalizeDomain(alias))
+		}
+		r.Aliases = aliases
+	}
+	r.IP = normalizeIP(r.IP)
+	r.Comment = strings.TrimSpace(r.Comment)
+
+	return r
+}
+
+// Equal reports whether both records describe the same host entry once normalized.
+func (r DNSRecord) Equal(other DNSRecord) bool {
+	a, b := r.Normalize(), other.Normalize()
+
+	return a.Domain == b.Domain &&
+		slices.Equal(a.Aliases, b.Aliases) &&
+		a.IP == b.IP &&
+		a.HasTTL == b.HasTTL &&
+		a.TTL == b.TTL &&
+		a.Comment == b.Comment
+}
+
+// Normalize returns a copy of the record with lowercase domain and target without
+// trailing dots.
+func (r CNAMERecord) Normalize() CNAMERecord {
+	r.Domain = normalizeDomain(r.Domain)
+	r.Target = normalizeDomain(r.Target)
+
+	return r
+}
+
+// Equal reports whether both records describe the same CNAME entry once normalized.
+func (r CNAMERecord) Equal(other CNAMERecord) bool {
+	a, b := r.Normalize(), other.Normalize()
+
+	return a.Domain == b.Domain &&
+		a.Target == b.Target &&
+		a.HasTTL == b.HasTTL &&
+		a.TTL == b.TTL
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// normalizeIP returns the canonical form of an address, e.g. "fd00::1" for
+// "FD00:0:0::1", or the trimmed input if it does not parse.
+func normalizeIP(ip string) string {
+	ip = strings.TrimSpace(ip)
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+
+	return addr.String()
+}
+
+func sameDomain(a string, b string) bool {
+	return normalizeDomain(a) == normalizeDomain(b)
+}
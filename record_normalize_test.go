@@ -0,0 +1,28 @@
+package pihole
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSRecord_NormalizeAndEqual(t *testing.T) {
+	record := DNSRecord{IP: " FD00:0:0::1 ", Domain: "NAS.Lan.", Comment: " storage "}
+
+	normalized := record.Normalize()
+	assert.Equal(t, "fd00::1", normalized.IP)
+	assert.Equal(t, "nas.lan", normalized.Domain)
+	assert.Equal(t, "storage", normalized.Comment)
+
+	assert.True(t, record.Equal(DNSRecord{IP: "fd00::1", Domain: "nas.lan", Comment: "storage"}))
+	assert.False(t, record.Equal(DNSRecord{IP: "fd00::2", Domain: "nas.lan", Comment: "storage"}))
+	assert.False(t, record.Equal(DNSRecord{IP: "fd00::1", Domain: "nas.lan", Comment: "storage", TTL: 60, HasTTL: true}))
+}
+
+func TestCNAMERecord_NormalizeAndEqual(t *testing.T) {
+	record := CNAMERecord{Domain: "WWW.lan", Target: "Nas.lan."}
+
+	assert.Equal(t, CNAMERecord{Domain: "www.lan", Target: "nas.lan"}, record.Normalize())
+	assert.True(t, record.Equal(CNAMERecord{Domain: "www.lan", Target: "nas.lan"}))
+	assert.False(t, record.Equal(CNAMERecord{Domain: "www.lan", Target: "web.lan"}))
+}
@@ -0,0 +1,180 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrStopPaging can be returned by a ListPages callback to stop early. ListPages
+// then returns nil.
+var ErrStopPaging = errors.New("stop paging")
+
+// ListPages parses the host lines incrementally and calls fn with pages of at most
+// pageSize records in config order
+func (dns localDNS) ListPages(ctx context.Context, pageSize int, fn func(DNSRecordList) error) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	page := make(DNSRecordList, 0, pageSize)
+	err := dns.each(ctx, nil, func(record DNSRecord) error {
+		page = append(page, record)
+		if len(page) < pageSize {
+			return nil
+		}
+
+		err := fn(page)
+		page = make(DNSRecordList, 0, pageSize)
+		return err
+	})
+	if err == nil && len(page) > 0 {
+		err = fn(page)
+	}
+	if errors.Is(err, ErrStopPaging) {
+		return nil
+	}
+
+	return err
+}
+
+// each streams the host lines, calling fn for every record that parses. Invalid
+// lines fail the call unless the client parses leniently. Lines for which skip
+// returns true are not parsed.
+func (dns localDNS) each(ctx context.Context, skip func(string) bool, fn func(DNSRecord) error) error {
+	return dns.client.streamConfigArray(ctx, "/api/config/dns/hosts", "hosts", newDNSAPIError, func(entry string) error {
+		if skip != nil && skip(entry) {
+			return nil
+		}
+
+		record, err := decodeHost(dns.client.recordCodec(), entry)
+		if err != nil {
+			if dns.client.lenientParsing {
+				return nil
+			}
+			return fmt.Errorf("failed to parse customDNS list body: %w", err)
+		}
+
+		return fn(record)
+	})
+}
+
+// ListPages parses the CNAME entries incrementally and calls fn with pages of at
+// most pageSize records in config order
+func (cname localCNAME) ListPages(ctx context.Context, pageSize int, fn func(CNAMERecordList) error) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	page := make(CNAMERecordList, 0, pageSize)
+	err := cname.client.streamConfigArray(ctx, "/api/config/dns/cnameRecords", "cnameRecords", newCNAMEAPIError, func(entry string) error {
+		record, err := decodeCNAME(cname.client.recordCodec(), entry)
+		if err != nil {
+			if cname.client.lenientParsing {
+				return nil
+			}
+			return fmt.Errorf("failed to parse custom CNAME list body: %w", err)
+		}
+
+		page = append(page, record)
+		if len(page) < pageSize {
+			return nil
+		}
+
+		err = fn(page)
+		page = make(CNAMERecordList, 0, pageSize)
+		return err
+	})
+	if err == nil && len(page) > 0 {
+		err = fn(page)
+	}
+	if errors.Is(err, ErrStopPaging) {
+		return nil
+	}
+
+	return err
+}
+
+// streamConfigArray decodes the string array at config.dns.<key> of a config
+// response one entry at a time. A response without the array has no entries. The
+// GET is not coalesced with concurrent ones, which would buffer the whole array.
+func (c *Client) streamConfigArray(ctx context.Context, path string, key string, apiError func(*http.Response, []byte) error, fn func(string) error) error {
+	res, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return apiError(res, b)
+	}
+
+	dec := json.NewDecoder(res.Body)
+	for _, field := range []string{"config", "dns", key} {
+		found, err := seekField(dec, field)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s body: %w", path, err)
+		}
+		if !found {
+			return nil
+		}
+	}
+
+	if tok, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to parse %s body: %w", path, err)
+	} else if tok == nil {
+		return nil
+	} else if tok != json.Delim('[') {
+		return fmt.Errorf("failed to parse %s body: %s is not an array", path, key)
+	}
+
+	for dec.More() {
+		var entry string
+		if err := dec.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to parse %s body: %w", path, err)
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seekField enters the next JSON object and advances to the value of field, skipping
+// the values before it. It reports false if the object has no such field or the
+// value is null instead of an object.
+func seekField(dec *json.Decoder, field string) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if tok != json.Delim('{') {
+		return false, fmt.Errorf("expected an object before %q", field)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if tok == field {
+			return true, nil
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStaticConfigClient(t *testing.T, lenient bool, body string) *Client {
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newHTTPResponse(http.StatusOK, body), nil
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient, LenientParsing: lenient})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestLocalDNS_ListPages(t *testing.T) {
+	isUnit(t)
+
+	hosts := make([]string, 0, 25)
+	for i := 0; i < 25; i++ {
+		hosts = append(hosts, fmt.Sprintf(`"10.0.0.%d host%d.lan"`, i, i))
+	}
+	body := `{"took":0.1,"config":{"misc":{"x":[1,{"y":null}]},"dns":{"upstreams":["1.1.1.1"],"hosts":[` + strings.Join(hosts, ",") + `]}}}`
+	client := newStaticConfigClient(t, false, body)
+	ctx := context.Background()
+
+	var sizes []int
+	require.NoError(t, client.LocalDNS.ListPages(ctx, 10, func(page DNSRecordList) error {
+		sizes = append(sizes, len(page))
+		return nil
+	}))
+	assert.Equal(t, []int{10, 10, 5}, sizes)
+
+	var seen []string
+	require.NoError(t, client.LocalDNS.ListPages(ctx, 3, func(page DNSRecordList) error {
+		for _, record := range page {
+			seen = append(seen, record.Domain)
+		}
+		return ErrStopPaging
+	}))
+	assert.Equal(t, []string{"host0.lan", "host1.lan", "host2.lan"}, seen)
+
+	failure := errors.New("boom")
+	assert.ErrorIs(t, client.LocalDNS.ListPages(ctx, 3, func(DNSRecordList) error { return failure }), failure)
+
+	assert.Error(t, client.LocalDNS.ListPages(ctx, 0, func(DNSRecordList) error { return nil }))
+
+	record, err := client.LocalDNS.Get(ctx, "host24.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.24", record.IP)
+}
+
+func TestLocalDNS_GetStreamingMatchesSortedOrder(t *testing.T) {
+	isUnit(t)
+
+	client := newStaticConfigClient(t, false, `{"config":{"dns":{"hosts":["fd00::1 nas.lan","10.0.0.9 nas.lan","10.0.0.5 nas.lan"]}}}`)
+
+	record, err := client.LocalDNS.Get(context.Background(), "nas.lan")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", record.IP)
+}
+
+func TestLocalCNAME_ListPagesLenient(t *testing.T) {
+	isUnit(t)
+
+	body := `{"config":{"dns":{"cnameRecords":["a.lan,b.lan","broken","c.lan,d.lan,60"]}}}`
+	ctx := context.Background()
+
+	var records CNAMERecordList
+	collect := func(page CNAMERecordList) error {
+		records = append(records, page...)
+		return nil
+	}
+
+	assert.Error(t, newStaticConfigClient(t, false, body).LocalCNAME.ListPages(ctx, 10, collect))
+
+	records = nil
+	require.NoError(t, newStaticConfigClient(t, true, body).LocalCNAME.ListPages(ctx, 10, collect))
+	require.Len(t, records, 2)
+	assert.Equal(t, 60, records[1].TTL)
+
+	records = nil
+	require.NoError(t, newStaticConfigClient(t, false, `{"config":{"dns":{"cnameRecords":null}}}`).LocalCNAME.ListPages(ctx, 10, collect))
+	assert.Empty(t, records)
+}
@@ -0,0 +1,65 @@
+package pihole
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// NewDNSRecordFromRaw parses a line of dns.hosts. The record keeps the line, so until
+// its fields are changed Raw returns it unchanged and Delete removes exactly that line.
+func NewDNSRecordFromRaw(raw string) (DNSRecord, error) {
+	return parseDNSRecord(raw)
+}
+
+// NewCNAMERecordFromRaw parses an entry of dns.cnameRecords. The record keeps the
+// entry, so until its fields are changed Raw returns it unchanged and Delete removes
+// exactly that entry.
+func NewCNAMERecordFromRaw(raw string) (CNAMERecord, error) {
+	return parseCNAMERecord(raw)
+}
+
+// NewRecordFromRaw parses either a hosts line ("10.0.0.2 nas.lan") or a CNAME tuple
+// ("www.lan,nas.lan"), telling them apart by whether the entry starts with an IP
+// address. Aliases of a hosts line are dropped; use NewDNSRecordFromRaw to keep
+// them.
+func NewRecordFromRaw(raw string) (LocalRecord, error) {
+	first := strings.TrimSpace(raw)
+	if i := strings.IndexAny(first, " \t,#"); i >= 0 {
+		first = first[:i]
+	}
+
+	if _, err := netip.ParseAddr(first); err == nil {
+		record, err := parseDNSRecord(raw)
+		if err != nil {
+			return LocalRecord{}, err
+		}
+		return record.LocalRecord(), nil
+	}
+
+	if strings.Contains(raw, ",") {
+		record, err := parseCNAMERecord(raw)
+		if err != nil {
+			return LocalRecord{}, err
+		}
+		return record.LocalRecord(), nil
+	}
+
+	return LocalRecord{}, fmt.Errorf("invalid local record: %q is neither a hosts line nor a CNAME tuple", raw)
+}
+
+// Raw returns the record in the form Pi-hole stores it: a hosts line for A and AAAA
+// records and a CNAME tuple for CNAME records.
+func (r LocalRecord) Raw() string {
+	if r.Kind == LocalRecordCNAME {
+		cname, _ := r.CNAMERecord()
+		return cname.Raw()
+	}
+
+	dns, err := r.DNSRecord()
+	if err != nil {
+		return ""
+	}
+
+	return dns.Raw()
+}
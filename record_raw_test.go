@@ -0,0 +1,92 @@
+package pihole
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRaw(t *testing.T) {
+	dns, err := NewDNSRecordFromRaw("  10.0.0.1 nas.lan storage.lan # rack 2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"storage.lan"}, dns.Aliases)
+	assert.Equal(t, "  10.0.0.1 nas.lan storage.lan # rack 2", dns.Raw())
+	assert.Equal(t, "10.0.0.2 printer.lan 300", DNSRecord{IP: "10.0.0.2", Domain: "printer.lan", TTL: 300, HasTTL: true}.Raw())
+
+	cname, err := NewCNAMERecordFromRaw("www.lan, nas.lan,60")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan", cname.Target)
+	assert.Equal(t, "www.lan, nas.lan,60", cname.Raw())
+	assert.Equal(t, "www.lan,nas.lan", CNAMERecord{Domain: "www.lan", Target: "nas.lan"}.Raw())
+
+	for raw, want := range map[string]LocalRecord{
+		"10.0.0.1 nas.lan":          {Kind: LocalRecordA, Name: "nas.lan", Value: "10.0.0.1"},
+		"fd00::1\tnas.lan 60":       {Kind: LocalRecordAAAA, Name: "nas.lan", Value: "fd00::1", TTL: 60, HasTTL: true},
+		"www.lan,nas.lan":           {Kind: LocalRecordCNAME, Name: "www.lan", Value: "nas.lan"},
+		"10.0.0.1 nas.lan # closet": {Kind: LocalRecordA, Name: "nas.lan", Value: "10.0.0.1", Comment: "closet"},
+	} {
+		record, err := NewRecordFromRaw(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, record, raw)
+	}
+
+	record, err := NewRecordFromRaw("www.lan,nas.lan,60")
+	require.NoError(t, err)
+	assert.Equal(t, "www.lan,nas.lan,60", record.Raw())
+
+	_, err = NewRecordFromRaw("nas.lan")
+	assert.Error(t, err)
+	_, err = NewRecordFromRaw("www.lan,nas.lan,soon")
+	assert.Error(t, err)
+}
+
+func TestRecordRawAfterEdit(t *testing.T) {
+	dns, err := NewDNSRecordFromRaw("10.0.0.1 nas.lan storage.lan # rack 2")
+	require.NoError(t, err)
+	dns.IP = "10.0.0.9"
+	assert.Equal(t, "10.0.0.9 nas.lan storage.lan # rack 2", dns.Raw())
+
+	dns.IP = "10.0.0.1"
+	assert.Equal(t, "10.0.0.1 nas.lan storage.lan # rack 2", dns.Raw())
+	dns.Comment = "rack 3"
+	assert.Equal(t, "10.0.0.1 nas.lan storage.lan # rack 3", dns.Raw())
+
+	cname, err := NewCNAMERecordFromRaw("www.lan, nas.lan,60")
+	require.NoError(t, err)
+	cname.Target = "web.lan"
+	assert.Equal(t, "www.lan,web.lan,60", cname.Raw())
+}
+
+func TestLocalDNS_CreateRecordWritesEditedFields(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{"10.0.0.1 nas.lan # rack 2"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &hosts, &ops)
+
+	record, err := client.LocalDNS.Get(context.Background(), "nas.lan")
+	require.NoError(t, err)
+
+	record.Domain = "backup.lan"
+	_, err = client.LocalDNS.CreateRecord(context.Background(), record)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PUT 10.0.0.1 backup.lan # rack 2"}, ops)
+}
+
+func TestLocalDNS_DeleteWritesBackPaddedLine(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{" 10.0.0.1  nas.lan # rack 2 ", "10.0.0.2 web.lan"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &hosts, &ops)
+
+	record, err := client.LocalDNS.Get(context.Background(), "nas.lan")
+	require.NoError(t, err)
+	assert.Equal(t, " 10.0.0.1  nas.lan # rack 2 ", record.Raw())
+
+	require.NoError(t, client.LocalDNS.Delete(context.Background(), "nas.lan"))
+	assert.Equal(t, []string{"DELETE  10.0.0.1  nas.lan # rack 2 "}, ops)
+	assert.Equal(t, []string{"10.0.0.2 web.lan"}, hosts)
+}
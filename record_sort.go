@@ -0,0 +1,66 @@
+package pihole
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Sort orders the records by domain, then by IP address, in place.
+func (l DNSRecordList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		return lessDNSRecord(l[i], l[j])
+	})
+}
+
+func lessDNSRecord(a DNSRecord, b DNSRecord) bool {
+	if c := compareDomains(a.Domain, b.Domain); c != 0 {
+		return c < 0
+	}
+
+	return compareIPs(a.IP, b.IP) < 0
+}
+
+// Sort orders the records by domain, then by target, in place.
+func (l CNAMERecordList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if c := compareDomains(l[i].Domain, l[j].Domain); c != 0 {
+			return c < 0
+		}
+
+		return compareDomains(l[i].Target, l[j].Target) < 0
+	})
+}
+
+// Sort orders the records by name, then kind (A, AAAA, CNAME), then value, in place.
+func (l LocalRecordList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if c := compareDomains(l[i].Name, l[j].Name); c != 0 {
+			return c < 0
+		}
+		if l[i].Kind != l[j].Kind {
+			return l[i].Kind < l[j].Kind
+		}
+		if l[i].Kind == LocalRecordCNAME {
+			return compareDomains(l[i].Value, l[j].Value) < 0
+		}
+
+		return compareIPs(l[i].Value, l[j].Value) < 0
+	})
+}
+
+func compareDomains(a string, b string) int {
+	return strings.Compare(normalizeDomain(a), normalizeDomain(b))
+}
+
+// compareIPs orders valid addresses numerically, IPv4 before IPv6, and falls back
+// to string order for anything that does not parse.
+func compareIPs(a string, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA == nil && errB == nil {
+		return addrA.Compare(addrB)
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,50 @@
+package pihole
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSRecordList_Sort(t *testing.T) {
+	list := DNSRecordList{
+		{IP: "fd00::1", Domain: "nas.lan"},
+		{IP: "10.0.0.10", Domain: "nas.lan"},
+		{IP: "10.0.0.9", Domain: "NAS.lan"},
+		{IP: "10.0.0.1", Domain: "camera.lan"},
+	}
+	list.Sort()
+
+	got := make([]string, 0, len(list))
+	for _, record := range list {
+		got = append(got, record.Domain+" "+record.IP)
+	}
+	assert.Equal(t, []string{"camera.lan 10.0.0.1", "NAS.lan 10.0.0.9", "nas.lan 10.0.0.10", "nas.lan fd00::1"}, got)
+}
+
+func TestCNAMERecordList_Sort(t *testing.T) {
+	list := CNAMERecordList{
+		{Domain: "www.lan", Target: "web.lan"},
+		{Domain: "api.lan", Target: "web.lan"},
+	}
+	list.Sort()
+
+	assert.Equal(t, "api.lan", list[0].Domain)
+	assert.Equal(t, "www.lan", list[1].Domain)
+}
+
+func TestLocalRecordList_Sort(t *testing.T) {
+	list := LocalRecordList{
+		{Kind: LocalRecordCNAME, Name: "nas.lan", Value: "storage.lan"},
+		{Kind: LocalRecordAAAA, Name: "nas.lan", Value: "fd00::1"},
+		{Kind: LocalRecordA, Name: "nas.lan", Value: "10.0.0.2"},
+		{Kind: LocalRecordA, Name: "nas.lan", Value: "10.0.0.1"},
+	}
+	list.Sort()
+
+	got := make([]string, 0, len(list))
+	for _, record := range list {
+		got = append(got, record.Value)
+	}
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "fd00::1", "storage.lan"}, got)
+}
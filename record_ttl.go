@@ -0,0 +1,144 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The TTL range dnsmasq honours for local records. TTLs are 32-bit values of which
+// RFC 2181 allows only the lower 31 bits; dnsmasq ignores anything outside.
+const (
+	MinTTL = 0
+	MaxTTL = math.MaxInt32
+)
+
+var (
+	ErrInvalidTTL = errors.New("invalid TTL")
+)
+
+// InvalidTTLError is returned when a record's TTL is outside [Min, Max], which
+// Pi-hole would store but dnsmasq would ignore.
+type InvalidTTLError struct {
+	TTL int
+	Min int
+	Max int
+}
+
+func (e *InvalidTTLError) Error() string {
+	return fmt.Sprintf("%s %d: must be between %d and %d", ErrInvalidTTL, e.TTL, e.Min, e.Max)
+}
+
+func (e *InvalidTTLError) Is(target error) bool {
+	return target == ErrInvalidTTL
+}
+
+// validateTTL checks that ttl is in the range dnsmasq accepts.
+func validateTTL(ttl int) error {
+	if ttl < MinTTL || ttl > MaxTTL {
+		return &InvalidTTLError{TTL: ttl, Min: MinTTL, Max: MaxTTL}
+	}
+
+	return nil
+}
+
+// SetTTL rewrites the TTL of the record for domain, adding the new hosts line before
+// removing the old one
+func (dns localDNS) SetTTL(ctx context.Context, domain string, ttl int) (updated *DNSRecord, err error) {
+	if err := validateTTL(ttl); err != nil {
+		return nil, err
+	}
+
+	record, err := dns.Get(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if record.HasTTL && record.TTL == ttl {
+		return record, nil
+	}
+
+	next := *record
+	next.raw = ""
+	next.TTL, next.HasTTL = ttl, true
+
+	defer func() {
+		dns.client.afterMutation(ctx, Mutation{Operation: AuditLocalDNSUpdate, Target: record.Domain, Before: *record, After: auditValue(updated)}, err)
+	}()
+
+	if err := dns.client.beforeMutation(ctx, Mutation{Operation: AuditLocalDNSUpdate, Target: record.Domain, Before: *record, After: next}); err != nil {
+		return nil, err
+	}
+
+	if err := dns.put(ctx, &next); err != nil {
+		return nil, fmt.Errorf("failed to add DNS record %s with TTL %d: %w", record.Domain, ttl, err)
+	}
+
+	if err := dns.remove(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to remove DNS record %s with the previous TTL, both entries remain: %w", record.Domain, err)
+	}
+
+	return dns.Get(ctx, record.Domain)
+}
+
+// SetTTL rewrites the TTL of the CNAME record for domain. FTL rejects a second entry
+// for the same domain, so the old entry is removed first and restored if the new one
+// cannot be added
+func (cname localCNAME) SetTTL(ctx context.Context, domain string, ttl int) (updated *CNAMERecord, err error) {
+	if err := validateTTL(ttl); err != nil {
+		return nil, err
+	}
+
+	record, err := cname.Get(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if record.HasTTL && record.TTL == ttl {
+		return record, nil
+	}
+
+	next := *record
+	next.raw = ""
+	next.TTL, next.HasTTL = ttl, true
+
+	defer func() {
+		cname.client.afterMutation(ctx, Mutation{Operation: AuditLocalCNAMEUpdate, Target: record.Domain, Before: *record, After: auditValue(updated)}, err)
+	}()
+
+	if err := cname.client.beforeMutation(ctx, Mutation{Operation: AuditLocalCNAMEUpdate, Target: record.Domain, Before: *record, After: next}); err != nil {
+		return nil, err
+	}
+
+	if err := cname.replace(ctx, []CNAMERecord{*record}, &next); err != nil {
+		return nil, fmt.Errorf("failed to set the TTL of CNAME record %s to %d: %w", record.Domain, ttl, err)
+	}
+
+	return cname.Get(ctx, record.Domain)
+}
+
+// replace removes the entries in old and adds record. If record cannot be added, the
+// removed entries are put back.
+func (cname localCNAME) replace(ctx context.Context, old []CNAMERecord, record *CNAMERecord) error {
+	for i := range old {
+		if err := cname.remove(ctx, &old[i]); err != nil {
+			return errors.Join(err, cname.restore(ctx, old[:i]))
+		}
+	}
+
+	if err := cname.put(ctx, record); err != nil {
+		return errors.Join(err, cname.restore(ctx, old))
+	}
+
+	return nil
+}
+
+func (cname localCNAME) restore(ctx context.Context, records []CNAMERecord) error {
+	errs := make([]error, 0)
+	for i := range records {
+		if err := cname.put(ctx, &records[i]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore CNAME record %s: %w", records[i].Domain, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
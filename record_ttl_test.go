@@ -0,0 +1,168 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfigArrayClient returns a client served by configArrayTransport.
+func newConfigArrayClient(t *testing.T, path string, key string, entries *[]string, ops *[]string) *Client {
+	client, err := newTransportClient(configArrayTransport(t, path, key, entries, ops))
+	require.NoError(t, err)
+
+	return client
+}
+
+// configArrayTransport serves one config array, such as dns/hosts, from entries and
+// records each mutation as "PUT <entry>" or "DELETE <entry>" in ops.
+func configArrayTransport(t *testing.T, path string, key string, entries *[]string, ops *[]string) roundTripFunc {
+	var mu sync.Mutex
+
+	return func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if req.Method == http.MethodGet && req.URL.Path == path {
+			b, _ := json.Marshal(*entries)
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"`+key+`":`+string(b)+`}}}`), nil
+		}
+
+		entry, ok := strings.CutPrefix(req.URL.EscapedPath(), path+"/")
+		if !ok {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+		entry, err := url.PathUnescape(entry)
+		require.NoError(t, err)
+
+		*ops = append(*ops, req.Method+" "+entry)
+		switch req.Method {
+		case http.MethodPut:
+			*entries = append(*entries, entry)
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		case http.MethodDelete:
+			*entries = slices.DeleteFunc(*entries, func(e string) bool { return e == entry })
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	}
+}
+
+func TestLocalDNS_SetTTL(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"10.0.0.5 nas.lan storage.lan # backups"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/hosts", "hosts", &entries, &ops)
+
+	record, err := client.LocalDNS.SetTTL(context.Background(), "storage.lan", 300)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"PUT 10.0.0.5 nas.lan 300 storage.lan # backups",
+		"DELETE 10.0.0.5 nas.lan storage.lan # backups",
+	}, ops)
+	assert.Equal(t, "nas.lan", record.Domain)
+	assert.Equal(t, []string{"storage.lan"}, record.Aliases)
+	assert.Equal(t, "backups", record.Comment)
+	assert.True(t, record.HasTTL)
+	assert.Equal(t, 300, record.TTL)
+
+	ops = nil
+	_, err = client.LocalDNS.SetTTL(context.Background(), "nas.lan", 300)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func TestLocalCNAME_SetTTL(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"www.lan,web.lan,60"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+
+	record, err := client.LocalCNAME.SetTTL(context.Background(), "www.lan", 3600)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"DELETE www.lan,web.lan,60", "PUT www.lan,web.lan,3600"}, ops)
+	assert.Equal(t, "web.lan", record.Target)
+	assert.Equal(t, 3600, record.TTL)
+
+	_, err = client.LocalCNAME.SetTTL(context.Background(), "missing.lan", 60)
+	assert.ErrorIs(t, err, ErrorLocalCNAMENotFound)
+}
+
+func TestLocalCNAME_SetTTLWithoutDuplicateEntries(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"www.lan,web.lan,60"}
+	var ops []string
+	transport := configArrayTransport(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+
+	// Like FTL's dnsmasq config test, refuse a second entry for a domain, and any
+	// entry with a TTL of 7.
+	var rejected int
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		entry, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), "/api/config/dns/cnameRecords/"))
+		require.NoError(t, err)
+		domain, _, _ := strings.Cut(entry, ",")
+
+		if req.Method == http.MethodPut {
+			duplicate := slices.ContainsFunc(entries, func(e string) bool { return strings.HasPrefix(e, domain+",") })
+			if duplicate || strings.HasSuffix(entry, ",7") {
+				rejected++
+				return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"Invalid configuration","hint":null}}`), nil
+			}
+		}
+
+		return transport(req)
+	})
+	require.NoError(t, err)
+
+	record, err := client.LocalCNAME.SetTTL(context.Background(), "www.lan", 3600)
+	require.NoError(t, err)
+	assert.Equal(t, 3600, record.TTL)
+	assert.Equal(t, []string{"www.lan,web.lan,3600"}, entries)
+	assert.Zero(t, rejected)
+
+	_, err = client.LocalCNAME.SetTTL(context.Background(), "www.lan", 7)
+	var apiErr *CNAMEAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, []string{"www.lan,web.lan,3600"}, entries)
+}
+
+func TestValidateTTL(t *testing.T) {
+	isUnit(t)
+
+	entries := []string{"www.lan,web.lan,60"}
+	var ops []string
+	client := newConfigArrayClient(t, "/api/config/dns/cnameRecords", "cnameRecords", &entries, &ops)
+	ctx := context.Background()
+
+	_, err := client.LocalCNAME.SetTTL(ctx, "www.lan", -1)
+	require.ErrorIs(t, err, ErrInvalidTTL)
+
+	var ttlErr *InvalidTTLError
+	require.ErrorAs(t, err, &ttlErr)
+	assert.Equal(t, InvalidTTLError{TTL: -1, Min: MinTTL, Max: MaxTTL}, *ttlErr)
+
+	tooLong := int64(MaxTTL) + 1
+	_, err = client.LocalCNAME.CreateRecord(ctx, &CNAMERecord{Domain: "api.lan", Target: "web.lan", TTL: int(tooLong), HasTTL: true})
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+
+	_, err = client.LocalDNS.CreateRecord(ctx, &DNSRecord{IP: "10.0.0.5", Domain: "nas.lan", TTL: -60, HasTTL: true})
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+
+	assert.Empty(t, ops)
+	assert.NoError(t, validateTTL(0))
+	assert.NoError(t, validateTTL(MaxTTL))
+}
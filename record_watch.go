@@ -0,0 +1,223 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordEventType describes how a record changed between two polls.
+type RecordEventType string
+
+const (
+	RecordAdded   RecordEventType = "added"
+	RecordRemoved RecordEventType = "removed"
+	RecordChanged RecordEventType = "changed"
+
+	// RecordWatchError is emitted when a poll fails. The watcher keeps polling.
+	RecordWatchError RecordEventType = "error"
+)
+
+// RecordEvent reports a change to a local DNS record. Old is nil for added records
+// and New is nil for removed records. Err is only set for RecordWatchError events.
+type RecordEvent struct {
+	Type   RecordEventType
+	Domain string
+	Old    *DNSRecord
+	New    *DNSRecord
+	Err    error
+}
+
+// CNAMERecordEvent reports a change to a local CNAME record. Old is nil for added
+// records and New is nil for removed records. Err is only set for RecordWatchError
+// events.
+type CNAMERecordEvent struct {
+	Type   RecordEventType
+	Domain string
+	Old    *CNAMERecord
+	New    *CNAMERecord
+	Err    error
+}
+
+// Watch polls the DNS records every interval and emits an event for each record that
+// was added, removed or changed since the previous poll
+func (dns localDNS) Watch(ctx context.Context, interval time.Duration) (<-chan RecordEvent, error) {
+	return watch(ctx, interval, dns.List, diffDNSRecords, func(err error) RecordEvent {
+		return RecordEvent{Type: RecordWatchError, Err: err}
+	})
+}
+
+// Watch polls the CNAME records every interval and emits an event for each record
+// that was added, removed or changed since the previous poll
+func (cname localCNAME) Watch(ctx context.Context, interval time.Duration) (<-chan CNAMERecordEvent, error) {
+	return watch(ctx, interval, cname.List, diffCNAMERecords, func(err error) CNAMERecordEvent {
+		return CNAMERecordEvent{Type: RecordWatchError, Err: err}
+	})
+}
+
+// watch fetches an initial snapshot, failing if it cannot, then emits the
+// differences between consecutive snapshots until ctx is done. The returned channel
+// is closed when the watcher stops.
+func watch[L any, E any](
+	ctx context.Context,
+	interval time.Duration,
+	list func(context.Context) (L, error),
+	diff func(before L, after L) []E,
+	errEvent func(error) E,
+) (<-chan E, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval %s", interval)
+	}
+
+	current, err := list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch initial snapshot: %w", err)
+	}
+
+	events := make(chan E)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		send := func(event E) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			next, err := list(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !send(errEvent(err)) {
+					return
+				}
+				continue
+			}
+
+			for _, event := range diff(current, next) {
+				if !send(event) {
+					return
+				}
+			}
+			current = next
+		}
+	}()
+
+	return events, nil
+}
+
+// diffDNSRecords matches records by domain and IP. When a domain lost exactly one
+// record and gained exactly one, the pair is reported as a single change.
+func diffDNSRecords(before DNSRecordList, after DNSRecordList) []RecordEvent {
+	key := func(record DNSRecord) string {
+		return normalizeDomain(record.Domain) + " " + normalizeIP(record.IP)
+	}
+
+	oldByKey := make(map[string]DNSRecord, len(before))
+	for _, record := range before {
+		oldByKey[key(record)] = record
+	}
+	newByKey := make(map[string]DNSRecord, len(after))
+	for _, record := range after {
+		newByKey[key(record)] = record
+	}
+
+	events := make([]RecordEvent, 0)
+	removed := make(map[string][]DNSRecord)
+	added := make(map[string][]DNSRecord)
+	domains := make([]string, 0)
+
+	track := func(domain string) {
+		if _, ok := removed[domain]; ok {
+			return
+		}
+		if _, ok := added[domain]; ok {
+			return
+		}
+		domains = append(domains, domain)
+	}
+
+	for _, record := range before {
+		domain := normalizeDomain(record.Domain)
+		if next, ok := newByKey[key(record)]; ok {
+			if !record.Equal(next) {
+				events = append(events, RecordEvent{Type: RecordChanged, Domain: next.Domain, Old: &record, New: &next})
+			}
+			continue
+		}
+		track(domain)
+		removed[domain] = append(removed[domain], record)
+	}
+
+	for _, record := range after {
+		if _, ok := oldByKey[key(record)]; ok {
+			continue
+		}
+		domain := normalizeDomain(record.Domain)
+		track(domain)
+		added[domain] = append(added[domain], record)
+	}
+
+	for _, domain := range domains {
+		gone, came := removed[domain], added[domain]
+		if len(gone) == 1 && len(came) == 1 {
+			events = append(events, RecordEvent{Type: RecordChanged, Domain: came[0].Domain, Old: &gone[0], New: &came[0]})
+			continue
+		}
+
+		for i := range gone {
+			events = append(events, RecordEvent{Type: RecordRemoved, Domain: gone[i].Domain, Old: &gone[i]})
+		}
+		for i := range came {
+			events = append(events, RecordEvent{Type: RecordAdded, Domain: came[i].Domain, New: &came[i]})
+		}
+	}
+
+	return events
+}
+
+// diffCNAMERecords matches records by domain, which is unique among CNAME records.
+func diffCNAMERecords(before CNAMERecordList, after CNAMERecordList) []CNAMERecordEvent {
+	oldByDomain := make(map[string]CNAMERecord, len(before))
+	for _, record := range before {
+		oldByDomain[normalizeDomain(record.Domain)] = record
+	}
+	newByDomain := make(map[string]CNAMERecord, len(after))
+	for _, record := range after {
+		newByDomain[normalizeDomain(record.Domain)] = record
+	}
+
+	events := make([]CNAMERecordEvent, 0)
+	for _, record := range before {
+		next, ok := newByDomain[normalizeDomain(record.Domain)]
+		switch {
+		case !ok:
+			events = append(events, CNAMERecordEvent{Type: RecordRemoved, Domain: record.Domain, Old: &record})
+		case !record.Equal(next):
+			events = append(events, CNAMERecordEvent{Type: RecordChanged, Domain: next.Domain, Old: &record, New: &next})
+		}
+	}
+
+	for _, record := range after {
+		if _, ok := oldByDomain[normalizeDomain(record.Domain)]; !ok {
+			events = append(events, CNAMERecordEvent{Type: RecordAdded, Domain: record.Domain, New: &record})
+		}
+	}
+
+	return events
+}
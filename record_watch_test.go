@@ -0,0 +1,117 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDNSRecords(t *testing.T) {
+	old := DNSRecordList{
+		{IP: "10.0.0.1", Domain: "nas.lan"},
+		{IP: "10.0.0.2", Domain: "printer.lan"},
+		{IP: "10.0.0.3", Domain: "tv.lan", Comment: "living room"},
+		{IP: "10.0.0.4", Domain: "multi.lan"},
+		{IP: "fd00::4", Domain: "multi.lan"},
+	}
+	after := DNSRecordList{
+		{IP: "10.0.0.1", Domain: "NAS.lan"},
+		{IP: "10.0.0.20", Domain: "printer.lan"},
+		{IP: "10.0.0.3", Domain: "tv.lan", Comment: "bedroom"},
+		{IP: "10.0.0.5", Domain: "multi.lan"},
+		{IP: "fd00::5", Domain: "multi.lan"},
+		{IP: "10.0.0.6", Domain: "camera.lan"},
+	}
+
+	events := diffDNSRecords(old, after)
+
+	got := make(map[string][]RecordEventType)
+	for _, event := range events {
+		got[event.Domain] = append(got[event.Domain], event.Type)
+	}
+
+	assert.Equal(t, map[string][]RecordEventType{
+		"tv.lan":      {RecordChanged},
+		"printer.lan": {RecordChanged},
+		"multi.lan":   {RecordRemoved, RecordRemoved, RecordAdded, RecordAdded},
+		"camera.lan":  {RecordAdded},
+	}, got)
+
+	for _, event := range events {
+		if event.Domain == "printer.lan" {
+			assert.Equal(t, "10.0.0.2", event.Old.IP)
+			assert.Equal(t, "10.0.0.20", event.New.IP)
+		}
+	}
+}
+
+func TestDiffCNAMERecords(t *testing.T) {
+	old := CNAMERecordList{
+		{Domain: "www.lan", Target: "nas.lan"},
+		{Domain: "old.lan", Target: "nas.lan"},
+	}
+	after := CNAMERecordList{
+		{Domain: "www.lan", Target: "web.lan"},
+		{Domain: "new.lan", Target: "nas.lan"},
+	}
+
+	events := diffCNAMERecords(old, after)
+	require.Len(t, events, 3)
+	assert.Equal(t, RecordChanged, events[0].Type)
+	assert.Equal(t, "web.lan", events[0].New.Target)
+	assert.Equal(t, RecordRemoved, events[1].Type)
+	assert.Equal(t, "old.lan", events[1].Old.Domain)
+	assert.Equal(t, RecordAdded, events[2].Type)
+	assert.Equal(t, "new.lan", events[2].New.Domain)
+}
+
+func TestLocalDNS_WatchEmitsOutOfBandChanges(t *testing.T) {
+	isUnit(t)
+
+	var mu sync.Mutex
+	hosts := []string{"10.0.0.1 nas.lan"}
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, err := json.Marshal(map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"hosts": hosts}}})
+		require.NoError(t, err)
+		return newHTTPResponse(http.StatusOK, string(b)), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.LocalDNS.Watch(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	mu.Lock()
+	hosts = []string{"10.0.0.1 nas.lan", "10.0.0.2 printer.lan"}
+	mu.Unlock()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, RecordAdded, event.Type)
+		assert.Equal(t, "printer.lan", event.Domain)
+		assert.Equal(t, "10.0.0.2", event.New.IP)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for record event")
+	}
+
+	cancel()
+	for range events {
+	}
+
+	_, err = client.LocalDNS.Watch(context.Background(), 0)
+	assert.Error(t, err)
+}
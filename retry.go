@@ -0,0 +1,85 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Mutations sent through the default retrying client are not replayed by the
+// transport: a POST, PUT or DELETE whose response was lost may already have been
+// applied, and replaying it would fail with a duplicate or not-found error. Instead
+// the client checks whether the mutation took effect before sending it again.
+
+type noTransportRetryKey struct{}
+
+// withoutTransportRetry marks ctx so that the retrying transport sends its request
+// only once.
+func withoutTransportRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTransportRetryKey{}, true)
+}
+
+// retryPolicy is the retry policy of the default client, skipping requests marked by
+// withoutTransportRetry.
+func retryPolicy(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if skip, _ := ctx.Value(noTransportRetryKey{}).(bool); skip {
+		return false, nil
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+}
+
+// retrySettings are the retry limits of the default client, reused for mutations.
+type retrySettings struct {
+	max     int
+	waitMin time.Duration
+	waitMax time.Duration
+}
+
+// mutate sends a POST, PUT or DELETE with an optional body. When retries are enabled
+// and the request fails with a transport error or a server error, applied is
+// consulted: if the mutation took effect anyway, mutate reports done without a
+// response; otherwise it tries again. The caller must close the returned response.
+func (c *Client) mutate(ctx context.Context, method string, path string, body interface{}, applied func(context.Context) (bool, error)) (res *http.Response, done bool, err error) {
+	if c.retry == nil {
+		res, err := c.request(ctx, method, path, body)
+		return res, false, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err = c.request(withoutTransportRetry(ctx), method, path, body)
+		if !mutationMayHaveApplied(res, err) || attempt >= c.retry.max {
+			return res, false, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		ok, checkErr := applied(ctx)
+		if checkErr == nil && ok {
+			return nil, true, nil
+		}
+
+		wait := retryablehttp.DefaultBackoff(c.retry.waitMin, c.retry.waitMax, attempt, res)
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// mutationMayHaveApplied reports whether a mutation failed in a way that leaves its
+// outcome unknown.
+func mutationMayHaveApplied(res *http.Response, err error) bool {
+	if err != nil {
+		var reqErr *RequestError
+		return errors.As(err, &reqErr)
+	}
+
+	return res.StatusCode >= http.StatusInternalServerError
+}
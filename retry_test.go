@@ -0,0 +1,173 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutate_VerifiesPostConditionAfterLostResponse(t *testing.T) {
+	isUnit(t)
+
+	hosts := []string{}
+	var puts int
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/dns/hosts":
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":["`+strings.Join(hosts, `","`)+`"]}}}`), nil
+		case req.Method == http.MethodPut:
+			puts++
+			if len(hosts) > 0 {
+				return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"Item already present","hint":null}}`), nil
+			}
+			// The entry is stored but the response never arrives.
+			hosts = append(hosts, "10.0.0.1 nas.lan")
+			return nil, errors.New("read: connection reset by peer")
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	_, err = client.LocalDNS.Create(context.Background(), "nas.lan", "10.0.0.1")
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr, "without retries the lost response surfaces")
+
+	hosts = nil
+	client.retry = &retrySettings{max: 3, waitMin: time.Millisecond, waitMax: time.Millisecond}
+
+	record, err := client.LocalDNS.Create(context.Background(), "nas.lan", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan", record.Domain)
+	assert.Equal(t, 2, puts, "the create is not replayed once it is known to have applied")
+}
+
+func TestRetryPolicySkipsMarkedRequests(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	retry, err := retryPolicy(context.Background(), res, nil)
+	require.NoError(t, err)
+	assert.True(t, retry)
+
+	retry, err = retryPolicy(withoutTransportRetry(context.Background()), res, nil)
+	require.NoError(t, err)
+	assert.False(t, retry)
+}
+
+func TestMutate_LostResponsesAreNotReplayed(t *testing.T) {
+	isUnit(t)
+
+	tcs := []struct {
+		name    string
+		method  string
+		path    string
+		getPath string
+		before  string
+		after   string
+		run     func(context.Context, *Client) error
+	}{
+		{
+			name:    "domain add",
+			method:  http.MethodPost,
+			path:    "/api/domains/deny/exact",
+			getPath: "/api/domains",
+			before:  `{"domains":[]}`,
+			after:   `{"domains":[{"id":1,"domain":"ads.test","type":"deny","kind":"exact","enabled":true}]}`,
+			run: func(ctx context.Context, c *Client) error {
+				results, err := c.Domains.AddBatch(ctx, DomainKindExact, []DomainEntry{{Domain: "ads.test", Type: DomainTypeDeny}})
+				if err == nil && results[0].Status != DomainBatchCreated {
+					return errors.New("unexpected status " + string(results[0].Status))
+				}
+				return err
+			},
+		},
+		{
+			name:    "client delete",
+			method:  http.MethodDelete,
+			path:    "/api/clients/10.0.0.5",
+			getPath: "/api/clients/10.0.0.5",
+			before:  `{"clients":[{"id":1,"client":"10.0.0.5","groups":[0]}]}`,
+			after:   `{"clients":[]}`,
+			run: func(ctx context.Context, c *Client) error {
+				return c.Clients.Delete(ctx, "10.0.0.5")
+			},
+		},
+		{
+			name:    "group create",
+			method:  http.MethodPost,
+			path:    "/api/groups",
+			getPath: "/api/groups",
+			before:  `{"groups":[]}`,
+			after:   `{"groups":[{"id":1,"name":"kids","enabled":true}]}`,
+			run: func(ctx context.Context, c *Client) error {
+				_, err := c.Groups.Create(ctx, GroupEntry{Name: "kids"})
+				return err
+			},
+		},
+		{
+			name:    "DHCP reservation",
+			method:  http.MethodPut,
+			path:    "/api/config/dhcp/hosts/aa:bb:cc:dd:ee:ff,10.0.0.9,nas",
+			getPath: "/api/config/dhcp/hosts",
+			before:  `{"config":{"dhcp":{"hosts":[]}}}`,
+			after:   `{"config":{"dhcp":{"hosts":["aa:bb:cc:dd:ee:ff,10.0.0.9,nas"]}}}`,
+			run: func(ctx context.Context, c *Client) error {
+				return dhcp{client: c}.reserve(ctx, Lease{MAC: "AA:BB:CC:DD:EE:FF", IP: "10.0.0.9"}, "nas")
+			},
+		},
+		{
+			name:    "network device delete",
+			method:  http.MethodDelete,
+			path:    "/api/network/devices/3",
+			getPath: "/api/network/devices",
+			before:  `{"devices":[{"id":3,"hwaddr":"aa:bb:cc:dd:ee:ff"}]}`,
+			after:   `{"devices":[]}`,
+			run: func(ctx context.Context, c *Client) error {
+				return c.Network.DeleteDevice(ctx, 3)
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var applied bool
+			var sent int
+
+			transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				switch {
+				case req.Method == http.MethodGet && req.URL.Path == tc.getPath:
+					if applied {
+						return newHTTPResponse(http.StatusOK, tc.after), nil
+					}
+					return newHTTPResponse(http.StatusOK, tc.before), nil
+				case req.Method == tc.method && req.URL.Path == tc.path:
+					sent++
+					if applied {
+						return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"replayed","hint":null}}`), nil
+					}
+					// The change is stored but the response never arrives.
+					applied = true
+					return nil, errors.New("read: connection reset by peer")
+				default:
+					return newHTTPResponse(http.StatusNotFound, ``), nil
+				}
+			})
+
+			client, err := newTransportClient(transport)
+			require.NoError(t, err)
+			client.retry = &retrySettings{max: 3, waitMin: time.Millisecond, waitMax: time.Millisecond}
+
+			require.NoError(t, tc.run(context.Background(), client))
+			assert.Equal(t, 1, sent, "the mutation is not replayed once it is known to have applied")
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 )
@@ -49,22 +50,29 @@ type Session struct {
 	TOTP       bool
 	CSRF       string
 	Expiration time.Time
+
+	// Validity is how long the session lasts without activity. Every authenticated
+	// request extends it by this much.
+	Validity time.Duration
 }
 
 func (r sessionResponse) ToSession() Session {
+	validity := time.Duration(r.Session.Validity) * time.Second
+
 	s := Session{
 		SID:        r.Session.SID,
 		CSRF:       r.Session.CSRF,
 		TOTP:       r.Session.TOTP,
-		Expiration: time.Now().Add(time.Duration(r.Session.Validity) * time.Second),
+		Expiration: time.Now().Add(validity),
+		Validity:   validity,
 	}
 
 	return s
 }
 
 var (
-	ErrorSessionNotFound        = errors.New("session not found")
-	ErrorSessionUnauthorized    = errors.New("unauthorized session request")
+	ErrorSessionNotFound        = newNotFoundError("session not found")
+	ErrorSessionUnauthorized    = newUnauthenticatedError("unauthorized session request")
 	ErrorSessionBadRequest      = errors.New("bad session request")
 	ErrorSessionTooManyRequests = errors.New("too many session requests")
 )
@@ -77,7 +85,11 @@ func (s *sessionAPI) Login(ctx context.Context) (Session, error) {
 	}
 
 	s.client.sessionLock.Lock()
-	s.client.auth.sid = session.SID
+	s.client.auth = auth{sid: session.SID, csrf: session.CSRF}
+	if session.Validity > 0 {
+		s.client.auth.validity = session.Validity
+		s.client.auth.expires = session.Expiration
+	}
 	s.client.sessionLock.Unlock()
 
 	return session, nil
@@ -100,7 +112,7 @@ func (s *sessionAPI) Logout(ctx context.Context) error {
 	s.client.sessionLock.Lock()
 	defer s.client.sessionLock.Unlock()
 
-	s.client.auth.sid = ""
+	s.client.auth = auth{}
 
 	return nil
 }
@@ -134,8 +146,18 @@ func (s *sessionAPI) Post(ctx context.Context) (Session, error) {
 	}
 	defer res.Body.Close()
 
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Session{}, err
+	}
+
+	// A rejected password may come without a body, so check for it before decoding.
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return Session{}, newAuthError(res, b)
+	}
+
 	var sesRes sessionResponse
-	if err := json.NewDecoder(res.Body).Decode(&sesRes); err != nil {
+	if err := json.Unmarshal(b, &sesRes); err != nil {
 		return Session{}, err
 	}
 
@@ -170,3 +192,101 @@ func (s *sessionAPI) Delete(ctx context.Context, sessionID string) error {
 		return fmt.Errorf("unexpected status code %d", res.StatusCode)
 	}
 }
+
+// defaultSessionRefreshMargin is how long before expiry a session is refreshed unless
+// Config.SessionRefreshMargin says otherwise.
+const defaultSessionRefreshMargin = 30 * time.Second
+
+// maxSessionRefreshFraction caps the refresh margin at a fraction of the session's
+// validity, so that a short validity does not make every request log in again.
+const maxSessionRefreshFraction = 4
+
+// SessionExpiration returns when the client's session lapses if it sends no further
+// requests, or the zero time if the client did not log in itself.
+func (c *Client) SessionExpiration() time.Time {
+	c.sessionLock.RLock()
+	defer c.sessionLock.RUnlock()
+
+	return c.auth.expires
+}
+
+// refreshSession logs in again when the current session expires within the refresh
+// margin, returning the session ID to use. Concurrent callers share one login, and
+// the superseded session is deleted so that refreshes do not use up API seats.
+func (c *Client) refreshSession(ctx context.Context) (string, error) {
+	if !c.sessionExpiring() {
+		c.sessionLock.RLock()
+		defer c.sessionLock.RUnlock()
+
+		return c.auth.sid, nil
+	}
+
+	c.refreshLock.Lock()
+	defer c.refreshLock.Unlock()
+
+	// Another request may have refreshed the session while this one waited.
+	if !c.sessionExpiring() {
+		c.sessionLock.RLock()
+		defer c.sessionLock.RUnlock()
+
+		return c.auth.sid, nil
+	}
+
+	c.sessionLock.RLock()
+	previous := c.auth
+	c.sessionLock.RUnlock()
+
+	session, err := c.SessionAPI.Login(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if previous.sid != "" && previous.sid != session.SID {
+		c.endSession(ctx, previous)
+	}
+
+	return session.SID, nil
+}
+
+// endSession logs out of the superseded session previous through a clone holding it.
+// This is best effort: the session lapses on its own if the logout fails.
+func (c *Client) endSession(ctx context.Context, previous auth) {
+	clone, err := c.With()
+	if err != nil {
+		return
+	}
+
+	clone.auth = auth{sid: previous.sid, csrf: previous.csrf}
+	_ = clone.SessionAPI.Logout(ctx)
+}
+
+func (c *Client) sessionExpiring() bool {
+	if c.refreshMargin < 0 || c.password == "" {
+		return false
+	}
+
+	c.sessionLock.RLock()
+	defer c.sessionLock.RUnlock()
+
+	if c.auth.expires.IsZero() {
+		return false
+	}
+
+	margin := c.refreshMargin
+	if limit := c.auth.validity / maxSessionRefreshFraction; limit > 0 && margin > limit {
+		margin = limit
+	}
+
+	return !time.Now().Add(margin).Before(c.auth.expires)
+}
+
+// touchSession extends the expiry of session sid after an authenticated request, as
+// Pi-hole does on every request it accepts.
+func (c *Client) touchSession(sid string) {
+	c.sessionLock.Lock()
+	defer c.sessionLock.Unlock()
+
+	if c.auth.sid == sid && c.auth.validity > 0 {
+		c.auth.expires = time.Now().Add(c.auth.validity)
+	}
+}
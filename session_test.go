@@ -2,7 +2,10 @@ package pihole
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,3 +64,177 @@ func TestSessionLogout(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionRefreshesBeforeExpiry(t *testing.T) {
+	isUnit(t)
+
+	var logins int
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/auth":
+			logins++
+			return newHTTPResponse(http.StatusOK, fmt.Sprintf(`{"session":{"valid":true,"sid":"sid-%d","validity":1800}}`, logins)), nil
+		case req.URL.Path == "/api/info/messages/count":
+			assert.Equal(t, fmt.Sprintf("sid-%d", logins), req.Header.Get(authHeader))
+			return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", Password: "secret", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, logins)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), client.SessionExpiration(), time.Minute)
+
+	// A session about to lapse is replaced before the request is sent.
+	client.sessionLock.Lock()
+	client.auth.expires = time.Now().Add(10 * time.Second)
+	client.sessionLock.Unlock()
+
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, logins)
+}
+
+func TestSessionSendsCSRFTokenOnMutations(t *testing.T) {
+	isUnit(t)
+
+	csrfByMethod := make(map[string]string)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/auth":
+			assert.Empty(t, req.Header.Get(csrfHeader))
+			return newHTTPResponse(http.StatusOK, `{"session":{"valid":true,"sid":"sid","csrf":"token","validity":1800}}`), nil
+		case req.URL.Path == "/api/config/dns/hosts":
+			csrfByMethod[req.Method] = req.Header.Get(csrfHeader)
+			return newHTTPResponse(http.StatusOK, `{"config":{"dns":{"hosts":[]}}}`), nil
+		case req.Method == http.MethodPut:
+			csrfByMethod[req.Method] = req.Header.Get(csrfHeader)
+			return newHTTPResponse(http.StatusCreated, ``), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", Password: "secret", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	_, _ = client.LocalDNS.Create(context.Background(), "nas.lan", "10.0.0.1")
+
+	assert.Equal(t, "token", csrfByMethod[http.MethodPut])
+	assert.Empty(t, csrfByMethod[http.MethodGet])
+}
+
+func TestSessionTransportCookie(t *testing.T) {
+	isUnit(t)
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Empty(t, req.Header.Get(authHeader))
+
+		cookie, err := req.Cookie("sid")
+		require.NoError(t, err)
+		assert.Equal(t, "cookie-sid", cookie.Value)
+
+		return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+	})}
+
+	client, err := New(Config{
+		BaseURL:          "http://pi.test",
+		SessionID:        "cookie-sid",
+		HttpClient:       httpClient,
+		SessionTransport: SessionTransportCookie,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Messages.Count(context.Background())
+	require.NoError(t, err)
+
+	_, err = New(Config{BaseURL: "http://pi.test", SessionTransport: "carrier-pigeon"})
+	assert.ErrorIs(t, err, ErrClientValidation)
+}
+
+func TestSessionRefreshLogsOutPreviousSession(t *testing.T) {
+	isUnit(t)
+
+	var logins int
+	var loggedOut []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/auth":
+			logins++
+			return newHTTPResponse(http.StatusOK, fmt.Sprintf(`{"session":{"valid":true,"sid":"sid-%d","csrf":"csrf-%d","validity":1800}}`, logins, logins)), nil
+		case req.Method == http.MethodDelete && req.URL.Path == "/api/auth/":
+			loggedOut = append(loggedOut, req.Header.Get(authHeader)+" "+req.Header.Get(csrfHeader))
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		case req.URL.Path == "/api/info/messages/count":
+			return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", Password: "secret", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, loggedOut)
+
+	client.sessionLock.Lock()
+	client.auth.expires = time.Now().Add(10 * time.Second)
+	client.sessionLock.Unlock()
+
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, logins)
+	assert.Equal(t, []string{"sid-1 csrf-1"}, loggedOut)
+	assert.Equal(t, "sid-2", client.auth.sid)
+}
+
+func TestSessionRefreshMarginIsCappedByValidity(t *testing.T) {
+	isUnit(t)
+
+	var logins int
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/auth":
+			logins++
+			return newHTTPResponse(http.StatusOK, fmt.Sprintf(`{"session":{"valid":true,"sid":"sid-%d","validity":20}}`, logins)), nil
+		case req.Method == http.MethodDelete:
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusOK, `{"count":0}`), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", Password: "secret", HttpClient: httpClient})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// A 20 second session is shorter than the default margin, but is only refreshed
+	// within the last quarter of its validity.
+	for i := 0; i < 3; i++ {
+		_, err = client.Messages.Count(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, logins)
+
+	client.sessionLock.Lock()
+	client.auth.expires = time.Now().Add(4 * time.Second)
+	client.sessionLock.Unlock()
+
+	_, err = client.Messages.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, logins)
+}
@@ -0,0 +1,56 @@
+package pihole
+
+import (
+	"time"
+)
+
+// RequestInfo describes a request sent to Pi-hole.
+type RequestInfo struct {
+	// BaseURL identifies the instance the request was sent to.
+	BaseURL string
+	Method  string
+	Path    string
+
+	// RequestID is the X-Request-ID the request was sent with.
+	RequestID string
+
+	// StatusCode is the status of the response, or 0 if none was received.
+	StatusCode int
+
+	// Duration is the time from sending the request until the response headers
+	// arrived, including retries.
+	Duration time.Duration
+
+	// Err is the error sending the request, if any.
+	Err error
+}
+
+// SlowRequestHook is called with a request that took longer than
+// Config.SlowRequestThreshold.
+type SlowRequestHook func(info RequestInfo)
+
+// OnSlowRequest registers a hook called, in registration order, for every request
+// that takes longer than Config.SlowRequestThreshold. Hooks run on the goroutine
+// that made the call, so they should return quickly.
+func (c *Client) OnSlowRequest(hook SlowRequestHook) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+
+	c.hooks.slowRequest = append(c.hooks.slowRequest, hook)
+}
+
+// observeDuration runs the slow request hooks if info took longer than the
+// threshold.
+func (c *Client) observeDuration(info RequestInfo) {
+	if c.slowThreshold <= 0 || info.Duration <= c.slowThreshold {
+		return
+	}
+
+	c.hooks.mu.RLock()
+	hooks := c.hooks.slowRequest
+	c.hooks.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(info)
+	}
+}
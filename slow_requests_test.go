@@ -0,0 +1,86 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnSlowRequest(t *testing.T) {
+	isUnit(t)
+
+	errUnreachable := errors.New("unreachable")
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/info/version":
+			time.Sleep(20 * time.Millisecond)
+			return newHTTPResponse(http.StatusOK, `{}`), nil
+		case "/api/info/ftl":
+			time.Sleep(20 * time.Millisecond)
+			return nil, errUnreachable
+		default:
+			return newHTTPResponse(http.StatusOK, `{}`), nil
+		}
+	})}
+
+	client, err := New(Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: httpClient, SlowRequestThreshold: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	var slow []RequestInfo
+	client.OnSlowRequest(func(info RequestInfo) {
+		slow = append(slow, info)
+	})
+
+	for _, path := range []string{"/api/info/version", "/api/info/system", "/api/info/ftl"} {
+		res, err := client.Get(context.Background(), path)
+		if err == nil {
+			res.Body.Close()
+		}
+	}
+
+	require.Len(t, slow, 2)
+	assert.Equal(t, "http://pi.test", slow[0].BaseURL)
+	assert.Equal(t, http.MethodGet, slow[0].Method)
+	assert.Equal(t, "/api/info/version", slow[0].Path)
+	assert.Equal(t, http.StatusOK, slow[0].StatusCode)
+	assert.NotEmpty(t, slow[0].RequestID)
+	assert.Greater(t, slow[0].Duration, 10*time.Millisecond)
+
+	assert.Equal(t, "/api/info/ftl", slow[1].Path)
+	assert.Zero(t, slow[1].StatusCode)
+	assert.ErrorIs(t, slow[1].Err, errUnreachable)
+
+	// Clones keep the threshold and the hooks registered so far.
+	slow = nil
+	clone, err := client.With()
+	require.NoError(t, err)
+	res, err := clone.Get(context.Background(), "/api/info/version")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Len(t, slow, 1)
+}
+
+func TestOnSlowRequest_DisabledWithoutThreshold(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(5 * time.Millisecond)
+		return newHTTPResponse(http.StatusOK, `{}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	called := false
+	client.OnSlowRequest(func(RequestInfo) { called = true })
+
+	res, err := client.Get(context.Background(), "/api/info/version")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.False(t, called)
+}
@@ -0,0 +1,264 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Stats interface {
+	// TopBlockedDomains returns the most frequently blocked domains.
+	TopBlockedDomains(ctx context.Context, opts TopOptions) ([]TopDomain, error)
+
+	// QueryTypes returns the number of queries per query type (A, AAAA, HTTPS, ...).
+	QueryTypes(ctx context.Context) (Breakdown, error)
+
+	// ReplyTypes returns the number of queries per reply type (IP, NXDOMAIN, CNAME, ...).
+	ReplyTypes(ctx context.Context) (Breakdown, error)
+
+	// StatusTypes returns the number of queries per query status (GRAVITY, FORWARDED, CACHE, ...).
+	StatusTypes(ctx context.Context) (Breakdown, error)
+
+	// RecentBlocked returns the last n blocked queries, most recent first.
+	RecentBlocked(ctx context.Context, n int) (QueryList, error)
+
+	// CacheMetrics returns the size and churn of FTL's DNS cache together with the
+	// number of queries answered from it and forwarded upstream.
+	CacheMetrics(ctx context.Context) (*CacheMetrics, error)
+
+	// ByGroup returns the number of queries and blocked queries per group between
+	// from and until, joining the per-client counts of the long-term database with
+	// the groups each client belongs to. A client in several groups counts towards
+	// each of them.
+	ByGroup(ctx context.Context, from time.Time, until time.Time) ([]GroupStats, error)
+
+	// DomainHits returns how often a domain was queried between from and until, and
+	// by which clients, read from the long-term database. A zero until means now,
+	// and the domain may contain * wildcards.
+	DomainHits(ctx context.Context, domain string, from time.Time, until time.Time) (*DomainHits, error)
+
+	// Compare returns the number of queries, blocked queries and clients in two time
+	// ranges, read from the long-term database, with the change of each from
+	// previous to current. Use TimeRange.Previous for the period right before.
+	Compare(ctx context.Context, current TimeRange, previous TimeRange) (*SummaryComparison, error)
+
+	// DetectAnomalies returns the clients and domains whose query rate over a recent
+	// window rose well above their rate in a baseline window before it, most
+	// anomalous first. It reads the long-term database.
+	DetectAnomalies(ctx context.Context, opts AnomalyOptions) ([]Anomaly, error)
+}
+
+const recentBlockedPageSize = 100
+
+type stats struct {
+	client *Client
+}
+
+// TopOptions controls the size and time window of top lists. When From and Until
+// are both zero, FTL's in-memory statistics (the last 24 hours) are used; otherwise
+// the long-term database is queried.
+type TopOptions struct {
+	Limit int
+	From  time.Time
+	Until time.Time
+}
+
+type TopDomain struct {
+	Domain string
+	Count  int
+}
+
+type topDomainResponse struct {
+	Domain string `json:"domain"`
+	Count  int    `json:"count"`
+}
+
+type topDomainsResponse struct {
+	Domains []topDomainResponse `json:"domains"`
+}
+
+// Breakdown maps a category, such as a query type, to its number of queries.
+type Breakdown map[string]int
+
+// Total returns the sum of all categories.
+func (b Breakdown) Total() int {
+	total := 0
+	for _, count := range b {
+		total += count
+	}
+
+	return total
+}
+
+// Percentage returns the share of key in the total, between 0 and 100.
+func (b Breakdown) Percentage(key string) float64 {
+	return percentage(b[key], b.Total())
+}
+
+// Percentages returns the share of every category in the total, between 0 and 100.
+func (b Breakdown) Percentages() map[string]float64 {
+	total := b.Total()
+	percentages := make(map[string]float64, len(b))
+	for key, count := range b {
+		percentages[key] = percentage(count, total)
+	}
+
+	return percentages
+}
+
+type queryTypesResponse struct {
+	Types Breakdown `json:"types"`
+}
+
+type summaryResponse struct {
+	Queries summaryQueriesResponse `json:"queries"`
+}
+
+type summaryQueriesResponse struct {
+	Types   Breakdown `json:"types"`
+	Status  Breakdown `json:"status"`
+	Replies Breakdown `json:"replies"`
+}
+
+func (res topDomainsResponse) toTopDomains() []TopDomain {
+	list := make([]TopDomain, 0, len(res.Domains))
+	for _, entry := range res.Domains {
+		list = append(list, TopDomain{Domain: entry.Domain, Count: entry.Count})
+	}
+
+	return list
+}
+
+func (opts TopOptions) useDatabase() bool {
+	return !opts.From.IsZero() || !opts.Until.IsZero()
+}
+
+func (opts TopOptions) values() (url.Values, error) {
+	vals := url.Values{}
+
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", opts.Limit)
+	}
+	if opts.Limit > 0 {
+		vals.Set("count", strconv.Itoa(opts.Limit))
+	}
+
+	if opts.useDatabase() {
+		window := Between(opts.From, opts.Until)
+		if window.From.After(window.until()) {
+			return nil, fmt.Errorf("invalid time window: from %s is after until %s", window.From, window.until())
+		}
+
+		for key, value := range window.values() {
+			vals[key] = value
+		}
+	}
+
+	return vals, nil
+}
+
+// TopBlockedDomains returns the most blocked domains, using the long-term database
+// when a time window is given and in-memory statistics otherwise
+func (s stats) TopBlockedDomains(ctx context.Context, opts TopOptions) ([]TopDomain, error) {
+	vals, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	vals.Set("blocked", "true")
+
+	path := "/api/stats/top_domains"
+	if opts.useDatabase() {
+		path = "/api/stats/database/top_domains"
+	}
+
+	var resTop topDomainsResponse
+	if err := s.get(ctx, path+"?"+vals.Encode(), &resTop); err != nil {
+		return nil, fmt.Errorf("failed to fetch top blocked domains: %w", err)
+	}
+
+	return resTop.toTopDomains(), nil
+}
+
+// QueryTypes returns the query type distribution
+func (s stats) QueryTypes(ctx context.Context) (Breakdown, error) {
+	var resTypes queryTypesResponse
+	if err := s.get(ctx, "/api/stats/query_types", &resTypes); err != nil {
+		return nil, fmt.Errorf("failed to fetch query types: %w", err)
+	}
+
+	return resTypes.Types, nil
+}
+
+// ReplyTypes returns the reply type distribution
+func (s stats) ReplyTypes(ctx context.Context) (Breakdown, error) {
+	var resSummary summaryResponse
+	if err := s.get(ctx, "/api/stats/summary", &resSummary); err != nil {
+		return nil, fmt.Errorf("failed to fetch reply types: %w", err)
+	}
+
+	return resSummary.Queries.Replies, nil
+}
+
+// StatusTypes returns the query status distribution
+func (s stats) StatusTypes(ctx context.Context) (Breakdown, error) {
+	var resSummary summaryResponse
+	if err := s.get(ctx, "/api/stats/summary", &resSummary); err != nil {
+		return nil, fmt.Errorf("failed to fetch query status types: %w", err)
+	}
+
+	return resSummary.Queries.Status, nil
+}
+
+// RecentBlocked pages through the blocked queries of the log from the most recent
+// one until n are found or the log is exhausted, so at most n queries are kept
+func (s stats) RecentBlocked(ctx context.Context, n int) (QueryList, error) {
+	blocked := make(QueryList, 0, max(n, 0))
+	if n <= 0 {
+		return blocked, nil
+	}
+
+	filter := NewQueryFilter().Status(QueryStatusBlocked).Length(recentBlockedPageSize)
+	err := s.client.Queries.ListPages(ctx, filter, func(page *QueryPage) error {
+		for _, query := range page.Queries {
+			if !query.Status.Blocked() {
+				continue
+			}
+
+			blocked = append(blocked, query)
+			if len(blocked) == n {
+				return ErrStopPaging
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent queries: %w", err)
+	}
+
+	return blocked, nil
+}
+
+func (s stats) get(ctx context.Context, path string, v interface{}) error {
+	res, err := s.client.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return newStatsAPIError(res, b)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse stats body: %w", err)
+	}
+
+	return nil
+}
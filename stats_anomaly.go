@@ -0,0 +1,236 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAnomalyWindow     = time.Hour
+	defaultAnomalyBaseline   = 24 * time.Hour
+	defaultAnomalyFactor     = 3
+	defaultAnomalyMinQueries = 50
+	defaultAnomalyLimit      = 100
+)
+
+// AnomalyOptions tunes DetectAnomalies. Zero values select the defaults.
+type AnomalyOptions struct {
+	// Window is the period ending now whose query rates are checked, one hour by
+	// default.
+	Window time.Duration
+
+	// Baseline is the period right before Window that rates are compared against,
+	// 24 hours by default.
+	Baseline time.Duration
+
+	// Factor is how many times its baseline rate a client or domain must reach to be
+	// reported, 3 by default.
+	Factor float64
+
+	// MinQueries is the fewest queries within Window for a client or domain to be
+	// reported, 50 by default. It keeps rarely used names out of the results.
+	MinQueries int
+
+	// Limit is how many top clients and domains are fetched per window, 100 by
+	// default. Names missing from the baseline's top list count as new.
+	Limit int
+}
+
+// AnomalyKind is whether an anomaly concerns a client or a domain.
+type AnomalyKind string
+
+const (
+	AnomalyClient AnomalyKind = "client"
+	AnomalyDomain AnomalyKind = "domain"
+)
+
+// Anomaly is a client or domain whose query rate rose above its baseline. Rates are
+// in queries per hour.
+type Anomaly struct {
+	Kind AnomalyKind
+
+	// Name is the client's IP address or the domain.
+	Name string
+
+	// Hostname is the client's name as known to Pi-hole, if any.
+	Hostname string
+
+	Count        int
+	Rate         float64
+	BaselineRate float64
+
+	// Factor is Rate divided by BaselineRate, or +Inf for names without baseline
+	// queries.
+	Factor float64
+}
+
+type topClientResponse struct {
+	IP    string `json:"ip"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type topClientsResponse struct {
+	Clients []topClientResponse `json:"clients"`
+}
+
+func (opts AnomalyOptions) withDefaults() (AnomalyOptions, error) {
+	if opts.Window < 0 || opts.Baseline < 0 || opts.Factor < 0 || opts.MinQueries < 0 || opts.Limit < 0 {
+		return opts, fmt.Errorf("invalid anomaly options: negative value")
+	}
+
+	if opts.Window == 0 {
+		opts.Window = defaultAnomalyWindow
+	}
+	if opts.Baseline == 0 {
+		opts.Baseline = defaultAnomalyBaseline
+	}
+	if opts.Factor == 0 {
+		opts.Factor = defaultAnomalyFactor
+	}
+	if opts.MinQueries == 0 {
+		opts.MinQueries = defaultAnomalyMinQueries
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultAnomalyLimit
+	}
+
+	return opts, nil
+}
+
+// DetectAnomalies compares the per-hour query rates of the top clients and domains
+// in the current window against the baseline window before it
+func (s stats) DetectAnomalies(ctx context.Context, opts AnomalyOptions) ([]Anomaly, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-opts.Window)
+	baselineStart := windowStart.Add(-opts.Baseline)
+
+	currentClients, hostnames, err := s.topClientCounts(ctx, windowStart, now, opts.Limit, false)
+	if err != nil {
+		return nil, err
+	}
+	baselineClients, _, err := s.topClientCounts(ctx, baselineStart, windowStart, opts.Limit, false)
+	if err != nil {
+		return nil, err
+	}
+
+	currentDomains, err := s.topDomainCounts(ctx, windowStart, now, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	baselineDomains, err := s.topDomainCounts(ctx, baselineStart, windowStart, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := detectAnomalies(AnomalyClient, currentClients, baselineClients, opts)
+	for i := range anomalies {
+		anomalies[i].Hostname = hostnames[anomalies[i].Name]
+	}
+	anomalies = append(anomalies, detectAnomalies(AnomalyDomain, currentDomains, baselineDomains, opts)...)
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if anomalies[i].Factor != anomalies[j].Factor {
+			return anomalies[i].Factor > anomalies[j].Factor
+		}
+		return anomalies[i].Count > anomalies[j].Count
+	})
+
+	return anomalies, nil
+}
+
+// detectAnomalies returns the names whose rate in current reaches opts.Factor times
+// their rate in baseline.
+func detectAnomalies(kind AnomalyKind, current map[string]int, baseline map[string]int, opts AnomalyOptions) []Anomaly {
+	anomalies := make([]Anomaly, 0)
+	for name, count := range current {
+		if count < opts.MinQueries {
+			continue
+		}
+
+		rate := float64(count) / opts.Window.Hours()
+		baselineRate := float64(baseline[name]) / opts.Baseline.Hours()
+
+		factor := math.Inf(1)
+		if baselineRate > 0 {
+			factor = rate / baselineRate
+		}
+		if factor < opts.Factor {
+			continue
+		}
+
+		anomalies = append(anomalies, Anomaly{
+			Kind:         kind,
+			Name:         name,
+			Count:        count,
+			Rate:         rate,
+			BaselineRate: baselineRate,
+			Factor:       factor,
+		})
+	}
+
+	return anomalies
+}
+
+// topClientCounts returns the query counts of the top clients, or their blocked
+// query counts if blocked is set, and their hostnames.
+func (s stats) topClientCounts(ctx context.Context, from time.Time, until time.Time, limit int, blocked bool) (map[string]int, map[string]string, error) {
+	vals := windowValues(from, until, limit)
+	if blocked {
+		vals.Set("blocked", "true")
+	}
+
+	var resTop topClientsResponse
+	if err := s.get(ctx, "/api/stats/database/top_clients?"+vals.Encode(), &resTop); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch top clients: %w", err)
+	}
+
+	counts := make(map[string]int, len(resTop.Clients))
+	hostnames := make(map[string]string, len(resTop.Clients))
+	for _, entry := range resTop.Clients {
+		counts[entry.IP] += entry.Count
+		if entry.Name != "" {
+			hostnames[entry.IP] = entry.Name
+		}
+	}
+
+	return counts, hostnames, nil
+}
+
+// topDomainCounts sums the permitted and blocked top lists, since beaconing shows in
+// either depending on whether the destination is on a blocklist.
+func (s stats) topDomainCounts(ctx context.Context, from time.Time, until time.Time, limit int) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, blocked := range []bool{false, true} {
+		vals := windowValues(from, until, limit)
+		vals.Set("blocked", strconv.FormatBool(blocked))
+
+		var resTop topDomainsResponse
+		if err := s.get(ctx, "/api/stats/database/top_domains?"+vals.Encode(), &resTop); err != nil {
+			return nil, fmt.Errorf("failed to fetch top domains: %w", err)
+		}
+
+		for _, entry := range resTop.Domains {
+			counts[entry.Domain] += entry.Count
+		}
+	}
+
+	return counts, nil
+}
+
+func windowValues(from time.Time, until time.Time, limit int) url.Values {
+	vals := Between(from, until).values()
+	vals.Set("count", strconv.Itoa(limit))
+
+	return vals
+}
@@ -0,0 +1,76 @@
+package pihole
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_DetectAnomalies(t *testing.T) {
+	isUnit(t)
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+		until, _ := strconv.ParseInt(query.Get("until"), 10, 64)
+		current := until-from == 3600
+
+		switch {
+		case req.URL.Path == "/api/stats/database/top_clients" && current:
+			return newHTTPResponse(http.StatusOK, `{"clients":[
+				{"ip":"10.0.0.5","name":"iot-camera","count":600},
+				{"ip":"10.0.0.6","name":"laptop","count":100}
+			]}`), nil
+		case req.URL.Path == "/api/stats/database/top_clients":
+			// 24 hours at 100 and 96 queries per hour.
+			return newHTTPResponse(http.StatusOK, `{"clients":[
+				{"ip":"10.0.0.5","name":"iot-camera","count":2400},
+				{"ip":"10.0.0.6","name":"laptop","count":2304}
+			]}`), nil
+		case req.URL.Path == "/api/stats/database/top_domains" && current && query.Get("blocked") == "true":
+			return newHTTPResponse(http.StatusOK, `{"domains":[{"domain":"c2.example","count":40},{"domain":"ads.example","count":10}]}`), nil
+		case req.URL.Path == "/api/stats/database/top_domains" && current:
+			return newHTTPResponse(http.StatusOK, `{"domains":[{"domain":"c2.example","count":20},{"domain":"rare.example","count":3}]}`), nil
+		case req.URL.Path == "/api/stats/database/top_domains":
+			return newHTTPResponse(http.StatusOK, `{"domains":[{"domain":"ads.example","count":240}]}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+	require.NoError(t, err)
+
+	anomalies, err := client.Stats.DetectAnomalies(context.Background(), AnomalyOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, anomalies, 2)
+
+	assert.Equal(t, AnomalyDomain, anomalies[0].Kind)
+	assert.Equal(t, "c2.example", anomalies[0].Name)
+	assert.Equal(t, 60, anomalies[0].Count)
+	assert.True(t, math.IsInf(anomalies[0].Factor, 1))
+
+	assert.Equal(t, Anomaly{
+		Kind:         AnomalyClient,
+		Name:         "10.0.0.5",
+		Hostname:     "iot-camera",
+		Count:        600,
+		Rate:         600,
+		BaselineRate: 100,
+		Factor:       6,
+	}, anomalies[1])
+}
+
+func TestStats_DetectAnomaliesRejectsNegativeOptions(t *testing.T) {
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+	require.NoError(t, err)
+
+	_, err = client.Stats.DetectAnomalies(context.Background(), AnomalyOptions{Factor: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+)
+
+// CacheMetrics describes FTL's DNS cache. Cached and Forwarded count the queries of
+// the last 24 hours answered from the cache and by an upstream server.
+type CacheMetrics struct {
+	// Size is the maximum number of cache entries.
+	Size int
+	// Inserted is the number of entries inserted since FTL started.
+	Inserted int
+	// Evicted is the number of entries removed to make room before they expired.
+	// A growing count means the cache is too small.
+	Evicted int
+	// Expired is the number of entries that expired and are kept to be served stale.
+	Expired int
+	// Immortal is the number of entries that never expire, such as local records.
+	Immortal int
+	// Content is the number of entries per record type.
+	Content []CacheContent
+
+	Cached    int
+	Forwarded int
+}
+
+// CacheContent is the number of cache entries of a record type.
+type CacheContent struct {
+	Type  string
+	Valid int
+	Stale int
+}
+
+type metricsResponse struct {
+	Metrics struct {
+		DNS struct {
+			Cache struct {
+				Size     int `json:"size"`
+				Inserted int `json:"inserted"`
+				Evicted  int `json:"evicted"`
+				Expired  int `json:"expired"`
+				Immortal int `json:"immortal"`
+				Content  []struct {
+					Name  string `json:"name"`
+					Count struct {
+						Valid int `json:"valid"`
+						Stale int `json:"stale"`
+					} `json:"count"`
+				} `json:"content"`
+			} `json:"cache"`
+		} `json:"dns"`
+	} `json:"metrics"`
+}
+
+type cacheSummaryResponse struct {
+	Queries struct {
+		Cached    int `json:"cached"`
+		Forwarded int `json:"forwarded"`
+	} `json:"queries"`
+}
+
+// CacheMetrics combines FTL's cache metrics with the cached and forwarded query
+// counts of the summary
+func (s stats) CacheMetrics(ctx context.Context) (*CacheMetrics, error) {
+	var resMetrics metricsResponse
+	if err := s.get(ctx, "/api/info/metrics", &resMetrics); err != nil {
+		return nil, fmt.Errorf("failed to fetch cache metrics: %w", err)
+	}
+
+	var resSummary cacheSummaryResponse
+	if err := s.get(ctx, "/api/stats/summary", &resSummary); err != nil {
+		return nil, fmt.Errorf("failed to fetch cache metrics: %w", err)
+	}
+
+	cache := resMetrics.Metrics.DNS.Cache
+	metrics := &CacheMetrics{
+		Size:      cache.Size,
+		Inserted:  cache.Inserted,
+		Evicted:   cache.Evicted,
+		Expired:   cache.Expired,
+		Immortal:  cache.Immortal,
+		Content:   make([]CacheContent, 0, len(cache.Content)),
+		Cached:    resSummary.Queries.Cached,
+		Forwarded: resSummary.Queries.Forwarded,
+	}
+	for _, content := range cache.Content {
+		metrics.Content = append(metrics.Content, CacheContent{Type: content.Name, Valid: content.Count.Valid, Stale: content.Count.Stale})
+	}
+
+	return metrics, nil
+}
+
+// HitRatio returns the share of queries needing an answer from outside Pi-hole that
+// were answered from the cache, between 0 and 1.
+func (m CacheMetrics) HitRatio() float64 {
+	return ratio(m.Cached, m.Cached+m.Forwarded)
+}
+
+// MissRatio returns the share of those queries that were forwarded, between 0 and 1.
+func (m CacheMetrics) MissRatio() float64 {
+	return ratio(m.Forwarded, m.Cached+m.Forwarded)
+}
+
+// EvictionRatio returns the share of inserted entries that were evicted before they
+// expired, between 0 and 1. A high ratio suggests raising dns.cache.size.
+func (m CacheMetrics) EvictionRatio() float64 {
+	return ratio(m.Evicted, m.Inserted)
+}
+
+// Entries returns the number of entries in the cache, valid or stale.
+func (m CacheMetrics) Entries() int {
+	entries := 0
+	for _, content := range m.Content {
+		entries += content.Valid + content.Stale
+	}
+
+	return entries
+}
+
+// Utilization returns the share of the cache in use, between 0 and 1.
+func (m CacheMetrics) Utilization() float64 {
+	return ratio(m.Entries(), m.Size)
+}
+
+func ratio(n int, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total)
+}
+
+// percentage returns n as a share of total between 0 and 100, or 0 if total is 0.
+// Derived percentages go through it so they are never rounded differently.
+func percentage(n int, total int) float64 {
+	return ratio(n, total) * 100
+}
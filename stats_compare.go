@@ -0,0 +1,119 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// PeriodSummary is the query activity in a time range, read from the long-term
+// database.
+type PeriodSummary struct {
+	Range   TimeRange
+	Queries int
+	Blocked int
+	// Clients is the number of distinct clients that made queries.
+	Clients int
+}
+
+// BlockedPercentage returns the share of blocked queries, between 0 and 100.
+func (p PeriodSummary) BlockedPercentage() float64 {
+	return percentage(p.Blocked, p.Queries)
+}
+
+// QueriesPerSecond returns the average query rate over the range.
+func (p PeriodSummary) QueriesPerSecond() float64 {
+	seconds := p.Range.Duration().Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+
+	return float64(p.Queries) / seconds
+}
+
+// QueriesPerClient returns the average number of queries per client.
+func (p PeriodSummary) QueriesPerClient() float64 {
+	return ratio(p.Queries, p.Clients)
+}
+
+// MetricChange is how a metric moved from one period to the next.
+type MetricChange struct {
+	Previous int
+	Current  int
+	Delta    int
+
+	// Percent is Delta relative to Previous, e.g. 25 for a rise from 80 to 100. It
+	// is +Inf for a rise from 0, and 0 when both are 0.
+	Percent float64
+}
+
+// SummaryComparison compares the activity of two periods.
+type SummaryComparison struct {
+	Current  PeriodSummary
+	Previous PeriodSummary
+
+	Queries MetricChange
+	Blocked MetricChange
+	Clients MetricChange
+
+	// BlockedPoints is the change of the blocked share of queries in percentage
+	// points, e.g. 2.5 for a rise from 10% to 12.5%.
+	BlockedPoints float64
+}
+
+type databaseSummaryResponse struct {
+	SumQueries   int `json:"sum_queries"`
+	SumBlocked   int `json:"sum_blocked"`
+	TotalClients int `json:"total_clients"`
+}
+
+func newMetricChange(previous int, current int) MetricChange {
+	change := MetricChange{Previous: previous, Current: current, Delta: current - previous}
+	switch {
+	case previous != 0:
+		change.Percent = float64(change.Delta) / float64(previous) * 100
+	case current != 0:
+		change.Percent = math.Inf(1)
+	}
+
+	return change
+}
+
+// Compare fetches the database summaries of both ranges and computes the changes
+func (s stats) Compare(ctx context.Context, current TimeRange, previous TimeRange) (*SummaryComparison, error) {
+	currentSummary, err := s.periodSummary(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	previousSummary, err := s.periodSummary(ctx, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SummaryComparison{
+		Current:       *currentSummary,
+		Previous:      *previousSummary,
+		Queries:       newMetricChange(previousSummary.Queries, currentSummary.Queries),
+		Blocked:       newMetricChange(previousSummary.Blocked, currentSummary.Blocked),
+		Clients:       newMetricChange(previousSummary.Clients, currentSummary.Clients),
+		BlockedPoints: currentSummary.BlockedPercentage() - previousSummary.BlockedPercentage(),
+	}, nil
+}
+
+func (s stats) periodSummary(ctx context.Context, r TimeRange) (*PeriodSummary, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	var resSummary databaseSummaryResponse
+	if err := s.get(ctx, "/api/stats/database/summary?"+r.values().Encode(), &resSummary); err != nil {
+		return nil, fmt.Errorf("failed to fetch summary for %s: %w", r, err)
+	}
+
+	return &PeriodSummary{
+		Range:   r,
+		Queries: resSummary.SumQueries,
+		Blocked: resSummary.SumBlocked,
+		Clients: resSummary.TotalClients,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StatsDatabase reads statistics from FTL's long-term database rather than its
+// in-memory data of the last 24 hours.
+type StatsDatabase interface {
+	// Graph returns the query totals between from and until in buckets of interval,
+	// for charts spanning weeks or months. Bucket boundaries are aligned to
+	// multiples of interval, and buckets without queries are included as zeros.
+	// The interval must be a multiple of the database's ten-minute resolution.
+	Graph(ctx context.Context, from time.Time, until time.Time, interval time.Duration) ([]GraphPoint, error)
+}
+
+// databaseGraphResolution is the width of the buckets FTL returns from the database.
+const databaseGraphResolution = 10 * time.Minute
+
+type statsDatabase struct {
+	client *Client
+}
+
+// GraphPoint is the number of queries in the bucket starting at Time.
+type GraphPoint struct {
+	Time      time.Time
+	Total     int
+	Cached    int
+	Blocked   int
+	Forwarded int
+}
+
+// BlockedPercentage returns the share of blocked queries, between 0 and 100.
+func (p GraphPoint) BlockedPercentage() float64 {
+	return percentage(p.Blocked, p.Total)
+}
+
+// CachedPercentage returns the share of queries answered from the cache, between 0
+// and 100.
+func (p GraphPoint) CachedPercentage() float64 {
+	return percentage(p.Cached, p.Total)
+}
+
+type historyPointResponse struct {
+	Timestamp float64 `json:"timestamp"`
+	Total     int     `json:"total"`
+	Cached    int     `json:"cached"`
+	Blocked   int     `json:"blocked"`
+	Forwarded int     `json:"forwarded"`
+}
+
+type historyResponse struct {
+	History []historyPointResponse `json:"history"`
+}
+
+// Graph fetches the database history and merges its buckets into buckets of
+// interval
+func (d statsDatabase) Graph(ctx context.Context, from time.Time, until time.Time, interval time.Duration) ([]GraphPoint, error) {
+	if interval <= 0 || interval%databaseGraphResolution != 0 {
+		return nil, fmt.Errorf("invalid graph interval %s: must be a positive multiple of %s", interval, databaseGraphResolution)
+	}
+	if !from.Before(until) {
+		return nil, fmt.Errorf("invalid time window: from %s is not before until %s", from, until)
+	}
+
+	start := alignTime(from, interval)
+
+	var resHistory historyResponse
+	if err := (stats{client: d.client}).get(ctx, "/api/history/database?"+Between(start, until).values().Encode(), &resHistory); err != nil {
+		return nil, fmt.Errorf("failed to fetch database history: %w", err)
+	}
+
+	points := make([]GraphPoint, 0, int(until.Sub(start)/interval)+1)
+	for t := start; t.Before(until); t = t.Add(interval) {
+		points = append(points, GraphPoint{Time: t})
+	}
+
+	for _, entry := range resHistory.History {
+		i := int(unixFloat(entry.Timestamp).Sub(start) / interval)
+		if i < 0 || i >= len(points) {
+			continue
+		}
+
+		points[i].Total += entry.Total
+		points[i].Cached += entry.Cached
+		points[i].Blocked += entry.Blocked
+		points[i].Forwarded += entry.Forwarded
+	}
+
+	return points, nil
+}
+
+// alignTime rounds t down to a multiple of interval since the Unix epoch, so that
+// buckets line up across calls regardless of the local time zone.
+func alignTime(t time.Time, interval time.Duration) time.Time {
+	sec := t.Unix()
+	step := int64(interval / time.Second)
+
+	return time.Unix(sec-sec%step, 0).In(t.Location())
+}
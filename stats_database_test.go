@@ -0,0 +1,53 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDatabase_Graph(t *testing.T) {
+	isUnit(t)
+
+	var gotFrom string
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/history/database" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		gotFrom = req.URL.Query().Get("from")
+		return newHTTPResponse(http.StatusOK, `{"history":[
+			{"timestamp":1699999500,"total":99},
+			{"timestamp":1700000300,"total":10,"cached":2,"blocked":3,"forwarded":5},
+			{"timestamp":1700001500,"total":20,"cached":4,"blocked":6,"forwarded":10},
+			{"timestamp":1700008100,"total":7,"blocked":1,"forwarded":6}
+		]}`), nil
+	})
+	require.NoError(t, err)
+
+	// 1700000000 is 22:13:20 UTC, so hourly buckets start at 22:00.
+	from := time.Unix(1700000000, 0)
+	points, err := client.StatsDatabase.Graph(context.Background(), from, from.Add(3*time.Hour), time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1699999200", gotFrom)
+	require.Len(t, points, 4)
+	assert.Equal(t, int64(1699999200), points[0].Time.Unix())
+	assert.Equal(t, GraphPoint{Time: points[0].Time, Total: 129, Cached: 6, Blocked: 9, Forwarded: 15}, points[0])
+	assert.InDelta(t, 6.977, points[0].BlockedPercentage(), 0.001)
+	assert.InDelta(t, 4.651, points[0].CachedPercentage(), 0.001)
+	assert.Zero(t, points[1].Total)
+	assert.Zero(t, points[1].BlockedPercentage())
+	assert.Equal(t, 7, points[2].Total)
+	assert.Zero(t, points[3].Total)
+
+	_, err = client.StatsDatabase.Graph(context.Background(), from, from.Add(time.Hour), 15*time.Minute)
+	assert.Error(t, err)
+
+	_, err = client.StatsDatabase.Graph(context.Background(), from, from, time.Hour)
+	assert.Error(t, err)
+}
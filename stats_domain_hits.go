@@ -0,0 +1,106 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const domainHitsPageSize = 1000
+
+// DomainHits reports how often a domain was queried in a time window, and by whom.
+type DomainHits struct {
+	Domain  string
+	Queries int
+	Blocked int
+
+	// First and Last are the times of the earliest and latest query, zero if there
+	// were none.
+	First time.Time
+	Last  time.Time
+
+	// Clients are the clients that queried the domain, most queries first.
+	Clients []ClientHits
+}
+
+// BlockedPercentage returns the share of blocked queries, between 0 and 100.
+func (h DomainHits) BlockedPercentage() float64 {
+	return percentage(h.Blocked, h.Queries)
+}
+
+// ClientHits is the number of queries a client made for a domain.
+type ClientHits struct {
+	IP      string
+	Name    string
+	Queries int
+	Blocked int
+}
+
+// DomainHits pages through the long-term query database for the domain and counts
+// the queries per client
+func (s stats) DomainHits(ctx context.Context, domain string, from time.Time, until time.Time) (*DomainHits, error) {
+	hits := &DomainHits{Domain: domain, Clients: make([]ClientHits, 0)}
+	byIP := make(map[string]*ClientHits)
+
+	var cursor int64
+	for start := 0; ; start += domainHitsPageSize {
+		filter := NewQueryFilter().
+			Domain(domain).
+			Between(from, until).
+			Disk().
+			Length(domainHitsPageSize).
+			Start(start).
+			Cursor(cursor)
+
+		page, err := s.client.Queries.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch queries for %s: %w", domain, err)
+		}
+
+		if cursor == 0 {
+			cursor = page.Cursor
+		}
+
+		for _, query := range page.Queries {
+			client, ok := byIP[query.Client.IP]
+			if !ok {
+				client = &ClientHits{IP: query.Client.IP}
+				byIP[query.Client.IP] = client
+			}
+			if query.Client.Name != "" {
+				client.Name = query.Client.Name
+			}
+
+			client.Queries++
+			hits.Queries++
+			if query.Status.Blocked() {
+				client.Blocked++
+				hits.Blocked++
+			}
+
+			if hits.First.IsZero() || query.Time.Before(hits.First) {
+				hits.First = query.Time
+			}
+			if query.Time.After(hits.Last) {
+				hits.Last = query.Time
+			}
+		}
+
+		if len(page.Queries) < domainHitsPageSize {
+			break
+		}
+	}
+
+	for _, client := range byIP {
+		hits.Clients = append(hits.Clients, *client)
+	}
+	sort.Slice(hits.Clients, func(i, j int) bool {
+		if hits.Clients[i].Queries != hits.Clients[j].Queries {
+			return hits.Clients[i].Queries > hits.Clients[j].Queries
+		}
+		return hits.Clients[i].IP < hits.Clients[j].IP
+	})
+
+	return hits, nil
+}
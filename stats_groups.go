@@ -0,0 +1,101 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// groupStatsClientLimit is how many clients ByGroup fetches from the database, which
+// covers every client of all but the largest networks.
+const groupStatsClientLimit = 10000
+
+// GroupStats are the queries made by the clients of a group.
+type GroupStats struct {
+	ID   int64
+	Name string
+
+	// Clients is the number of clients that made queries, identified by IP address.
+	Clients int
+	Queries int
+	Blocked int
+}
+
+// BlockedPercentage returns the share of blocked queries, between 0 and 100.
+func (g GroupStats) BlockedPercentage() float64 {
+	return percentage(g.Blocked, g.Queries)
+}
+
+// ByGroup sums the per-client query and blocked counts of the long-term database by
+// the groups the clients belong to
+func (s stats) ByGroup(ctx context.Context, from time.Time, until time.Time) ([]GroupStats, error) {
+	if err := Between(from, until).validate(); err != nil {
+		return nil, err
+	}
+
+	queries, hostnames, err := s.topClientCounts(ctx, from, until, groupStatsClientLimit, false)
+	if err != nil {
+		return nil, err
+	}
+	blocked, _, err := s.topClientCounts(ctx, from, until, groupStatsClientLimit, true)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.client.Groups.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	managed, err := s.client.Clients.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clients: %w", err)
+	}
+	devices, err := s.client.Network.Devices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch network devices: %w", err)
+	}
+
+	byID := make(map[int64]*GroupStats, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = &GroupStats{ID: group.ID, Name: group.Name}
+	}
+
+	for ip, count := range queries {
+		resolved := &ResolvedClient{IPs: []string{ip}, Hostname: hostnames[ip]}
+		iface := ""
+		if device := devices.find(net.ParseIP(ip), ""); device != nil {
+			if isHardwareAddress(device.MAC) {
+				resolved.MAC = device.MAC
+			}
+			iface = device.Interface
+		}
+
+		memberships := []int{defaultGroupID}
+		if entries := managed.matching(resolved, iface); len(entries) > 0 {
+			memberships = entries[0].Groups
+		}
+
+		for _, id := range memberships {
+			group, ok := byID[int64(id)]
+			if !ok {
+				group = &GroupStats{ID: int64(id)}
+				byID[int64(id)] = group
+			}
+			group.Clients++
+			group.Queries += count
+			group.Blocked += blocked[ip]
+		}
+	}
+
+	stats := make([]GroupStats, 0, len(byID))
+	for _, group := range byID {
+		stats = append(stats, *group)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ID < stats[j].ID
+	})
+
+	return stats, nil
+}
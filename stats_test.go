@@ -0,0 +1,290 @@
+package pihole
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_TopBlockedDomains(t *testing.T) {
+	isUnit(t)
+
+	var gotPath string
+	var gotQuery map[string][]string
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		gotPath = req.URL.Path
+		gotQuery = req.URL.Query()
+		return newHTTPResponse(http.StatusOK, `{"domains":[{"domain":"ads.example","count":42},{"domain":"tracker.example","count":7}],"total_queries":100,"blocked_queries":49}`), nil
+	})
+	require.NoError(t, err)
+
+	t.Run("uses in-memory stats without a window", func(t *testing.T) {
+		domains, err := client.Stats.TopBlockedDomains(context.Background(), TopOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/stats/top_domains", gotPath)
+		assert.Equal(t, []string{"true"}, gotQuery["blocked"])
+		assert.Equal(t, []string{"2"}, gotQuery["count"])
+		assert.Equal(t, []TopDomain{{Domain: "ads.example", Count: 42}, {Domain: "tracker.example", Count: 7}}, domains)
+	})
+
+	t.Run("uses database stats with a window", func(t *testing.T) {
+		from := time.Unix(1700000000, 0)
+		until := time.Unix(1700604800, 0)
+
+		_, err := client.Stats.TopBlockedDomains(context.Background(), TopOptions{Limit: 10, From: from, Until: until})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/stats/database/top_domains", gotPath)
+		assert.Equal(t, []string{"1700000000"}, gotQuery["from"])
+		assert.Equal(t, []string{"1700604800"}, gotQuery["until"])
+	})
+
+	t.Run("rejects an inverted window", func(t *testing.T) {
+		_, err := client.Stats.TopBlockedDomains(context.Background(), TopOptions{From: time.Unix(10, 0), Until: time.Unix(5, 0)})
+		assert.Error(t, err)
+	})
+}
+
+func TestStats_Breakdowns(t *testing.T) {
+	isUnit(t)
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/stats/query_types":
+			return newHTTPResponse(http.StatusOK, `{"types":{"A":60,"AAAA":30,"HTTPS":10,"SRV":0}}`), nil
+		case "/api/stats/summary":
+			return newHTTPResponse(http.StatusOK, `{"queries":{"total":4,"status":{"GRAVITY":1,"FORWARDED":3},"replies":{"IP":3,"NXDOMAIN":1}}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+	require.NoError(t, err)
+
+	types, err := client.Stats.QueryTypes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 100, types.Total())
+	assert.InDelta(t, 30.0, types.Percentage("AAAA"), 0.001)
+	assert.InDelta(t, 10.0, types.Percentages()["HTTPS"], 0.001)
+	assert.Zero(t, types.Percentage("MX"))
+
+	replies, err := client.Stats.ReplyTypes(context.Background())
+	require.NoError(t, err)
+	assert.InDelta(t, 75.0, replies.Percentage("IP"), 0.001)
+
+	status, err := client.Stats.StatusTypes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, status["GRAVITY"])
+
+	assert.Zero(t, Breakdown{}.Percentage("A"))
+}
+
+func TestStats_RecentBlocked(t *testing.T) {
+	isUnit(t)
+
+	var pages []string
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/queries" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		assert.Equal(t, "blocklist", req.URL.Query().Get("upstream"))
+		pages = append(pages, req.URL.Query().Get("start"))
+		if req.URL.Query().Get("start") == "100" {
+			assert.Equal(t, "991", req.URL.Query().Get("cursor"))
+			return newHTTPResponse(http.StatusOK, `{"queries":[{"id":3,"time":1700000000.5,"type":"A","status":"DENYLIST","domain":"late.example","client":{"ip":"10.0.0.3","name":null},"reply":{"type":"IP","time":-1}}],"cursor":991}`), nil
+		}
+
+		queries := make([]string, 0, recentBlockedPageSize)
+		queries = append(queries,
+			`{"id":1,"time":1700000100.25,"type":"AAAA","status":"GRAVITY","domain":"ads.example","client":{"ip":"10.0.0.2","name":"laptop.lan"},"reply":{"type":"IP","time":0.5}}`,
+		)
+		for len(queries) < recentBlockedPageSize {
+			queries = append(queries, `{"id":2,"time":1700000050,"type":"A","status":"FORWARDED","domain":"ok.example","client":{"ip":"10.0.0.2"}}`)
+		}
+
+		return newHTTPResponse(http.StatusOK, `{"queries":[`+strings.Join(queries, ",")+`],"cursor":991}`), nil
+	})
+	require.NoError(t, err)
+
+	blocked, err := client.Stats.RecentBlocked(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, blocked, 2)
+	assert.Equal(t, []string{"", "100"}, pages)
+
+	assert.Equal(t, "ads.example", blocked[0].Domain)
+	assert.Equal(t, QueryStatusGravity, blocked[0].Status)
+	assert.Equal(t, QueryClient{IP: "10.0.0.2", Name: "laptop.lan"}, blocked[0].Client)
+	assert.Equal(t, int64(1700000100250), blocked[0].Time.UnixMilli())
+	assert.Equal(t, 500*time.Microsecond, blocked[0].Reply.Time)
+	assert.Equal(t, "late.example", blocked[1].Domain)
+	assert.Zero(t, blocked[1].Reply.Time)
+
+	pages = nil
+	blocked, err = client.Stats.RecentBlocked(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, []string{""}, pages)
+}
+
+func TestStats_CacheMetrics(t *testing.T) {
+	isUnit(t)
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/info/metrics":
+			return newHTTPResponse(http.StatusOK, `{"metrics":{"dns":{"cache":{"size":10000,"inserted":400,"evicted":100,"expired":3,"immortal":2,
+				"content":[{"type":1,"name":"A","count":{"valid":1500,"stale":500}},{"type":28,"name":"AAAA","count":{"valid":500,"stale":0}}]}}}}`), nil
+		case "/api/stats/summary":
+			return newHTTPResponse(http.StatusOK, `{"queries":{"total":1000,"cached":600,"forwarded":200}}`), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+	require.NoError(t, err)
+
+	metrics, err := client.Stats.CacheMetrics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 10000, metrics.Size)
+	assert.Equal(t, []CacheContent{{Type: "A", Valid: 1500, Stale: 500}, {Type: "AAAA", Valid: 500}}, metrics.Content)
+	assert.InDelta(t, 0.75, metrics.HitRatio(), 0.001)
+	assert.InDelta(t, 0.25, metrics.MissRatio(), 0.001)
+	assert.InDelta(t, 0.25, metrics.EvictionRatio(), 0.001)
+	assert.Equal(t, 2500, metrics.Entries())
+	assert.InDelta(t, 0.25, metrics.Utilization(), 0.001)
+
+	assert.Zero(t, CacheMetrics{}.HitRatio())
+}
+
+func TestStats_ByGroup(t *testing.T) {
+	isUnit(t)
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/stats/database/top_clients":
+			if req.URL.Query().Get("blocked") == "true" {
+				return newHTTPResponse(http.StatusOK, `{"clients":[{"ip":"192.168.1.20","count":10},{"ip":"192.168.1.50","count":5}]}`), nil
+			}
+			return newHTTPResponse(http.StatusOK, `{"clients":[{"ip":"192.168.1.20","name":"laptop.lan","count":100},{"ip":"192.168.1.30","count":40},{"ip":"10.0.0.7","count":20},{"ip":"192.168.1.50","count":60}]}`), nil
+		case "/api/groups":
+			return newHTTPResponse(http.StatusOK, `{"groups":[{"id":0,"name":"Default"},{"id":1,"name":"adults"},{"id":2,"name":"lan"},{"id":3,"name":"laptops"},{"id":6,"name":"unused"}]}`), nil
+		case "/api/clients":
+			return newHTTPResponse(http.StatusOK, testNetworkClientsBody), nil
+		case "/api/network/devices":
+			return newHTTPResponse(http.StatusOK, testDevicesBody), nil
+		default:
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+	})
+	require.NoError(t, err)
+
+	until := time.Now()
+	groups, err := client.Stats.ByGroup(context.Background(), until.Add(-24*time.Hour), until)
+	require.NoError(t, err)
+
+	byName := make(map[string]GroupStats)
+	for _, group := range groups {
+		byName[group.Name] = group
+	}
+
+	// The laptop is matched by MAC, the printer and .50 by subnet, 10.0.0.7 by nothing.
+	assert.Equal(t, GroupStats{ID: 1, Name: "adults", Clients: 1, Queries: 100, Blocked: 10}, byName["adults"])
+	assert.Equal(t, GroupStats{ID: 3, Name: "laptops", Clients: 1, Queries: 100, Blocked: 10}, byName["laptops"])
+	assert.Equal(t, GroupStats{ID: 2, Name: "lan", Clients: 2, Queries: 100, Blocked: 5}, byName["lan"])
+	assert.Equal(t, GroupStats{ID: 0, Name: "Default", Clients: 1, Queries: 20}, byName["Default"])
+	assert.Equal(t, GroupStats{ID: 6, Name: "unused"}, byName["unused"])
+	assert.InDelta(t, 5.0, byName["lan"].BlockedPercentage(), 0.001)
+
+	_, err = client.Stats.ByGroup(context.Background(), until, until.Add(-time.Hour))
+	assert.Error(t, err)
+}
+
+func TestStats_DomainHits(t *testing.T) {
+	isUnit(t)
+
+	var starts []string
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/queries" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		query := req.URL.Query()
+		assert.Equal(t, "tracker.example", query.Get("domain"))
+		assert.Equal(t, "true", query.Get("disk"))
+		starts = append(starts, query.Get("start"))
+
+		if query.Get("start") == "1000" {
+			assert.Equal(t, "77", query.Get("cursor"))
+			return newHTTPResponse(http.StatusOK, `{"queries":[{"id":3,"time":1700000300,"type":"A","status":"GRAVITY","domain":"tracker.example","client":{"ip":"10.0.0.3","name":"tv.lan"}}],"cursor":77}`), nil
+		}
+
+		queries := make([]string, 0, domainHitsPageSize)
+		queries = append(queries, `{"id":1,"time":1700000100,"type":"A","status":"GRAVITY","domain":"tracker.example","client":{"ip":"10.0.0.3"}}`)
+		for len(queries) < domainHitsPageSize {
+			queries = append(queries, `{"id":2,"time":1700000200,"type":"A","status":"FORWARDED","domain":"tracker.example","client":{"ip":"10.0.0.2","name":"laptop.lan"}}`)
+		}
+
+		return newHTTPResponse(http.StatusOK, `{"queries":[`+strings.Join(queries, ",")+`],"cursor":77}`), nil
+	})
+	require.NoError(t, err)
+
+	hits, err := client.Stats.DomainHits(context.Background(), "tracker.example", time.Unix(1700000000, 0), time.Unix(1700086400, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "1000"}, starts)
+
+	assert.Equal(t, 1001, hits.Queries)
+	assert.Equal(t, 2, hits.Blocked)
+	assert.InDelta(t, 0.1998, hits.BlockedPercentage(), 0.0001)
+	assert.Equal(t, int64(1700000100), hits.First.Unix())
+	assert.Equal(t, int64(1700000300), hits.Last.Unix())
+	assert.Equal(t, []ClientHits{
+		{IP: "10.0.0.2", Name: "laptop.lan", Queries: 999},
+		{IP: "10.0.0.3", Name: "tv.lan", Queries: 2, Blocked: 2},
+	}, hits.Clients)
+
+	_, err = client.Stats.DomainHits(context.Background(), "", time.Unix(1700000000, 0), time.Time{})
+	assert.ErrorIs(t, err, ErrInvalidQueryFilter)
+}
+
+func TestStats_Compare(t *testing.T) {
+	isUnit(t)
+
+	week := Between(time.Unix(1700000000, 0), time.Unix(1700604800, 0))
+
+	client, err := newTransportClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/stats/database/summary" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		if req.URL.Query().Get("from") == "1700000000" {
+			return newHTTPResponse(http.StatusOK, `{"sum_queries":1000,"sum_blocked":125,"percent_blocked":12.5,"total_clients":12}`), nil
+		}
+		assert.Equal(t, "1700000000", req.URL.Query().Get("until"))
+		return newHTTPResponse(http.StatusOK, `{"sum_queries":800,"sum_blocked":80,"percent_blocked":10,"total_clients":0}`), nil
+	})
+	require.NoError(t, err)
+
+	comparison, err := client.Stats.Compare(context.Background(), week, week.Previous())
+	require.NoError(t, err)
+
+	assert.Equal(t, MetricChange{Previous: 800, Current: 1000, Delta: 200, Percent: 25}, comparison.Queries)
+	assert.Equal(t, 45, comparison.Blocked.Delta)
+	assert.InDelta(t, 56.25, comparison.Blocked.Percent, 0.001)
+	assert.True(t, math.IsInf(comparison.Clients.Percent, 1))
+	assert.InDelta(t, 2.5, comparison.BlockedPoints, 0.001)
+	assert.Equal(t, week, comparison.Current.Range)
+
+	assert.InDelta(t, 12.5, comparison.Current.BlockedPercentage(), 0.001)
+	assert.InDelta(t, 1000.0/604800, comparison.Current.QueriesPerSecond(), 1e-9)
+	assert.InDelta(t, 83.333, comparison.Current.QueriesPerClient(), 0.001)
+	assert.Zero(t, comparison.Previous.QueriesPerClient())
+	assert.Zero(t, PeriodSummary{}.BlockedPercentage())
+
+	_, err = client.Stats.Compare(context.Background(), week, TimeRange{})
+	assert.Error(t, err)
+}
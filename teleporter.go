@@ -0,0 +1,227 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Teleporter interface {
+	// Export streams a Teleporter backup archive. The caller must close the returned reader.
+	Export(ctx context.Context) (io.ReadCloser, error)
+
+	// Import restores a Teleporter backup archive of size bytes, or of unknown size when
+	// size is negative. A nil opts imports everything.
+	Import(ctx context.Context, archive io.Reader, size int64, opts *ImportOptions) (*ImportReport, error)
+}
+
+type teleporter struct {
+	client *Client
+}
+
+// ImportOptions selects which parts of a Teleporter archive are restored. Local DNS
+// and CNAME records are part of Config.
+type ImportOptions struct {
+	Config       bool
+	DHCPLeases   bool
+	Groups       bool
+	Adlists      bool
+	AdlistGroups bool
+	Domains      bool
+	DomainGroups bool
+	Clients      bool
+	ClientGroups bool
+}
+
+// DefaultImportOptions returns options restoring every part of an archive, which
+// matches the web interface's defaults.
+func DefaultImportOptions() ImportOptions {
+	return ImportOptions{
+		Config:       true,
+		DHCPLeases:   true,
+		Groups:       true,
+		Adlists:      true,
+		AdlistGroups: true,
+		Domains:      true,
+		DomainGroups: true,
+		Clients:      true,
+		ClientGroups: true,
+	}
+}
+
+// ImportReport describes what the server restored from an archive.
+type ImportReport struct {
+	Config        bool
+	DHCPLeases    bool
+	GravityTables []string
+	Files         []string
+	Took          time.Duration
+}
+
+type importOptionsRequest struct {
+	Config     bool                        `json:"config"`
+	DHCPLeases bool                        `json:"dhcp_leases"`
+	Gravity    importGravityOptionsRequest `json:"gravity"`
+}
+
+type importGravityOptionsRequest struct {
+	Group             bool `json:"group"`
+	Adlist            bool `json:"adlist"`
+	AdlistByGroup     bool `json:"adlist_by_group"`
+	Domainlist        bool `json:"domainlist"`
+	DomainlistByGroup bool `json:"domainlist_by_group"`
+	Client            bool `json:"client"`
+	ClientByGroup     bool `json:"client_by_group"`
+}
+
+type importResponse struct {
+	Files []string `json:"files"`
+	Took  float64  `json:"took"`
+}
+
+func (opts ImportOptions) toRequest() importOptionsRequest {
+	return importOptionsRequest{
+		Config:     opts.Config,
+		DHCPLeases: opts.DHCPLeases,
+		Gravity: importGravityOptionsRequest{
+			Group:             opts.Groups,
+			Adlist:            opts.Adlists,
+			AdlistByGroup:     opts.AdlistGroups,
+			Domainlist:        opts.Domains,
+			DomainlistByGroup: opts.DomainGroups,
+			Client:            opts.Clients,
+			ClientByGroup:     opts.ClientGroups,
+		},
+	}
+}
+
+// gravityTables are the gravity.db tables a Teleporter archive may contain.
+var gravityTables = []string{
+	"adlist_by_group",
+	"domainlist_by_group",
+	"client_by_group",
+	"adlist",
+	"domainlist",
+	"client",
+	"group",
+}
+
+func (res importResponse) toImportReport() *ImportReport {
+	report := &ImportReport{
+		Files:         res.Files,
+		GravityTables: make([]string, 0),
+		Took:          time.Duration(res.Took * float64(time.Second)),
+	}
+
+	for _, file := range res.Files {
+		switch {
+		case strings.HasSuffix(file, "pihole.toml"):
+			report.Config = true
+		case strings.HasSuffix(file, "dhcp.leases"):
+			report.DHCPLeases = true
+		case strings.Contains(file, "gravity.db"):
+			for _, table := range gravityTables {
+				if strings.HasSuffix(file, table) {
+					report.GravityTables = append(report.GravityTables, table)
+					break
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// Export streams a Teleporter archive from the response body. The request is not
+// coalesced with concurrent exports, which would buffer the whole archive.
+func (t teleporter) Export(ctx context.Context) (io.ReadCloser, error) {
+	res, err := t.client.get(ctx, "/api/teleporter")
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		return nil, newTeleporterAPIError(res, b)
+	}
+
+	return res.Body, nil
+}
+
+// Import streams a Teleporter archive to the server, restoring the parts selected by opts.
+// When size is known the request carries a Content-Length instead of being chunked.
+// Note that the default retrying HTTP client buffers request bodies in memory so they
+// can be replayed; supply Config.HttpClient to stream without buffering.
+func (t teleporter) Import(ctx context.Context, archive io.Reader, size int64, opts *ImportOptions) (report *ImportReport, err error) {
+	if opts == nil {
+		defaults := DefaultImportOptions()
+		opts = &defaults
+	}
+
+	defer func() {
+		t.client.afterMutation(ctx, Mutation{Operation: AuditTeleporterImport, Target: "teleporter", After: auditValue(report)}, err)
+	}()
+
+	if err := t.client.beforeMutation(ctx, Mutation{Operation: AuditTeleporterImport, Target: "teleporter", After: *opts}); err != nil {
+		return nil, err
+	}
+
+	importJSON, err := json.Marshal(opts.toRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	// The multipart framing is rendered up front so that the archive itself can be
+	// streamed between the file part's header and the trailing import field.
+	var head, tail bytes.Buffer
+	form := multipart.NewWriter(&head)
+
+	if _, err := form.CreateFormFile("file", "pihole-teleporter.zip"); err != nil {
+		return nil, err
+	}
+
+	tailForm := multipart.NewWriter(&tail)
+	if err := tailForm.SetBoundary(form.Boundary()); err != nil {
+		return nil, err
+	}
+	if err := tailForm.WriteField("import", string(importJSON)); err != nil {
+		return nil, err
+	}
+	if err := tailForm.Close(); err != nil {
+		return nil, err
+	}
+
+	// The tail writer starts its first part without a leading CRLF, which the file part
+	// needs to be terminated with.
+	body := io.MultiReader(&head, archive, strings.NewReader("\r\n"), &tail)
+
+	contentLength := int64(-1)
+	if size >= 0 {
+		contentLength = int64(head.Len()) + size + 2 + int64(tail.Len())
+	}
+
+	res, err := t.client.do(ctx, http.MethodPost, "/api/teleporter", body, form.FormDataContentType(), "", contentLength)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newTeleporterAPIError(res, b)
+	}
+
+	var resImport importResponse
+	if err := json.NewDecoder(res.Body).Decode(&resImport); err != nil {
+		return nil, fmt.Errorf("failed to parse teleporter import body: %w", err)
+	}
+
+	return resImport.toImportReport(), nil
+}
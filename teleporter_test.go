@@ -0,0 +1,129 @@
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeleporter_ImportSendsOptions(t *testing.T) {
+	isUnit(t)
+
+	var (
+		gotArchive       []byte
+		gotOptions       importOptionsRequest
+		gotContentLength int64
+	)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.URL.Path != "/api/teleporter" {
+			return newHTTPResponse(http.StatusNotFound, ``), nil
+		}
+
+		raw, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		if req.ContentLength > 0 {
+			assert.Equal(t, int64(len(raw)), req.ContentLength)
+		}
+		gotContentLength = req.ContentLength
+		req.Body = io.NopCloser(bytes.NewReader(raw))
+
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		file, _, err := req.FormFile("file")
+		require.NoError(t, err)
+		gotArchive, err = io.ReadAll(file)
+		require.NoError(t, err)
+
+		require.NoError(t, json.Unmarshal([]byte(req.FormValue("import")), &gotOptions))
+
+		return newHTTPResponse(http.StatusOK, `{"files":["etc/pihole/pihole.toml","etc/pihole/gravity.db->group","etc/pihole/gravity.db->adlist_by_group"],"took":1.5}`), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	opts := DefaultImportOptions()
+	opts.DHCPLeases = false
+	opts.Clients = false
+
+	report, err := client.Teleporter.Import(context.Background(), strings.NewReader("PK-archive"), 10, &opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("PK-archive"), gotArchive)
+	assert.Positive(t, gotContentLength)
+	assert.True(t, gotOptions.Config)
+	assert.False(t, gotOptions.DHCPLeases)
+	assert.False(t, gotOptions.Gravity.Client)
+	assert.True(t, gotOptions.Gravity.AdlistByGroup)
+
+	assert.True(t, report.Config)
+	assert.False(t, report.DHCPLeases)
+	assert.Equal(t, []string{"group", "adlist_by_group"}, report.GravityTables)
+	assert.Len(t, report.Files, 3)
+
+	_, err = client.Teleporter.Import(context.Background(), io.MultiReader(strings.NewReader("PK-"), strings.NewReader("archive")), -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("PK-archive"), gotArchive)
+	assert.Zero(t, gotContentLength)
+	assert.True(t, gotOptions.DHCPLeases)
+	assert.True(t, gotOptions.Gravity.Client)
+}
+
+func TestTeleporter_Export(t *testing.T) {
+	isUnit(t)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/teleporter" {
+			return newHTTPResponse(http.StatusOK, "PK-archive"), nil
+		}
+		return newHTTPResponse(http.StatusNotFound, ``), nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	archive, err := client.Teleporter.Export(context.Background())
+	require.NoError(t, err)
+	defer archive.Close()
+
+	b, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("PK-archive"), b)
+}
+
+func TestTeleporter_ExportStreams(t *testing.T) {
+	isUnit(t)
+
+	body, writer := io.Pipe()
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		res := newHTTPResponse(http.StatusOK, "")
+		res.Body = body
+		return res, nil
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	// Export returns while the server is still sending the archive.
+	archive, err := client.Teleporter.Export(context.Background())
+	require.NoError(t, err)
+	defer archive.Close()
+
+	go func() {
+		writer.Write([]byte("PK-"))
+		writer.Write([]byte("archive"))
+		writer.Close()
+	}()
+
+	b, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("PK-archive"), b)
+}
@@ -1,21 +1,9 @@
 package pihole
 
 import (
-	"io"
-	"net/http"
-	"strings"
+	"github.com/awaybreaktoday/lib-pihole-go/piholetest"
 )
 
-type roundTripFunc func(*http.Request) (*http.Response, error)
+type roundTripFunc = piholetest.RoundTripFunc
 
-func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req)
-}
-
-func newHTTPResponse(status int, body string) *http.Response {
-	return &http.Response{
-		StatusCode: status,
-		Header:     make(http.Header),
-		Body:       io.NopCloser(strings.NewReader(body)),
-	}
-}
+var newHTTPResponse = piholetest.Response
@@ -0,0 +1,9 @@
+package pihole
+
+import "net/http"
+
+// newTransportClient returns a client with a fixed session that sends every request
+// to transport.
+func newTransportClient(transport roundTripFunc) (*Client, error) {
+	return New(Config{BaseURL: "http://pi.test", SessionID: "test", HttpClient: &http.Client{Transport: transport}})
+}
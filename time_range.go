@@ -0,0 +1,98 @@
+package pihole
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TimeRange is a window of time for the query log, history and database APIs. From
+// and Until are instants, so a range means the same thing whatever the time zone
+// of the Pi-hole or the caller. A zero Until means now.
+type TimeRange struct {
+	From  time.Time
+	Until time.Time
+}
+
+// Last returns the range of d ending now.
+func Last(d time.Duration) TimeRange {
+	now := time.Now()
+	return TimeRange{From: now.Add(-d), Until: now}
+}
+
+// LastHours returns the range of the last n hours.
+func LastHours(n int) TimeRange {
+	return Last(time.Duration(n) * time.Hour)
+}
+
+// Today returns the range from midnight in the local time zone until now.
+func Today() TimeRange {
+	return TodayIn(time.Local)
+}
+
+// TodayIn returns the range from midnight in loc until now. Use it with the Pi-hole's
+// time zone when the caller runs elsewhere.
+func TodayIn(loc *time.Location) TimeRange {
+	now := time.Now().In(loc)
+	year, month, day := now.Date()
+
+	return TimeRange{From: time.Date(year, month, day, 0, 0, 0, 0, loc), Until: now}
+}
+
+// Between returns the range from from until until.
+func Between(from time.Time, until time.Time) TimeRange {
+	return TimeRange{From: from, Until: until}
+}
+
+// until returns Until, or now if it is zero.
+func (r TimeRange) until() time.Time {
+	if r.Until.IsZero() {
+		return time.Now()
+	}
+
+	return r.Until
+}
+
+// Duration returns the length of the range.
+func (r TimeRange) Duration() time.Duration {
+	return r.until().Sub(r.From)
+}
+
+// Contains reports whether t falls in [From, Until).
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.From) && t.Before(r.until())
+}
+
+// Previous returns the range of the same length that ends where r starts, for
+// period-over-period comparisons.
+func (r TimeRange) Previous() TimeRange {
+	return TimeRange{From: r.From.Add(-r.Duration()), Until: r.From}
+}
+
+func (r TimeRange) String() string {
+	return fmt.Sprintf("%s to %s", r.From.Format(time.RFC3339), r.until().Format(time.RFC3339))
+}
+
+// validate checks that the range has a start and does not end before it.
+func (r TimeRange) validate() error {
+	if r.From.IsZero() {
+		return fmt.Errorf("invalid time range: from must not be zero")
+	}
+
+	if r.From.After(r.until()) {
+		return fmt.Errorf("invalid time window: from %s is after until %s", r.From, r.until())
+	}
+
+	return nil
+}
+
+// values encodes the range as the from and until Unix seconds FTL expects. Both ends
+// are truncated to the second, so consecutive ranges stay contiguous.
+func (r TimeRange) values() url.Values {
+	vals := url.Values{}
+	vals.Set("from", strconv.FormatInt(r.From.Unix(), 10))
+	vals.Set("until", strconv.FormatInt(r.until().Unix(), 10))
+
+	return vals
+}
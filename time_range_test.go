@@ -0,0 +1,44 @@
+package pihole
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeRange(t *testing.T) {
+	r := LastHours(24)
+	assert.Equal(t, 24*time.Hour, r.Duration())
+	assert.True(t, r.Contains(time.Now().Add(-time.Hour)))
+	assert.False(t, r.Contains(time.Now().Add(-25*time.Hour)))
+	assert.Equal(t, r.From, r.Previous().Until)
+	assert.Equal(t, 24*time.Hour, r.Previous().Duration())
+
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	today := TodayIn(loc)
+	assert.Equal(t, 0, today.From.Hour())
+	assert.Equal(t, loc, today.From.Location())
+	assert.LessOrEqual(t, today.Duration(), 24*time.Hour)
+
+	from := time.Date(2026, 10, 1, 12, 0, 0, 500, time.FixedZone("CEST", 2*60*60))
+	vals := Between(from, from.Add(time.Hour)).values()
+	assert.Equal(t, "1790848800", vals.Get("from"))
+	assert.Equal(t, "1790852400", vals.Get("until"))
+
+	require.NoError(t, Between(from, time.Time{}).validate())
+	assert.Error(t, Between(from, from.Add(-time.Second)).validate())
+	assert.Error(t, Between(time.Time{}, from).validate())
+}
+
+func TestQueryFilter_Range(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	vals, err := NewQueryFilter().Range(Between(from, from.Add(time.Hour))).Encode()
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000", vals.Get("from"))
+	assert.Equal(t, "1700003600", vals.Get("until"))
+
+	_, err = NewQueryFilter().Range(TimeRange{}).Encode()
+	assert.ErrorIs(t, err, ErrInvalidQueryFilter)
+}
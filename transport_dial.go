@@ -0,0 +1,66 @@
+package pihole
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (t TransportConfig) isZero() bool {
+	return t.MaxIdleConnsPerHost == 0 &&
+		t.IdleConnTimeout == 0 &&
+		!t.DisableKeepAlives &&
+		t.Protocol == HTTPProtocolAuto &&
+		t.DialContext == nil &&
+		t.Resolver == nil &&
+		t.ResolveTo == ""
+}
+
+// dialContext returns the dial function for DialContext, Resolver and ResolveTo, or
+// nil to keep the transport's own. The config has been validated.
+func (t TransportConfig) dialContext(baseURL string) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if t.DialContext == nil && t.Resolver == nil && t.ResolveTo == "" {
+		return nil
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		// The same settings as the transport's default dialer.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Resolver: t.Resolver}
+		dial = dialer.DialContext
+	}
+
+	if t.ResolveTo == "" {
+		return dial
+	}
+
+	u, _ := url.Parse(baseURL)
+	host := u.Hostname()
+	ip, port := splitResolveTo(t.ResolveTo)
+
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		addrHost, addrPort, err := net.SplitHostPort(addr)
+		if err != nil || !strings.EqualFold(addrHost, host) {
+			return dial(ctx, network, addr)
+		}
+
+		if port != "" {
+			addrPort = port
+		}
+
+		return dial(ctx, network, net.JoinHostPort(ip, addrPort))
+	}
+}
+
+// splitResolveTo splits ResolveTo into the IP address and the port, which is empty
+// if there is none.
+func splitResolveTo(resolveTo string) (string, string) {
+	ip, port := resolveTo, ""
+	if host, p, err := net.SplitHostPort(resolveTo); err == nil {
+		ip, port = host, p
+	}
+
+	return strings.Trim(ip, "[]"), port
+}
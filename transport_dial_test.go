@@ -0,0 +1,86 @@
+package pihole
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportConfig_ResolveTo(t *testing.T) {
+	isUnit(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Host)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// pi.invalid cannot resolve, so the request only succeeds through ResolveTo.
+	client, err := New(Config{
+		BaseURL:   "http://pi.invalid:" + u.Port(),
+		APIToken:  "token",
+		Transport: TransportConfig{ResolveTo: u.Hostname()},
+	})
+	require.NoError(t, err)
+
+	res, err := client.Get(context.Background(), "/api/info/version")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "pi.invalid:"+u.Port(), string(b))
+}
+
+func TestTransportConfig_DialContext(t *testing.T) {
+	isUnit(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var dialed []string
+	client, err := New(Config{
+		BaseURL:  "http://pi.invalid",
+		APIToken: "token",
+		Transport: TransportConfig{
+			ResolveTo: server.Listener.Addr().String(),
+			DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+				dialed = append(dialed, addr)
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := client.Get(context.Background(), "/api/info/version")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, []string{server.Listener.Addr().String()}, dialed)
+}
+
+func TestTransportConfig_DialValidation(t *testing.T) {
+	_, err := New(Config{BaseURL: "http://pi.test", Transport: TransportConfig{ResolveTo: "pi.hole"}})
+	assert.ErrorIs(t, err, ErrClientValidation)
+
+	_, err = New(Config{BaseURL: "http://pi.test", Transport: TransportConfig{
+		Resolver:    &net.Resolver{},
+		DialContext: (&net.Dialer{}).DialContext,
+	}})
+	assert.ErrorIs(t, err, ErrClientValidation)
+
+	_, err = New(Config{BaseURL: "http://pi.test", HttpClient: &http.Client{}, Transport: TransportConfig{ResolveTo: "10.0.0.53"}})
+	assert.ErrorIs(t, err, ErrClientValidation)
+
+	_, err = New(Config{BaseURL: "http://[fd00::53]", Transport: TransportConfig{ResolveTo: "[fd00::54]:8080"}})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+//go:build go1.24
+
+package pihole
+
+import (
+	"net/http"
+)
+
+// h2cSupported reports whether net/http can speak unencrypted HTTP/2.
+const h2cSupported = true
+
+// apply restricts the transport to the protocol. HTTP/2 without HTTP/1 makes
+// net/http use h2c for http:// URLs.
+func (p HTTPProtocol) apply(transport *http.Transport) {
+	protocols := new(http.Protocols)
+	switch p {
+	case HTTPProtocol1:
+		protocols.SetHTTP1(true)
+	case HTTPProtocol2:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	transport.Protocols = protocols
+}
@@ -0,0 +1,24 @@
+//go:build !go1.24
+
+package pihole
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// h2cSupported reports whether net/http can speak unencrypted HTTP/2, which it
+// cannot before Go 1.24.
+const h2cSupported = false
+
+// apply restricts the transport to the protocol.
+func (p HTTPProtocol) apply(transport *http.Transport) {
+	switch p {
+	case HTTPProtocol1:
+		// A non-nil, empty TLSNextProto disables HTTP/2.
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case HTTPProtocol2:
+		transport.ForceAttemptHTTP2 = true
+	}
+}
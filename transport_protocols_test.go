@@ -0,0 +1,47 @@
+//go:build go1.24
+
+package pihole
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportConfig_Protocol(t *testing.T) {
+	isUnit(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	for protocol, want := range map[HTTPProtocol]string{
+		HTTPProtocolAuto: "HTTP/1.1",
+		HTTPProtocol1:    "HTTP/1.1",
+		HTTPProtocol2:    "HTTP/2.0",
+	} {
+		client, err := New(Config{BaseURL: server.URL, APIToken: "token", Transport: TransportConfig{Protocol: protocol}})
+		require.NoError(t, err)
+
+		res, err := client.Get(context.Background(), "/api/info/version")
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, want, string(b), "protocol %q", protocol)
+	}
+
+	_, err := New(Config{BaseURL: server.URL, Transport: TransportConfig{Protocol: "spdy"}})
+	assert.ErrorIs(t, err, ErrClientValidation)
+}
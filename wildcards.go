@@ -0,0 +1,252 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+type Wildcards interface {
+	// List all wildcard records.
+	List(ctx context.Context) (WildcardRecordList, error)
+
+	// Create a wildcard record resolving domain and all its subdomains to IP.
+	Create(ctx context.Context, domain string, IP string) (*WildcardRecord, error)
+
+	// Get a wildcard record by its domain.
+	Get(ctx context.Context, domain string) (*WildcardRecord, error)
+
+	// Delete a wildcard record by its domain.
+	Delete(ctx context.Context, domain string) error
+}
+
+var (
+	ErrorWildcardNotFound = newNotFoundError("wildcard record not found")
+)
+
+const wildcardDirective = "address="
+
+type wildcards struct {
+	client *Client
+}
+
+// WildcardRecord is a dnsmasq address=/domain/ip line from misc.dnsmasq_lines,
+// which answers queries for the domain and every subdomain with IP. An empty IP
+// makes dnsmasq answer NXDOMAIN and "#" answers with the unspecified address.
+type WildcardRecord struct {
+	Domain string `json:"domain"`
+	IP     string `json:"ip"`
+	raw    string
+}
+
+type WildcardRecordList []WildcardRecord
+
+type dnsmasqLinesResponse struct {
+	Config struct {
+		Misc struct {
+			DnsmasqLines []string `json:"dnsmasq_lines"`
+		} `json:"misc"`
+	} `json:"config"`
+}
+
+// parseWildcardRecord parses an address=/domain/ip line. ok is false for other
+// dnsmasq lines and for address lines naming several domains, which are left alone.
+func parseWildcardRecord(line string) (WildcardRecord, bool, error) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, wildcardDirective) {
+		return WildcardRecord{}, false, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(trimmed, wildcardDirective), "/")
+	if len(parts) < 3 || parts[0] != "" {
+		return WildcardRecord{}, false, fmt.Errorf("invalid wildcard record: %q", line)
+	}
+	if len(parts) > 3 {
+		return WildcardRecord{}, false, nil
+	}
+
+	if parts[1] == "" {
+		return WildcardRecord{}, false, fmt.Errorf("invalid wildcard record: %q", line)
+	}
+
+	return WildcardRecord{Domain: parts[1], IP: parts[2], raw: line}, true, nil
+}
+
+// encodeWildcardRecord returns the dnsmasq line for the record, preserving the line
+// as read from the server.
+func encodeWildcardRecord(record *WildcardRecord) string {
+	if record.raw != "" {
+		return record.raw
+	}
+
+	return fmt.Sprintf("%s/%s/%s", wildcardDirective, strings.TrimSpace(record.Domain), strings.TrimSpace(record.IP))
+}
+
+func (res dnsmasqLinesResponse) toWildcardRecordList() (WildcardRecordList, error) {
+	list := make(WildcardRecordList, 0)
+
+	for _, line := range res.Config.Misc.DnsmasqLines {
+		record, ok, err := parseWildcardRecord(line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			list = append(list, record)
+		}
+	}
+
+	return list, nil
+}
+
+func wildcardPath(record *WildcardRecord) string {
+	return "/api/config/misc/dnsmasq_lines/" + url.PathEscape(encodeWildcardRecord(record))
+}
+
+// List returns the wildcard records in misc.dnsmasq_lines
+func (w wildcards) List(ctx context.Context) (WildcardRecordList, error) {
+	res, err := w.client.Get(ctx, "/api/config/misc/dnsmasq_lines")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newWildcardAPIError(res, b)
+	}
+
+	var resLines dnsmasqLinesResponse
+	if err := json.NewDecoder(res.Body).Decode(&resLines); err != nil {
+		return nil, fmt.Errorf("failed to parse dnsmasq lines body: %w", err)
+	}
+
+	records, err := resLines.toWildcardRecordList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dnsmasq lines body: %w", err)
+	}
+
+	return records, nil
+}
+
+// Create adds an address=/domain/ip line
+func (w wildcards) Create(ctx context.Context, domain string, IP string) (created *WildcardRecord, err error) {
+	defer func() {
+		w.client.afterMutation(ctx, Mutation{Operation: AuditWildcardCreate, Target: domain, After: auditValue(created)}, err)
+	}()
+
+	if strings.TrimSpace(domain) == "" || strings.ContainsAny(domain, "/ ") {
+		return nil, fmt.Errorf("invalid wildcard domain %q", domain)
+	}
+
+	// A malformed address line stops dnsmasq from starting.
+	if IP != "" && IP != "#" {
+		if _, err := netip.ParseAddr(IP); err != nil {
+			return nil, fmt.Errorf("invalid wildcard IP address %q", IP)
+		}
+	}
+
+	if err := w.client.beforeMutation(ctx, Mutation{Operation: AuditWildcardCreate, Target: domain, After: WildcardRecord{Domain: domain, IP: IP}}); err != nil {
+		return nil, err
+	}
+
+	record := &WildcardRecord{Domain: domain, IP: IP}
+	res, done, err := w.client.mutate(ctx, http.MethodPut, wildcardPath(record), nil, func(ctx context.Context) (bool, error) {
+		return w.exists(ctx, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return w.Get(ctx, domain)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		b, _ := io.ReadAll(res.Body)
+		return nil, newWildcardAPIError(res, b)
+	}
+
+	return w.Get(ctx, domain)
+}
+
+// Get returns a wildcard record by its domain
+func (w wildcards) Get(ctx context.Context, domain string) (*WildcardRecord, error) {
+	records, err := w.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wildcard records: %w", err)
+	}
+
+	for _, record := range records {
+		if sameDomain(record.Domain, domain) {
+			return &record, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrorWildcardNotFound, domain)
+}
+
+// exists reports whether the dnsmasq lines hold the address line for record.
+func (w wildcards) exists(ctx context.Context, record *WildcardRecord) (bool, error) {
+	records, err := w.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, candidate := range records {
+		if sameDomain(candidate.Domain, record.Domain) && normalizeIP(candidate.IP) == normalizeIP(record.IP) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Delete removes the wildcard record for a domain
+func (w wildcards) Delete(ctx context.Context, domain string) error {
+	record, err := w.Get(ctx, domain)
+	if err != nil {
+		if errors.Is(err, ErrorWildcardNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed looking up wildcard record %s for deletion: %w", domain, err)
+	}
+
+	return w.delete(ctx, record)
+}
+
+func (w wildcards) delete(ctx context.Context, record *WildcardRecord) (err error) {
+	m := Mutation{Operation: AuditWildcardDelete, Target: record.Domain, Before: *record}
+	defer func() {
+		w.client.afterMutation(ctx, m, err)
+	}()
+
+	if err := w.client.beforeMutation(ctx, m); err != nil {
+		return err
+	}
+
+	res, done, err := w.client.mutate(ctx, http.MethodDelete, wildcardPath(record), nil, func(ctx context.Context) (bool, error) {
+		exists, err := w.exists(ctx, record)
+		return !exists, err
+	})
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(res.Body)
+		return newWildcardAPIError(res, b)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package pihole
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWildcardRecord(t *testing.T) {
+	tcs := []struct {
+		line    string
+		want    WildcardRecord
+		ok      bool
+		wantErr bool
+	}{
+		{line: "address=/lan.example/10.0.0.1", want: WildcardRecord{Domain: "lan.example", IP: "10.0.0.1"}, ok: true},
+		{line: "address=/blocked.example/", want: WildcardRecord{Domain: "blocked.example"}, ok: true},
+		{line: "address=/null.example/#", want: WildcardRecord{Domain: "null.example", IP: "#"}, ok: true},
+		{line: "address=/a.example/b.example/10.0.0.1"},
+		{line: "server=/corp.example/10.0.0.53"},
+		{line: "address=lan.example", wantErr: true},
+		{line: "address=//10.0.0.1", wantErr: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.line, func(t *testing.T) {
+			record, ok, err := parseWildcardRecord(tc.line)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.want.Domain, record.Domain)
+				assert.Equal(t, tc.want.IP, record.IP)
+				assert.Equal(t, tc.line, encodeWildcardRecord(&record))
+			}
+		})
+	}
+}
+
+func TestWildcards_CreateAndDelete(t *testing.T) {
+	isUnit(t)
+
+	lines := []string{"server=/corp.example/10.0.0.53"}
+	const wantPath = "/api/config/misc/dnsmasq_lines/address=%2Flan.example%2F10.0.0.1"
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/api/config/misc/dnsmasq_lines":
+			body := `{"config":{"misc":{"dnsmasq_lines":[`
+			for i, line := range lines {
+				if i > 0 {
+					body += ","
+				}
+				body += `"` + line + `"`
+			}
+			return newHTTPResponse(http.StatusOK, body+`]}}}`), nil
+		case req.Method == http.MethodPut && req.URL.EscapedPath() == wantPath:
+			lines = append(lines, "address=/lan.example/10.0.0.1")
+			return newHTTPResponse(http.StatusCreated, `{}`), nil
+		case req.Method == http.MethodDelete && req.URL.EscapedPath() == wantPath:
+			lines = lines[:1]
+			return newHTTPResponse(http.StatusNoContent, ``), nil
+		default:
+			return newHTTPResponse(http.StatusBadRequest, `{"error":{"key":"bad_request","message":"unexpected request","hint":null}}`), nil
+		}
+	})
+
+	client, err := newTransportClient(transport)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	record, err := client.Wildcards.Create(ctx, "lan.example", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", record.IP)
+
+	list, err := client.Wildcards.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, client.Wildcards.Delete(ctx, "LAN.example"))
+	_, err = client.Wildcards.Get(ctx, "lan.example")
+	assert.ErrorIs(t, err, ErrorWildcardNotFound)
+
+	_, err = client.Wildcards.Create(ctx, "a/b", "10.0.0.1")
+	assert.Error(t, err)
+
+	for _, ip := range []string{"10.0.0", "10.0.0.1/24", "nas.lan"} {
+		_, err = client.Wildcards.Create(ctx, "lan.example", ip)
+		assert.ErrorContains(t, err, "invalid wildcard IP address", ip)
+	}
+}